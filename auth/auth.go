@@ -1,6 +1,19 @@
 package auth
 
-import "context"
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+const (
+	ScopeAccountsRead       = "accounts:read"
+	ScopeAccountsWrite      = "accounts:write"
+	ScopeTransactionsWrite  = "transactions:write"
+	ScopeAtmOperate         = "atm:operate"
+	ScopeTransactionsReview = "transactions:review"
+	ScopeAuditRead          = "audit:read"
+)
 
 type (
 	Claims struct {
@@ -13,10 +26,42 @@ type (
 
 		Is2FAToken      bool `json:"2fa"`
 		HasPersonalData bool `json:"idf"`
+		IsStaff         bool `json:"stf"`
+
+		Name string `json:"name,omitempty"`
+
+		Scope *string `json:"scope,omitempty"`
+	}
+
+	ATMClaims struct {
+		Id string `json:"jti"`
+
+		IssuedAt  int64 `json:"iat"`
+		ExpiresAt int64 `json:"exp"`
+
+		AtmId int64 `json:"atm"`
 	}
 
 	Authorizer interface {
 		Authorize(ctx context.Context, claims Claims) ([]byte, error)
 		VerifyAuthorization(ctx context.Context, authorization []byte) (Claims, error)
 	}
+
+	ATMAuthorizer interface {
+		AuthorizeATM(ctx context.Context, claims ATMClaims) ([]byte, error)
+		VerifyATMAuthorization(ctx context.Context, authorization []byte) (ATMClaims, error)
+	}
 )
+
+func (c *Claims) HasScopes(scopes ...string) bool {
+	if c.Scope == nil {
+		return true
+	}
+	granted := strings.Fields(*c.Scope)
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+	"x-bank-ms-bank/config"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/infra/hasher"
+	"x-bank-ms-bank/infra/notifier"
+	"x-bank-ms-bank/infra/paymentfile"
+	"x-bank-ms-bank/infra/postgres"
+	"x-bank-ms-bank/infra/random"
+	"x-bank-ms-bank/infra/screening"
+)
+
+const (
+	dateTimeLayout = "2006.01.02 15:04:05"
+	dateLayout     = "2006.01.02"
+)
+
+var (
+	configFile = flag.String("config", "config.json", "")
+	atm        = flag.Int64("atm", 0, "")
+	fromDate   = flag.String("from", "", "")
+	toDate     = flag.String("to", "", "")
+)
+
+func main() {
+	flag.Parse()
+	if *atm == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	to := time.Now()
+	if *toDate != "" {
+		to = parseTime(*toDate)
+	}
+	from := to.Add(-24 * time.Hour)
+	if *fromDate != "" {
+		from = parseTime(*fromDate)
+	}
+
+	conf, err := config.Read(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	postgresService, err := postgres.NewService(conf.Postgres.Login, conf.Postgres.Password, conf.Postgres.Host, conf.Postgres.Port, conf.Postgres.DataBase, conf.Postgres.MaxCons)
+	if err != nil {
+		log.Fatal(err)
+	}
+	passwordHasher := hasher.NewService()
+	randomService := random.NewService()
+	paymentFileParser := paymentfile.NewService("RUB")
+	stepUpNotifier := notifier.NewService(conf.StepUp.WebhookUrl)
+	screener, err := screening.NewService(conf.Screening.ListFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	stepUpPolicy := web.StepUpPolicy{ThresholdCents: conf.StepUp.ThresholdCents, FirstTimeReceiver: conf.StepUp.FirstTimeReceiver}
+	beneficiaryPolicy := web.BeneficiaryPolicy{
+		CoolingOff:           time.Duration(conf.Beneficiaries.CoolingOffHours) * time.Hour,
+		CoolingOffLimitCents: conf.Beneficiaries.CoolingOffLimitCents,
+	}
+
+	service := web.NewService(&postgresService, &passwordHasher, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &paymentFileParser, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &stepUpNotifier, &postgresService, &postgresService, screener, &postgresService, &randomService, stepUpPolicy, beneficiaryPolicy)
+
+	report, err := service.GetAtmCashReport(context.Background(), *atm, from, to)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("atm %d, account %d: %s - %s\n", report.AtmId, report.AccountId, report.From.Format(dateTimeLayout), report.To.Format(dateTimeLayout))
+	for _, operation := range report.Operations {
+		if !operation.HasSnapshot {
+			fmt.Printf("%8d\t%s\t%d\t%d\t-\t-\n", operation.Id, operation.CreatedAt.Format(dateTimeLayout), operation.UserAccountId, operation.AmountCents)
+			continue
+		}
+		fmt.Printf("%8d\t%s\t%d\t%d\t%d\t%d\t%d\n", operation.Id, operation.CreatedAt.Format(dateTimeLayout), operation.UserAccountId, operation.AmountCents,
+			operation.CashAfterCents, operation.BalanceAfterCents, operation.DiscrepancyCents)
+	}
+	fmt.Printf("operations: %d, sum %d cents\n", len(report.Operations), report.OperationsSumCents)
+	fmt.Printf("cash: opening %d, closing %d, current %d, discrepancy %d\n", report.OpeningCashCents, report.ClosingCashCents, report.CurrentCashCents, report.CashDiscrepancyCents)
+	fmt.Printf("balance: opening %d, closing %d, transfers in %d, out %d, discrepancy %d\n", report.OpeningBalanceCents, report.ClosingBalanceCents,
+		report.TransfersInCents, report.TransfersOutCents, report.BalanceDiscrepancyCents)
+	if !report.Balanced {
+		fmt.Println("NOT BALANCED")
+		os.Exit(1)
+	}
+	fmt.Println("balanced")
+}
+
+func parseTime(value string) time.Time {
+	if parsed, err := time.ParseInLocation(dateTimeLayout, value, time.Local); err == nil {
+		return parsed
+	}
+	parsed, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return parsed
+}
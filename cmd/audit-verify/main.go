@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+	"x-bank-ms-bank/config"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/infra/hasher"
+	"x-bank-ms-bank/infra/notifier"
+	"x-bank-ms-bank/infra/paymentfile"
+	"x-bank-ms-bank/infra/postgres"
+	"x-bank-ms-bank/infra/random"
+	"x-bank-ms-bank/infra/screening"
+)
+
+var (
+	configFile = flag.String("config", "config.json", "")
+)
+
+func main() {
+	flag.Parse()
+
+	conf, err := config.Read(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	postgresService, err := postgres.NewService(conf.Postgres.Login, conf.Postgres.Password, conf.Postgres.Host, conf.Postgres.Port, conf.Postgres.DataBase, conf.Postgres.MaxCons)
+	if err != nil {
+		log.Fatal(err)
+	}
+	passwordHasher := hasher.NewService()
+	randomService := random.NewService()
+	paymentFileParser := paymentfile.NewService("RUB")
+	stepUpNotifier := notifier.NewService(conf.StepUp.WebhookUrl)
+	screener, err := screening.NewService(conf.Screening.ListFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	stepUpPolicy := web.StepUpPolicy{ThresholdCents: conf.StepUp.ThresholdCents, FirstTimeReceiver: conf.StepUp.FirstTimeReceiver}
+	beneficiaryPolicy := web.BeneficiaryPolicy{
+		CoolingOff:           time.Duration(conf.Beneficiaries.CoolingOffHours) * time.Hour,
+		CoolingOffLimitCents: conf.Beneficiaries.CoolingOffLimitCents,
+	}
+
+	service := web.NewService(&postgresService, &passwordHasher, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &paymentFileParser, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &stepUpNotifier, &postgresService, &postgresService, screener, &postgresService, &randomService, stepUpPolicy, beneficiaryPolicy)
+
+	result, err := service.VerifyAuditLog(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("entries checked: %d\n", result.Checked)
+	if !result.Valid {
+		fmt.Printf("CHAIN BROKEN at entry %d\n", result.BrokenId)
+		os.Exit(1)
+	}
+	fmt.Println("chain valid")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"x-bank-ms-bank/config"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/iban"
+	"x-bank-ms-bank/infra/hasher"
+	"x-bank-ms-bank/infra/notifier"
+	"x-bank-ms-bank/infra/paymentfile"
+	"x-bank-ms-bank/infra/postgres"
+	"x-bank-ms-bank/infra/random"
+	"x-bank-ms-bank/infra/screening"
+)
+
+var (
+	configFile = flag.String("config", "config.json", "")
+	file       = flag.String("file", "", "")
+	account    = flag.String("account", "", "")
+	user       = flag.Int64("user", 0, "")
+	format     = flag.String("format", "", "")
+)
+
+func main() {
+	flag.Parse()
+	if *file == "" || *account == "" || *user == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conf, err := config.Read(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	postgresService, err := postgres.NewService(conf.Postgres.Login, conf.Postgres.Password, conf.Postgres.Host, conf.Postgres.Port, conf.Postgres.DataBase, conf.Postgres.MaxCons)
+	if err != nil {
+		log.Fatal(err)
+	}
+	passwordHasher := hasher.NewService()
+	randomService := random.NewService()
+	paymentFileParser := paymentfile.NewService("RUB")
+	stepUpNotifier := notifier.NewService(conf.StepUp.WebhookUrl)
+	screener, err := screening.NewService(conf.Screening.ListFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	stepUpPolicy := web.StepUpPolicy{ThresholdCents: conf.StepUp.ThresholdCents, FirstTimeReceiver: conf.StepUp.FirstTimeReceiver}
+	beneficiaryPolicy := web.BeneficiaryPolicy{
+		CoolingOff:           time.Duration(conf.Beneficiaries.CoolingOffHours) * time.Hour,
+		CoolingOffLimitCents: conf.Beneficiaries.CoolingOffLimitCents,
+	}
+
+	service := web.NewService(&postgresService, &passwordHasher, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &paymentFileParser, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &stepUpNotifier, &postgresService, &postgresService, screener, &postgresService, &randomService, stepUpPolicy, beneficiaryPolicy)
+	ctx := context.Background()
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fileFormat := *format
+	if fileFormat == "" {
+		fileFormat = web.PaymentFileFormatCSV
+		if strings.EqualFold(filepath.Ext(*file), ".xml") {
+			fileFormat = web.PaymentFileFormatPain001
+		}
+	}
+
+	senderId, err := strconv.ParseInt(*account, 10, 64)
+	if err != nil {
+		senderId, err = service.ResolveAccountNumber(ctx, iban.Normalize(*account))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fileData, err := service.ImportPaymentFile(ctx, senderId, *user, fileFormat, data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if fileData.Status != web.PaymentFileStatusRejected {
+		if err = service.ProcessPaymentFile(ctx, fileData.Id); err != nil {
+			log.Fatal(err)
+		}
+		if fileData, err = service.GetPaymentFile(ctx, fileData.Id, *user); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fmt.Printf("file %d: %s, %d items, %d cents\n", fileData.Id, fileData.Status, fileData.ItemsCount, fileData.TotalCents)
+	for _, item := range fileData.Items {
+		fmt.Printf("%6d\t%s\t%d\t%s\t%s\n", item.LineNo, item.Receiver, item.AmountCents, item.Status, item.Error)
+	}
+	if fileData.Status != web.PaymentFileStatusCompleted {
+		os.Exit(1)
+	}
+}
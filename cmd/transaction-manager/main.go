@@ -27,4 +27,7 @@ func main() {
 	if err = service.ApplyTransactions(context.Background()); err != nil {
 		log.Fatal(err)
 	}
+	if err = service.ExpireCardHolds(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 }
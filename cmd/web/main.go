@@ -3,15 +3,22 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	"x-bank-ms-bank/auth"
 	"x-bank-ms-bank/config"
 	"x-bank-ms-bank/core/web"
 	"x-bank-ms-bank/infra/hasher"
+	"x-bank-ms-bank/infra/notifier"
+	"x-bank-ms-bank/infra/paymentfile"
 	"x-bank-ms-bank/infra/postgres"
+	"x-bank-ms-bank/infra/random"
+	"x-bank-ms-bank/infra/ratelimit"
+	"x-bank-ms-bank/infra/screening"
 	"x-bank-ms-bank/transport/http"
 	"x-bank-ms-bank/transport/http/jwt"
 )
@@ -28,18 +35,75 @@ func main() {
 		log.Fatal(err)
 	}
 
-	jwtHs512, err := jwt.NewHS512(conf.Hs512SecretKey)
+	postgresService, err := postgres.NewService(conf.Postgres.Login, conf.Postgres.Password, conf.Postgres.Host, conf.Postgres.Port, conf.Postgres.DataBase, conf.Postgres.MaxCons)
 	if err != nil {
 		log.Fatal(err)
 	}
-	postgresService, err := postgres.NewService(conf.Postgres.Login, conf.Postgres.Password, conf.Postgres.Host, conf.Postgres.Port, conf.Postgres.DataBase, conf.Postgres.MaxCons)
+
+	clockSkew := time.Duration(conf.JwtClockSkew) * time.Second
+	authorizer, err := newAuthorizer(conf, jwt.NewValidator(conf.JwtIssuer, conf.JwtAudience, clockSkew, &postgresService))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if conf.AtmHs512SecretKey == "" {
+		log.Fatal("atmHs512SecretKey is not set")
+	}
+	atmJwtHs512, err := jwt.NewHS512(conf.AtmHs512SecretKey, jwt.NewValidator("", "", clockSkew, nil))
 	if err != nil {
 		log.Fatal(err)
 	}
 	passwordHasher := hasher.NewService()
+	randomService := random.NewService()
+	paymentFileParser := paymentfile.NewService("RUB")
+	stepUpNotifier := notifier.NewService(conf.StepUp.WebhookUrl)
+	screener, err := screening.NewService(conf.Screening.ListFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	stepUpPolicy := web.StepUpPolicy{ThresholdCents: conf.StepUp.ThresholdCents, FirstTimeReceiver: conf.StepUp.FirstTimeReceiver}
+	beneficiaryPolicy := web.BeneficiaryPolicy{
+		CoolingOff:           time.Duration(conf.Beneficiaries.CoolingOffHours) * time.Hour,
+		CoolingOffLimitCents: conf.Beneficiaries.CoolingOffLimitCents,
+	}
 
-	service := web.NewService(&postgresService, &passwordHasher, &postgresService, &postgresService)
-	transport := http.NewTransport(service, &jwtHs512)
+	service := web.NewService(&postgresService, &passwordHasher, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &paymentFileParser, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &postgresService, &stepUpNotifier, &postgresService, &postgresService, screener, &postgresService, &randomService, stepUpPolicy, beneficiaryPolicy)
+	go func() {
+		if err := service.ResumePaymentFiles(context.Background()); err != nil {
+			log.Println(err)
+		}
+	}()
+	go func() {
+		for {
+			if err := service.ListenAccountEvents(context.Background()); err != nil {
+				log.Println(err)
+			}
+			time.Sleep(5 * time.Second)
+		}
+	}()
+	if conf.Screening.ReloadSeconds > 0 {
+		go screener.Watch(context.Background(), time.Duration(conf.Screening.ReloadSeconds)*time.Second)
+	}
+	rateLimiter, err := newRateLimiter(conf, &postgresService)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if conf.RateLimits.Backend == "postgres" {
+		go func() {
+			for range time.Tick(time.Minute) {
+				if err := postgresService.DeleteFullRateLimitBuckets(context.Background()); err != nil {
+					log.Println(err)
+				}
+			}
+		}()
+	}
+	rateLimits := http.RateLimits{
+		Ip:        http.RateLimit(conf.RateLimits.Ip),
+		User:      http.RateLimit(conf.RateLimits.User),
+		Transfers: http.RateLimit(conf.RateLimits.Transfers),
+		Atm:       http.RateLimit(conf.RateLimits.Atm),
+		AtmLogin:  http.RateLimit(conf.RateLimits.AtmLogin),
+	}
+	transport := http.NewTransport(service, authorizer, &atmJwtHs512, rateLimiter, rateLimits)
 
 	errCh := transport.Start(*addr)
 	interruptsCh := make(chan os.Signal, 1)
@@ -57,3 +121,35 @@ func main() {
 		}
 	}
 }
+
+func newAuthorizer(conf config.Config, validator jwt.Validator) (auth.Authorizer, error) {
+	switch conf.JwtAlgorithm {
+	case "", "hs512":
+		authorizer, err := jwt.NewHS512(conf.Hs512SecretKey, validator)
+		return &authorizer, err
+	case "rs256":
+		authorizer, err := jwt.NewRS256(conf.Rs256PrivateKey, conf.Rs256PublicKey, conf.JwtKeyId, validator)
+		return &authorizer, err
+	case "es256":
+		authorizer, err := jwt.NewES256(conf.Es256PrivateKey, conf.Es256PublicKey, conf.JwtKeyId, validator)
+		return &authorizer, err
+	case "eddsa":
+		authorizer, err := jwt.NewEdDSA(conf.Ed25519PrivateKey, conf.Ed25519PublicKey, conf.JwtKeyId, validator)
+		return &authorizer, err
+	case "jwks":
+		return jwt.NewJWKS(conf.JwksSource, validator)
+	default:
+		return nil, fmt.Errorf("unknown jwtAlgorithm %q", conf.JwtAlgorithm)
+	}
+}
+
+func newRateLimiter(conf config.Config, postgresService *postgres.Service) (http.RateLimiter, error) {
+	switch conf.RateLimits.Backend {
+	case "", "memory":
+		return ratelimit.NewService(), nil
+	case "postgres":
+		return postgresService, nil
+	default:
+		return nil, fmt.Errorf("unknown rateLimits.backend %q", conf.RateLimits.Backend)
+	}
+}
@@ -7,10 +7,55 @@ import (
 
 type (
 	Config struct {
-		Hs512SecretKey  string   `json:"hs512SecretKey"`
-		Rs256PrivateKey string   `json:"rs256PrivateKey"`
-		Rs256PublicKey  string   `json:"rs256PublicKey"`
-		Postgres        Postgres `json:"postgres"`
+		JwtAlgorithm      string        `json:"jwtAlgorithm"`
+		JwtKeyId          string        `json:"jwtKeyId"`
+		JwtIssuer         string        `json:"jwtIssuer"`
+		JwtAudience       string        `json:"jwtAudience"`
+		JwtClockSkew      int           `json:"jwtClockSkew"`
+		Hs512SecretKey    string        `json:"hs512SecretKey"`
+		AtmHs512SecretKey string        `json:"atmHs512SecretKey"`
+		Rs256PrivateKey   string        `json:"rs256PrivateKey"`
+		Rs256PublicKey    string        `json:"rs256PublicKey"`
+		Es256PrivateKey   string        `json:"es256PrivateKey"`
+		Es256PublicKey    string        `json:"es256PublicKey"`
+		Ed25519PrivateKey string        `json:"ed25519PrivateKey"`
+		Ed25519PublicKey  string        `json:"ed25519PublicKey"`
+		JwksSource        string        `json:"jwksSource"`
+		StepUp            StepUp        `json:"stepUp"`
+		Beneficiaries     Beneficiaries `json:"beneficiaries"`
+		Screening         Screening     `json:"screening"`
+		RateLimits        RateLimits    `json:"rateLimits"`
+		Postgres          Postgres      `json:"postgres"`
+	}
+
+	StepUp struct {
+		ThresholdCents    int64  `json:"thresholdCents"`
+		FirstTimeReceiver bool   `json:"firstTimeReceiver"`
+		WebhookUrl        string `json:"webhookUrl"`
+	}
+
+	Beneficiaries struct {
+		CoolingOffHours      int   `json:"coolingOffHours"`
+		CoolingOffLimitCents int64 `json:"coolingOffLimitCents"`
+	}
+
+	Screening struct {
+		ListFile      string `json:"listFile"`
+		ReloadSeconds int    `json:"reloadSeconds"`
+	}
+
+	RateLimits struct {
+		Backend   string    `json:"backend"`
+		Ip        RateLimit `json:"ip"`
+		User      RateLimit `json:"user"`
+		Transfers RateLimit `json:"transfers"`
+		Atm       RateLimit `json:"atm"`
+		AtmLogin  RateLimit `json:"atmLogin"`
+	}
+
+	RateLimit struct {
+		RequestsPerMinute int `json:"requestsPerMinute"`
+		Burst             int `json:"burst"`
 	}
 
 	Postgres struct {
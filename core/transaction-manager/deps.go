@@ -8,5 +8,6 @@ import (
 type (
 	TransactionStorage interface {
 		ConfirmTransaction(ctx context.Context, confirmationTime time.Duration) error
+		ExpireCardHolds(ctx context.Context) error
 	}
 )
@@ -24,3 +24,7 @@ const (
 func (s *Service) ApplyTransactions(ctx context.Context) error {
 	return s.transactionStorage.ConfirmTransaction(ctx, confirmationTime)
 }
+
+func (s *Service) ExpireCardHolds(ctx context.Context) error {
+	return s.transactionStorage.ExpireCardHolds(ctx)
+}
@@ -1,25 +1,157 @@
 package web
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type (
 	AccountStorage interface {
 		GetUserAccounts(ctx context.Context, userId int64) ([]UserAccountData, error)
-		OpenUserAccount(ctx context.Context, userId int64) error
+		OpenUserAccount(ctx context.Context, userId int64, number string) error
 		BlockUserAccount(ctx context.Context, accountId int64) error
 		GetAccountHistory(ctx context.Context, accountId, limit, offset int64) ([]AccountTransactionsData, int64, error)
 		UpdateAtmAccount(ctx context.Context, amountCents, accountId int64) error
 		GetAccountDataById(ctx context.Context, senderId int64) (UserAccountData, error)
+		GetAccountIdByNumber(ctx context.Context, number string) (int64, error)
+		GetAccountsDataByIds(ctx context.Context, accountIds []int64) (map[int64]UserAccountData, error)
+		SetUserIdentified(ctx context.Context, userId int64, identification OwnerIdentificationData) (OwnerIdentificationData, bool, bool, error)
 	}
 
 	TransactionStorage interface {
-		CreateTransaction(ctx context.Context, senderId, receiverId, amountCents int64, description string) error
+		CreateTransaction(ctx context.Context, senderId, receiverId, amountCents int64, description string) (int64, error)
+		CreateTransactionBatch(ctx context.Context, senderId int64, itemsCount int, totalCents int64) (int64, error)
+		LinkTransactionToBatch(ctx context.Context, batchId, transactionId int64) error
+		GetTransactionBatch(ctx context.Context, batchId int64) (TransactionBatchData, error)
+		GetAccountTransfersSums(ctx context.Context, accountId int64, from, to time.Time) (int64, int64, error)
 	}
 
 	AtmStorage interface {
 		GetAtmDataByLogin(ctx context.Context, login string) (AtmData, error)
-		UpdateAtmCash(ctx context.Context, amountCents, atmId int64) error
-		LogCashOperation(ctx context.Context, atmId, amountCents, userId int64) error
+		GetAtmDataById(ctx context.Context, atmId int64) (AtmData, error)
+		RegisterAtmLoginFailure(ctx context.Context, atmId int64, maxAttempts int, lockDuration time.Duration) error
+		ResetAtmLoginFailures(ctx context.Context, atmId int64) error
+		UpdateAtmCash(ctx context.Context, amountCents, atmId int64) (int64, error)
+		LogCashOperation(ctx context.Context, atmId, amountCents, userAccountId int64) error
+		GetCashOperations(ctx context.Context, atmId int64, from, to time.Time) ([]CashOperationData, error)
+		GetLastCashOperationBefore(ctx context.Context, atmId int64, before time.Time) (CashOperationData, error)
+		GetAtmCassettes(ctx context.Context, atmId int64) ([]AtmBanknotesData, error)
+		UpdateAtmCassettes(ctx context.Context, atmId int64, banknotes []AtmBanknotesData) error
+		CreateAtmAlert(ctx context.Context, atmId int64, alertType string, cashCents int64) error
+		CreateAtm(ctx context.Context, login string, passwordHash []byte, accountNumber string) (AtmData, error)
+		GetAtms(ctx context.Context, limit, offset int64) ([]AtmData, int64, error)
+		UpdateAtmPassword(ctx context.Context, atmId int64, passwordHash []byte) error
+		UpdateAtmStatus(ctx context.Context, atmId int64, status string) error
+	}
+
+	AliasStorage interface {
+		CreatePaymentAlias(ctx context.Context, accountId int64, aliasType, value, ownerName string) error
+		GetUserPaymentAliases(ctx context.Context, userId int64) ([]PaymentAliasData, error)
+		GetPaymentAlias(ctx context.Context, value string) (PaymentAliasData, error)
+		DeletePaymentAlias(ctx context.Context, aliasId, userId int64) error
+		CreatePaymentAliasVerification(ctx context.Context, verification PaymentAliasVerificationData) (int64, error)
+		GetPaymentAliasVerificationForUpdate(ctx context.Context, verificationId int64) (PaymentAliasVerificationData, error)
+		UpdatePaymentAliasVerification(ctx context.Context, verification PaymentAliasVerificationData) error
+	}
+
+	PaymentRequestStorage interface {
+		CreatePaymentRequest(ctx context.Context, requesterAccountId, payerAccountId, amountCents int64, description string, ttl time.Duration) (int64, error)
+		GetPaymentRequest(ctx context.Context, requestId int64) (PaymentRequestData, error)
+		GetUserPaymentRequests(ctx context.Context, userId int64, incoming bool, limit, offset int64) ([]PaymentRequestData, int64, error)
+		ClaimPaymentRequest(ctx context.Context, requestId int64, status string) error
+		LinkPaymentRequestTransaction(ctx context.Context, requestId, transactionId int64) error
+	}
+
+	PaymentFileStorage interface {
+		CreatePaymentFile(ctx context.Context, fileData PaymentFileData) (int64, error)
+		GetPaymentFile(ctx context.Context, fileId int64) (PaymentFileData, error)
+		GetPaymentFileIdsByStatus(ctx context.Context, status string) ([]int64, error)
+		UpdatePaymentFileStatus(ctx context.Context, fileId int64, status string) error
+		ClaimPaymentFileItem(ctx context.Context, itemId int64) (bool, error)
+		UpdatePaymentFileItem(ctx context.Context, itemId int64, status string, transactionId int64, errorMessage string) error
+	}
+
+	PaymentFileParser interface {
+		ParsePaymentFile(ctx context.Context, format string, data []byte) ([]PaymentFileItem, error)
+	}
+
+	CardStorage interface {
+		CreateCard(ctx context.Context, cardData CardData) (int64, error)
+		GetCardByNumber(ctx context.Context, number string) (CardData, error)
+		GetCardById(ctx context.Context, cardId int64) (CardData, error)
+		GetUserCards(ctx context.Context, userId int64) ([]CardData, error)
+		RegisterCardPinFailure(ctx context.Context, cardId int64, maxTries int) error
+		ResetCardPinTries(ctx context.Context, cardId int64) error
+		UpdateCardStatus(ctx context.Context, cardId int64, status string) error
+		UpdateCardLimits(ctx context.Context, cardId, perTransactionLimitCents, dailyLimitCents int64) error
+		GetCardSpentSince(ctx context.Context, cardId int64, since time.Time) (int64, error)
+		CreateCardOperation(ctx context.Context, operation CardOperationData) (int64, error)
+		CreateCardHold(ctx context.Context, hold CardHoldData) (int64, error)
+		GetCardHoldForUpdate(ctx context.Context, holdId int64) (CardHoldData, error)
+		UpdateCardHold(ctx context.Context, hold CardHoldData) error
+	}
+
+	TransactionManager interface {
+		WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+		WithinNewTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	}
+
+	AccountEventStorage interface {
+		ListenAccountEvents(ctx context.Context, handler func(userId, eventId int64)) error
+		GetAccountEvent(ctx context.Context, eventId int64) (AccountEventData, error)
+		GetAccountEventsAfter(ctx context.Context, userId, afterId, limit int64) ([]AccountEventData, error)
+	}
+	BeneficiaryStorage interface {
+		CreateBeneficiary(ctx context.Context, userId, accountId int64, nickname string) (int64, error)
+		GetUserBeneficiaries(ctx context.Context, userId int64) ([]BeneficiaryData, error)
+		GetUserBeneficiaryByAccount(ctx context.Context, userId, accountId int64) (BeneficiaryData, error)
+		UpdateBeneficiaryNickname(ctx context.Context, beneficiaryId, userId int64, nickname string) error
+		DeleteBeneficiary(ctx context.Context, beneficiaryId, userId int64) error
+		GetUserTransfersSumTo(ctx context.Context, userId, receiverId int64, since time.Time) (int64, error)
+	}
+
+	StepUpStorage interface {
+		HasUserTransfersTo(ctx context.Context, userId, receiverId int64) (bool, error)
+		CreateStepUpChallenge(ctx context.Context, challenge StepUpChallengeData) (int64, error)
+		GetStepUpChallengeForUpdate(ctx context.Context, challengeId int64) (StepUpChallengeData, error)
+		UpdateStepUpChallenge(ctx context.Context, challenge StepUpChallengeData) error
+	}
+
+	StepUpNotifier interface {
+		SendStepUpCode(ctx context.Context, userId int64, code string) error
+		SendAliasVerificationCode(ctx context.Context, aliasType, value, code string) error
+	}
+
+	FraudStorage interface {
+		GetTransferStats(ctx context.Context, senderId, receiverId int64, velocitySince, historySince, roundTripSince time.Time) (TransferStatsData, error)
+		HoldTransactionForReview(ctx context.Context, transactionId int64, score int, reasons []string) error
+		GetTransactionReviews(ctx context.Context, status string) ([]TransactionReviewData, error)
+		GetTransactionReviewForUpdate(ctx context.Context, transactionId int64) (TransactionReviewData, error)
+		ApproveTransactionReview(ctx context.Context, transactionId, reviewerId int64) error
+		RejectTransactionReview(ctx context.Context, transactionId, reviewerId int64) error
+	}
+
+	ScreeningStorage interface {
+		GetAccountOwnerNames(ctx context.Context, accountId int64) ([]string, error)
+		CreateScreeningHits(ctx context.Context, hits []ScreeningHitData) error
+	}
+
+	Screener interface {
+		Screen(ctx context.Context, subject ScreeningSubject) []ScreeningMatch
+	}
+
+	AuditStorage interface {
+		AppendAuditEntry(ctx context.Context, entry AuditEntryData) error
+		GetAuditEntries(ctx context.Context, filter AuditFilterData, limit, offset int64) ([]AuditEntryData, int64, error)
+		GetAuditEntriesAfter(ctx context.Context, afterId, limit int64) ([]AuditEntryData, error)
+	}
+
+	TokenStorage interface {
+		RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	}
+
+	RandomGenerator interface {
+		GenerateString(ctx context.Context, set string, size int) (string, error)
 	}
 
 	PasswordHasher interface {
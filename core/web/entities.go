@@ -4,25 +4,370 @@ import "time"
 
 type (
 	UserAccountData struct {
-		Id           int64
-		BalanceCents int64
-		Status       string
-		UserId       int64
+		Id                   int64
+		Number               string
+		BalanceCents         int64
+		BookedCents          int64
+		AvailableCents       int64
+		PendingIncomingCents int64
+		PendingOutgoingCents int64
+		Status               string
+		UserId               int64
+		OwnerIdentified      bool
+		OwnerName            string
+	}
+
+	OwnerIdentificationData struct {
+		Identified bool
+		FullName   string
 	}
 
 	AccountTransactionsData struct {
-		SenderId    int64
-		ReceiverId  int64
-		Status      string
-		CreatedAt   time.Time
-		AmountCents int64
-		Description string
+		SenderId             int64
+		ReceiverId           int64
+		Status               string
+		CreatedAt            time.Time
+		AmountCents          int64
+		Description          string
+		CounterpartyNickname string
 	}
 
 	AtmData struct {
-		Id           int64
-		AccountId    int64
-		PasswordHash []byte
-		CashCents    int64
+		Id                    int64
+		AccountId             int64
+		AccountNumber         string
+		Login                 string
+		PasswordHash          []byte
+		Status                string
+		CashCents             int64
+		LowCashThresholdCents int64
+		IsLocked              bool
+		CreatedAt             time.Time
+		Cassettes             []AtmBanknotesData
+	}
+
+	CardData struct {
+		Id                       int64
+		AccountId                int64
+		UserId                   int64
+		Number                   string
+		PinHash                  []byte
+		PinTries                 int
+		CvvHash                  []byte
+		Status                   string
+		ExpiresAt                time.Time
+		PerTransactionLimitCents int64
+		DailyLimitCents          int64
+		CreatedAt                time.Time
+	}
+
+	CardPaymentData struct {
+		MerchantAccountId int64
+		CardNumber        string
+		ExpiryMonth       int
+		ExpiryYear        int
+		Cvv               string
+		AmountCents       int64
+		Description       string
+	}
+
+	CardHoldData struct {
+		Id            int64
+		CardId        int64
+		AccountId     int64
+		ReceiverId    int64
+		AmountCents   int64
+		CapturedCents int64
+		TransactionId int64
+		Status        string
+		Description   string
+		ExpiresAt     time.Time
+		CreatedAt     time.Time
+	}
+
+	BeneficiaryData struct {
+		Id              int64
+		UserId          int64
+		AccountId       int64
+		AccountNumber   string
+		Nickname        string
+		CreatedAt       time.Time
+		CoolingOffUntil time.Time
+	}
+
+	BeneficiaryPolicy struct {
+		CoolingOff           time.Duration
+		CoolingOffLimitCents int64
+	}
+
+	StepUpChallengeData struct {
+		Id            int64
+		Operation     string
+		UserId        int64
+		SenderId      int64
+		ReceiverId    int64
+		AmountCents   int64
+		Description   string
+		OperationHash string
+		CodeHash      []byte
+		TokenHash     []byte
+		Attempts      int
+		Status        string
+		ExpiresAt     time.Time
+		CreatedAt     time.Time
+	}
+
+	TransferStatsData struct {
+		RecentCount     int64
+		HistoryCount    int64
+		HistorySumCents int64
+		HasTransfersTo  bool
+		HasRoundTrip    bool
+	}
+
+	TransactionReviewData struct {
+		TransactionId int64
+		SenderId      int64
+		ReceiverId    int64
+		AmountCents   int64
+		Description   string
+		Score         int
+		Reasons       []string
+		Status        string
+		ReviewerId    int64
+		CreatedAt     time.Time
+		ReviewedAt    time.Time
+	}
+
+	ScreeningSubject struct {
+		UserId        int64
+		AccountNumber string
+		Names         []string
+	}
+
+	ScreeningMatch struct {
+		ListType string
+		Entry    string
+	}
+
+	ScreeningHitData struct {
+		Operation     string
+		UserId        int64
+		AccountId     int64
+		TransactionId int64
+		ListType      string
+		Entry         string
+	}
+
+	AuditRequestData struct {
+		ActorType string
+		ActorId   string
+		RequestId string
+		Ip        string
+		UserAgent string
+	}
+
+	AuditEntryData struct {
+		Id        int64
+		ActorType string
+		ActorId   string
+		RequestId string
+		Ip        string
+		UserAgent string
+		Action    string
+		EntityId  int64
+		Before    []byte
+		After     []byte
+		Outcome   string
+		Error     string
+		CreatedAt time.Time
+		PrevHash  string
+		Hash      string
+	}
+
+	AuditFilterData struct {
+		ActorType string
+		ActorId   string
+		Action    string
+		EntityId  int64
+		From      time.Time
+		To        time.Time
+	}
+
+	AuditVerificationData struct {
+		Checked  int64
+		Valid    bool
+		BrokenId int64
+	}
+
+	StepUpPolicy struct {
+		ThresholdCents    int64
+		FirstTimeReceiver bool
+	}
+
+	CardOperationData struct {
+		Id            int64
+		CardId        int64
+		Type          string
+		ReceiverId    int64
+		TransactionId int64
+		AmountCents   int64
+		Description   string
+	}
+
+	ATMSessionData struct {
+		Id    string
+		AtmId int64
+	}
+
+	AtmBanknotesData struct {
+		DenominationCents int64
+		Count             int64
+	}
+
+	PaymentAliasData struct {
+		Id        int64
+		AccountId int64
+		Type      string
+		Value     string
+		OwnerName string
+		CreatedAt time.Time
+	}
+
+	PaymentAliasVerificationData struct {
+		Id        int64
+		UserId    int64
+		AccountId int64
+		Type      string
+		Value     string
+		CodeHash  []byte
+		Attempts  int
+		Status    string
+		ExpiresAt time.Time
+		CreatedAt time.Time
+	}
+
+	PaymentRequestData struct {
+		Id                 int64
+		RequesterAccountId int64
+		PayerAccountId     int64
+		AmountCents        int64
+		Description        string
+		Status             string
+		ExpiresAt          time.Time
+		CreatedAt          time.Time
+		TransactionId      int64
+	}
+
+	BatchTransferItem struct {
+		ReceiverId     int64
+		ReceiverNumber string
+		ReceiverAlias  string
+		AmountCents    int64
+		Description    string
+	}
+
+	BatchTransferResult struct {
+		Index         int
+		ReceiverId    int64
+		AmountCents   int64
+		TransactionId int64
+		Error         string
+	}
+
+	BatchTransferData struct {
+		BatchId    int64
+		TotalCents int64
+		Items      []BatchTransferResult
+	}
+
+	TransactionBatchData struct {
+		Id         int64
+		SenderId   int64
+		TotalCents int64
+		CreatedAt  time.Time
+		Items      []BatchTransactionData
+	}
+
+	BatchTransactionData struct {
+		TransactionId int64
+		ReceiverId    int64
+		AmountCents   int64
+		Description   string
+		Status        string
+	}
+
+	PaymentFileData struct {
+		Id         int64
+		SenderId   int64
+		UserId     int64
+		Format     string
+		Hash       string
+		Status     string
+		ItemsCount int
+		TotalCents int64
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+		Items      []PaymentFileItem
+	}
+
+	PaymentFileItem struct {
+		Id            int64
+		LineNo        int
+		Receiver      string
+		ReceiverId    int64
+		AmountCents   int64
+		Description   string
+		DebtorAccount string
+		Status        string
+		Error         string
+		TransactionId int64
+	}
+
+	ResolvedAliasData struct {
+		Type      string
+		OwnerName string
+	}
+
+	CashOperationData struct {
+		Id                int64
+		AtmId             int64
+		UserAccountId     int64
+		AmountCents       int64
+		CashAfterCents    int64
+		BalanceAfterCents int64
+		HasSnapshot       bool
+		DiscrepancyCents  int64
+		CreatedAt         time.Time
+	}
+
+	AtmCashReportData struct {
+		AtmId                   int64
+		AccountId               int64
+		From                    time.Time
+		To                      time.Time
+		Operations              []CashOperationData
+		OperationsSumCents      int64
+		OpeningCashCents        int64
+		ClosingCashCents        int64
+		CashDiscrepancyCents    int64
+		OpeningBalanceCents     int64
+		ClosingBalanceCents     int64
+		TransfersInCents        int64
+		TransfersOutCents       int64
+		BalanceDiscrepancyCents int64
+		CurrentCashCents        int64
+		Balanced                bool
+	}
+
+	AccountEventData struct {
+		Id            int64
+		AccountId     int64
+		UserId        int64
+		Type          string
+		TransactionId int64
+		AmountCents   int64
+		BalanceCents  int64
+		CreatedAt     time.Time
 	}
 )
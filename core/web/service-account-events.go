@@ -0,0 +1,142 @@
+package web
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+const (
+	AccountEventTransactionCreated   = "TRANSACTION_CREATED"
+	AccountEventTransactionConfirmed = "TRANSACTION_CONFIRMED"
+	AccountEventBalanceChanged       = "BALANCE_CHANGED"
+
+	accountEventsReplayLimit = 1000
+	accountEventsBufferSize  = 64
+)
+
+type accountEventHub struct {
+	mu          sync.Mutex
+	subscribers map[int64]map[chan AccountEventData]struct{}
+}
+
+func newAccountEventHub() *accountEventHub {
+	return &accountEventHub{
+		subscribers: make(map[int64]map[chan AccountEventData]struct{}),
+	}
+}
+
+func (h *accountEventHub) subscribe(userId int64) chan AccountEventData {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan AccountEventData, accountEventsBufferSize)
+	if h.subscribers[userId] == nil {
+		h.subscribers[userId] = make(map[chan AccountEventData]struct{})
+	}
+	h.subscribers[userId][ch] = struct{}{}
+	return ch
+}
+
+func (h *accountEventHub) unsubscribe(userId int64, ch chan AccountEventData) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(userId, ch)
+}
+
+func (h *accountEventHub) remove(userId int64, ch chan AccountEventData) {
+	if _, ok := h.subscribers[userId][ch]; !ok {
+		return
+	}
+	delete(h.subscribers[userId], ch)
+	if len(h.subscribers[userId]) == 0 {
+		delete(h.subscribers, userId)
+	}
+	close(ch)
+}
+
+func (h *accountEventHub) hasSubscribers(userId int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscribers[userId]) > 0
+}
+
+func (h *accountEventHub) publish(event AccountEventData) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[event.UserId] {
+		select {
+		case ch <- event:
+		default:
+			h.remove(event.UserId, ch)
+		}
+	}
+}
+
+func (s *Service) ListenAccountEvents(ctx context.Context) error {
+	return s.accountEventStorage.ListenAccountEvents(ctx, func(userId, eventId int64) {
+		if !s.accountEvents.hasSubscribers(userId) {
+			return
+		}
+
+		event, err := s.accountEventStorage.GetAccountEvent(ctx, eventId)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		s.accountEvents.publish(event)
+	})
+}
+
+func (s *Service) SubscribeAccountEvents(ctx context.Context, userId, lastEventId int64) (<-chan AccountEventData, error) {
+	live := s.accountEvents.subscribe(userId)
+
+	var replay []AccountEventData
+	if lastEventId > 0 {
+		var err error
+		replay, err = s.accountEventStorage.GetAccountEventsAfter(ctx, userId, lastEventId, accountEventsReplayLimit)
+		if err != nil {
+			s.accountEvents.unsubscribe(userId, live)
+			return nil, err
+		}
+	}
+
+	events := make(chan AccountEventData)
+	go func() {
+		defer close(events)
+		defer s.accountEvents.unsubscribe(userId, live)
+
+		sent := make(map[int64]struct{}, len(replay))
+		for _, event := range replay {
+			select {
+			case events <- event:
+				sent[event.Id] = struct{}{}
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for {
+			select {
+			case event, ok := <-live:
+				if !ok {
+					return
+				}
+				if _, ok = sent[event.Id]; ok {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
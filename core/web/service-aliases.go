@@ -0,0 +1,228 @@
+package web
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	AliasTypePhone = "PHONE"
+	AliasTypeEmail = "EMAIL"
+
+	AliasVerificationStatusPending   = "PENDING"
+	AliasVerificationStatusConfirmed = "CONFIRMED"
+	AliasVerificationStatusFailed    = "FAILED"
+
+	aliasVerificationMaxAttempts = 3
+	aliasVerificationCodeTTL     = 10 * time.Minute
+)
+
+var (
+	isValidPhoneAlias  = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`).MatchString
+	isValidEmailAlias  = regexp.MustCompile(`^[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}$`).MatchString
+	phoneAliasReplacer = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "")
+)
+
+func ParsePaymentAlias(value string) (aliasType, normalized string, ok bool) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "@") {
+		normalized = strings.ToLower(value)
+		if len(normalized) > 254 || !isValidEmailAlias(normalized) {
+			return "", "", false
+		}
+		return AliasTypeEmail, normalized, true
+	}
+
+	normalized = phoneAliasReplacer.Replace(value)
+	if !isValidPhoneAlias(normalized) {
+		return "", "", false
+	}
+	return AliasTypePhone, normalized, true
+}
+
+func (s *Service) BindPaymentAlias(ctx context.Context, accountId, userId int64, alias string) (_ PaymentAliasVerificationData, err error) {
+	audit := s.startAudit(ctx, AuditActionAliasVerify, accountId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	aliasType, value, ok := ParsePaymentAlias(alias)
+	if !ok {
+		return PaymentAliasVerificationData{}, cerrors.NewErrorWithUserMessage(ercodes.InvalidAlias, nil, "Неверный формат псевдонима")
+	}
+	if _, err = s.getAliasAccount(ctx, accountId, userId); err != nil {
+		return PaymentAliasVerificationData{}, err
+	}
+	if _, err = s.aliasStorage.GetPaymentAlias(ctx, value); err == nil {
+		return PaymentAliasVerificationData{}, cerrors.NewErrorWithUserMessage(ercodes.AliasAlreadyExists, nil, "Псевдоним уже привязан к счёту")
+	} else if !hasErrorCode(err, ercodes.AliasNotFound) {
+		return PaymentAliasVerificationData{}, err
+	}
+
+	code, err := s.randomGenerator.GenerateString(ctx, stepUpCodeCharset, stepUpCodeLength)
+	if err != nil {
+		return PaymentAliasVerificationData{}, err
+	}
+	codeHash, err := s.passwordHasher.HashPassword(ctx, []byte(code), stepUpHashCost)
+	if err != nil {
+		return PaymentAliasVerificationData{}, err
+	}
+
+	verification := PaymentAliasVerificationData{
+		UserId:    userId,
+		AccountId: accountId,
+		Type:      aliasType,
+		Value:     value,
+		CodeHash:  codeHash,
+		Status:    AliasVerificationStatusPending,
+		ExpiresAt: time.Now().Add(aliasVerificationCodeTTL),
+	}
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		var err error
+		if verification.Id, err = s.aliasStorage.CreatePaymentAliasVerification(ctx, verification); err != nil {
+			return err
+		}
+		audit.after = auditValues{"verificationId": verification.Id, "type": aliasType, "value": value, "status": verification.Status}
+		return s.stepUpNotifier.SendAliasVerificationCode(ctx, aliasType, value, code)
+	})
+	if err != nil {
+		return PaymentAliasVerificationData{}, err
+	}
+	return verification, nil
+}
+
+func (s *Service) ConfirmPaymentAlias(ctx context.Context, verificationId, userId int64, code string) (err error) {
+	audit := s.startAudit(ctx, AuditActionAliasBind, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	var codeErr error
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		verification, err := s.aliasStorage.GetPaymentAliasVerificationForUpdate(ctx, verificationId)
+		if err != nil {
+			return err
+		}
+		if verification.UserId != userId {
+			return cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+		}
+		if verification.Status != AliasVerificationStatusPending || !time.Now().Before(verification.ExpiresAt) {
+			return cerrors.NewErrorWithUserMessage(ercodes.AliasVerificationNotActive, nil, "Подтверждение псевдонима недействительно")
+		}
+		audit.entry.EntityId = verification.AccountId
+		audit.before = auditValues{"verificationId": verification.Id, "status": verification.Status, "attempts": verification.Attempts}
+
+		if err = s.passwordHasher.CompareHashAndPassword(ctx, code, verification.CodeHash); err != nil {
+			verification.Attempts++
+			if verification.Attempts >= aliasVerificationMaxAttempts {
+				verification.Status = AliasVerificationStatusFailed
+			}
+			codeErr = cerrors.NewErrorWithUserMessage(ercodes.WrongStepUpCode, err, "Неверный код подтверждения")
+			if err = s.aliasStorage.UpdatePaymentAliasVerification(ctx, verification); err != nil {
+				return err
+			}
+			audit.after = auditValues{"verificationId": verification.Id, "status": verification.Status, "attempts": verification.Attempts}
+			return s.appendAudit(ctx, audit, codeErr)
+		}
+
+		accountInfo, err := s.getAliasAccount(ctx, verification.AccountId, userId)
+		if err != nil {
+			return err
+		}
+		verification.Status = AliasVerificationStatusConfirmed
+		if err = s.aliasStorage.UpdatePaymentAliasVerification(ctx, verification); err != nil {
+			return err
+		}
+		if err = s.aliasStorage.CreatePaymentAlias(ctx, verification.AccountId, verification.Type, verification.Value, accountInfo.OwnerName); err != nil {
+			return err
+		}
+		audit.after = auditValues{"type": verification.Type, "value": verification.Value, "ownerName": accountInfo.OwnerName}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return codeErr
+}
+
+func (s *Service) GetPaymentAliases(ctx context.Context, userId int64) ([]PaymentAliasData, error) {
+	return s.aliasStorage.GetUserPaymentAliases(ctx, userId)
+}
+
+func (s *Service) DeletePaymentAlias(ctx context.Context, aliasId, userId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionAliasDelete, aliasId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		return s.aliasStorage.DeletePaymentAlias(ctx, aliasId, userId)
+	})
+}
+
+func (s *Service) ResolvePaymentAlias(ctx context.Context, alias string) (ResolvedAliasData, error) {
+	aliasData, err := s.getPaymentAlias(ctx, alias)
+	if err != nil {
+		return ResolvedAliasData{}, err
+	}
+
+	return ResolvedAliasData{
+		Type:      aliasData.Type,
+		OwnerName: maskOwnerName(aliasData.OwnerName),
+	}, nil
+}
+
+func (s *Service) MakeTransactionToAlias(ctx context.Context, senderId int64, receiverAlias string, amountCents, userId int64, description, confirmationToken string) (int64, error) {
+	aliasData, err := s.getPaymentAlias(ctx, receiverAlias)
+	if err != nil {
+		return 0, err
+	}
+	return s.MakeUserTransaction(ctx, senderId, aliasData.AccountId, amountCents, userId, description, confirmationToken)
+}
+
+func (s *Service) CreateStepUpChallengeToAlias(ctx context.Context, senderId int64, receiverAlias string, amountCents, userId int64, description string) (StepUpChallengeData, error) {
+	aliasData, err := s.getPaymentAlias(ctx, receiverAlias)
+	if err != nil {
+		return StepUpChallengeData{}, err
+	}
+	return s.CreateStepUpChallenge(ctx, senderId, aliasData.AccountId, amountCents, userId, description)
+}
+
+func (s *Service) getAliasAccount(ctx context.Context, accountId, userId int64) (UserAccountData, error) {
+	accountInfo, err := s.accountStorage.GetAccountDataById(ctx, accountId)
+	if err != nil {
+		return UserAccountData{}, err
+	}
+	if accountInfo.UserId != userId {
+		return UserAccountData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+	if accountInfo.Status == "BLOCKED" {
+		return UserAccountData{}, cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт заблокирован")
+	}
+	if !accountInfo.OwnerIdentified || accountInfo.OwnerName == "" {
+		return UserAccountData{}, cerrors.NewErrorWithUserMessage(ercodes.IdentificationRequired, nil, "Для привязки псевдонима пройдите идентификацию")
+	}
+	return accountInfo, nil
+}
+
+func (s *Service) getPaymentAlias(ctx context.Context, alias string) (PaymentAliasData, error) {
+	_, value, ok := ParsePaymentAlias(alias)
+	if !ok {
+		return PaymentAliasData{}, cerrors.NewErrorWithUserMessage(ercodes.InvalidAlias, nil, "Неверный формат псевдонима")
+	}
+	return s.aliasStorage.GetPaymentAlias(ctx, value)
+}
+
+func maskOwnerName(name string) string {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return ""
+	}
+
+	masked := make([]string, 0, len(words))
+	masked = append(masked, words[0])
+	for _, word := range words[1:] {
+		firstRune, _ := utf8.DecodeRuneInString(word)
+		masked = append(masked, string(firstRune)+".")
+	}
+	return strings.Join(masked, " ")
+}
@@ -0,0 +1,89 @@
+package web
+
+import (
+	"context"
+	"time"
+)
+
+func (s *Service) GetAtmCashReport(ctx context.Context, atmId int64, from, to time.Time) (AtmCashReportData, error) {
+	atmData, err := s.atmStorage.GetAtmDataById(ctx, atmId)
+	if err != nil {
+		return AtmCashReportData{}, err
+	}
+
+	operations, err := s.atmStorage.GetCashOperations(ctx, atmId, from, to)
+	if err != nil {
+		return AtmCashReportData{}, err
+	}
+	anchor, err := s.atmStorage.GetLastCashOperationBefore(ctx, atmId, from)
+	if err != nil {
+		return AtmCashReportData{}, err
+	}
+
+	report := AtmCashReportData{
+		AtmId:            atmId,
+		AccountId:        atmData.AccountId,
+		From:             from,
+		To:               to,
+		Operations:       operations,
+		CurrentCashCents: atmData.CashCents,
+		OpeningCashCents: atmData.CashCents,
+		Balanced:         true,
+	}
+
+	var (
+		prev            *CashOperationData
+		balanceAnchor   *CashOperationData
+		cashSumCents    int64
+		balanceSumCents int64
+	)
+	if anchor.Id != 0 {
+		prev, balanceAnchor = &anchor, &anchor
+		report.OpeningCashCents = anchor.CashAfterCents
+		report.OpeningBalanceCents = anchor.BalanceAfterCents
+	}
+
+	for i := range report.Operations {
+		operation := &report.Operations[i]
+		report.OperationsSumCents += operation.AmountCents
+		if !operation.HasSnapshot {
+			continue
+		}
+
+		if prev == nil {
+			report.OpeningCashCents = operation.CashAfterCents - operation.AmountCents
+			report.OpeningBalanceCents = operation.BalanceAfterCents
+			balanceAnchor = operation
+		} else {
+			operation.DiscrepancyCents = operation.CashAfterCents - prev.CashAfterCents - operation.AmountCents
+			if operation.DiscrepancyCents != 0 {
+				report.Balanced = false
+			}
+			balanceSumCents += operation.AmountCents
+		}
+		cashSumCents += operation.AmountCents
+		prev = operation
+	}
+
+	if prev == nil {
+		report.ClosingCashCents = report.OpeningCashCents
+		return report, nil
+	}
+	report.ClosingCashCents = prev.CashAfterCents
+	report.ClosingBalanceCents = prev.BalanceAfterCents
+	report.CashDiscrepancyCents = report.ClosingCashCents - report.OpeningCashCents - cashSumCents
+	if !to.Before(time.Now()) {
+		report.CashDiscrepancyCents += report.CurrentCashCents - report.ClosingCashCents
+	}
+
+	report.TransfersInCents, report.TransfersOutCents, err = s.transactionStorage.GetAccountTransfersSums(ctx, atmData.AccountId, balanceAnchor.CreatedAt, prev.CreatedAt)
+	if err != nil {
+		return AtmCashReportData{}, err
+	}
+	report.BalanceDiscrepancyCents = report.ClosingBalanceCents - report.OpeningBalanceCents - balanceSumCents - report.TransfersInCents + report.TransfersOutCents
+
+	if report.CashDiscrepancyCents != 0 || report.BalanceDiscrepancyCents != 0 {
+		report.Balanced = false
+	}
+	return report, nil
+}
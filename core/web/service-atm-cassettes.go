@@ -0,0 +1,133 @@
+package web
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	AtmAlertLowCash = "LOW_CASH"
+)
+
+var AtmBanknoteDenominations = []int64{10000, 20000, 50000, 100000, 200000, 500000}
+
+func (s *Service) supplyATM(ctx context.Context, atmData AtmData, banknotes []AtmBanknotesData) (int64, error) {
+	amountCents, err := banknotesAmount(banknotes)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = s.atmStorage.UpdateAtmCassettes(ctx, atmData.Id, banknotes); err != nil {
+		return 0, err
+	}
+	if err = s.changeATMState(ctx, atmData, amountCents); err != nil {
+		return 0, err
+	}
+	return amountCents, nil
+}
+
+func (s *Service) dispenseATM(ctx context.Context, atmData AtmData, amountCents int64) ([]AtmBanknotesData, error) {
+	cassettes, err := s.atmStorage.GetAtmCassettes(ctx, atmData.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassettesCents int64
+	for _, cassette := range cassettes {
+		cassettesCents += cassette.DenominationCents * cassette.Count
+	}
+	if cassettesCents < amountCents {
+		return nil, cerrors.NewErrorWithUserMessage(ercodes.NotEnoughAtmCash, nil, "В банкомате недостаточно наличных")
+	}
+
+	banknotes, ok := dispenseBanknotes(cassettes, amountCents)
+	if !ok {
+		return nil, cerrors.NewErrorWithUserMessage(ercodes.AtmCannotDispense, nil, "Банкомат не может выдать эту сумму имеющимися купюрами")
+	}
+
+	withdrawn := make([]AtmBanknotesData, 0, len(banknotes))
+	for _, entry := range banknotes {
+		withdrawn = append(withdrawn, AtmBanknotesData{DenominationCents: entry.DenominationCents, Count: -entry.Count})
+	}
+	if err = s.atmStorage.UpdateAtmCassettes(ctx, atmData.Id, withdrawn); err != nil {
+		return nil, err
+	}
+	if err = s.changeATMState(ctx, atmData, -amountCents); err != nil {
+		return nil, err
+	}
+	return banknotes, nil
+}
+
+func banknotesAmount(banknotes []AtmBanknotesData) (int64, error) {
+	if len(banknotes) == 0 {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.InvalidBanknotes, nil, "Не указаны внесённые купюры")
+	}
+
+	var amountCents int64
+	for _, entry := range banknotes {
+		if !slices.Contains(AtmBanknoteDenominations, entry.DenominationCents) {
+			return 0, cerrors.NewErrorWithUserMessage(ercodes.InvalidBanknotes, nil, "Неподдерживаемый номинал купюры")
+		}
+		if entry.Count <= 0 || entry.Count > (math.MaxInt64-amountCents)/entry.DenominationCents {
+			return 0, cerrors.NewErrorWithUserMessage(ercodes.InvalidBanknotes, nil, "Неверное количество купюр")
+		}
+		amountCents += entry.DenominationCents * entry.Count
+	}
+	return amountCents, nil
+}
+
+func dispenseBanknotes(cassettes []AtmBanknotesData, amountCents int64) ([]AtmBanknotesData, bool) {
+	available := make([]AtmBanknotesData, 0, len(cassettes))
+	for _, cassette := range cassettes {
+		if cassette.DenominationCents > 0 && cassette.Count > 0 {
+			available = append(available, cassette)
+		}
+	}
+	slices.SortFunc(available, func(a, b AtmBanknotesData) int {
+		return cmp.Compare(b.DenominationCents, a.DenominationCents)
+	})
+
+	counts := make([]int64, len(available))
+	failed := make(map[[2]int64]struct{})
+
+	var search func(i int, restCents int64) bool
+	search = func(i int, restCents int64) bool {
+		if restCents == 0 {
+			return true
+		}
+		if i == len(available) {
+			return false
+		}
+		key := [2]int64{int64(i), restCents}
+		if _, ok := failed[key]; ok {
+			return false
+		}
+
+		denominationCents := available[i].DenominationCents
+		for count := min(available[i].Count, restCents/denominationCents); count >= 0; count-- {
+			counts[i] = count
+			if search(i+1, restCents-count*denominationCents) {
+				return true
+			}
+		}
+		counts[i] = 0
+		failed[key] = struct{}{}
+		return false
+	}
+
+	if amountCents <= 0 || !search(0, amountCents) {
+		return nil, false
+	}
+
+	var banknotes []AtmBanknotesData
+	for i, count := range counts {
+		if count > 0 {
+			banknotes = append(banknotes, AtmBanknotesData{DenominationCents: available[i].DenominationCents, Count: count})
+		}
+	}
+	return banknotes, true
+}
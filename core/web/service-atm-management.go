@@ -0,0 +1,111 @@
+package web
+
+import (
+	"context"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	AtmStatusActive   = "ACTIVE"
+	AtmStatusDisabled = "DISABLED"
+
+	atmLoginPrefix      = "atm"
+	atmLoginCharset     = "abcdefghijklmnopqrstuvwxyz0123456789"
+	atmLoginLength      = 8
+	atmPasswordCharset  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	atmPasswordLength   = 24
+	atmPasswordHashCost = 10
+	atmRegisterAttempts = 3
+)
+
+func (s *Service) RegisterAtm(ctx context.Context) (_ AtmData, _ string, err error) {
+	audit := s.startAudit(ctx, AuditActionAtmRegister, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	password, passwordHash, err := s.generateAtmPassword(ctx)
+	if err != nil {
+		return AtmData{}, "", err
+	}
+
+	for i := 0; i < atmRegisterAttempts; i++ {
+		var login, accountNumber string
+		login, err = s.randomGenerator.GenerateString(ctx, atmLoginCharset, atmLoginLength)
+		if err != nil {
+			return AtmData{}, "", err
+		}
+		accountNumber, err = s.generateAccountNumber(ctx)
+		if err != nil {
+			return AtmData{}, "", err
+		}
+
+		var atmData AtmData
+		err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+			var err error
+			if atmData, err = s.atmStorage.CreateAtm(ctx, atmLoginPrefix+login, passwordHash, accountNumber); err != nil {
+				return err
+			}
+			audit.entry.EntityId = atmData.Id
+			audit.after = auditValues{"login": atmData.Login, "accountId": atmData.AccountId, "status": atmData.Status}
+			return nil
+		})
+		if err == nil {
+			return atmData, password, nil
+		}
+		if !hasErrorCode(err, ercodes.AtmLoginExists, ercodes.AccountNumberExists) {
+			return AtmData{}, "", err
+		}
+	}
+	return AtmData{}, "", err
+}
+
+func (s *Service) GetAtms(ctx context.Context, limit, offset int64) ([]AtmData, int64, error) {
+	return s.atmStorage.GetAtms(ctx, limit, offset)
+}
+
+func (s *Service) RotateAtmPassword(ctx context.Context, atmId int64) (_ string, err error) {
+	audit := s.startAudit(ctx, AuditActionAtmRotatePassword, atmId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	if _, err = s.atmStorage.GetAtmDataById(ctx, atmId); err != nil {
+		return "", err
+	}
+
+	password, passwordHash, err := s.generateAtmPassword(ctx)
+	if err != nil {
+		return "", err
+	}
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		return s.atmStorage.UpdateAtmPassword(ctx, atmId, passwordHash)
+	})
+	if err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+func (s *Service) SetAtmStatus(ctx context.Context, atmId int64, status string) (err error) {
+	audit := s.startAudit(ctx, AuditActionAtmStatus, atmId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	atmData, err := s.atmStorage.GetAtmDataById(ctx, atmId)
+	if err != nil {
+		return err
+	}
+	audit.before = auditValues{"status": atmData.Status}
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		audit.after = auditValues{"status": status}
+		return s.atmStorage.UpdateAtmStatus(ctx, atmId, status)
+	})
+}
+
+func (s *Service) generateAtmPassword(ctx context.Context) (string, []byte, error) {
+	password, err := s.randomGenerator.GenerateString(ctx, atmPasswordCharset, atmPasswordLength)
+	if err != nil {
+		return "", nil, err
+	}
+	passwordHash, err := s.passwordHasher.HashPassword(ctx, []byte(password), atmPasswordHashCost)
+	if err != nil {
+		return "", nil, err
+	}
+	return password, passwordHash, nil
+}
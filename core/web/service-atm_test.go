@@ -0,0 +1,363 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	testAtmId          = 1
+	testAtmAccountId   = 10
+	testUserAccountId  = 20
+	testUserId         = 7
+	testCardNumber     = "2200000000000000"
+	testBanknoteCents  = 100000
+	testAtmInitialCash = 10 * testBanknoteCents
+)
+
+var errStepFailed = errors.New("step failed")
+
+type (
+	fakeTxKey struct{}
+	fakeTx    struct {
+		screeningHits []ScreeningHitData
+	}
+
+	atmState struct {
+		cashCents      int64
+		cassettes      map[int64]int64
+		balances       map[int64]int64
+		cashLog        []int64
+		transactions   int
+		cardOperations int
+		reviewHolds    int
+		audit          []AuditEntryData
+	}
+
+	atmLedger struct {
+		atmState
+		failOn           string
+		outsideTx        []string
+		sanctionedNumber string
+		screeningHits    []ScreeningHitData
+	}
+
+	fakeTransactionManager struct {
+		l *atmLedger
+	}
+	fakeAtmStorage struct {
+		AtmStorage
+		l *atmLedger
+	}
+	fakeAccountStorage struct {
+		AccountStorage
+		l *atmLedger
+	}
+	fakeTransactionStorage struct {
+		TransactionStorage
+		l *atmLedger
+	}
+	fakeCardStorage struct {
+		CardStorage
+		l *atmLedger
+	}
+	fakeFraudStorage struct {
+		FraudStorage
+		l *atmLedger
+	}
+	fakeScreeningStorage struct {
+		ScreeningStorage
+		l *atmLedger
+	}
+	fakeScreener struct {
+		l *atmLedger
+	}
+	fakePasswordHasher struct {
+		PasswordHasher
+	}
+	fakeAuditStorage struct {
+		AuditStorage
+		l *atmLedger
+	}
+)
+
+func newAtmLedger() *atmLedger {
+	return &atmLedger{atmState: atmState{
+		cashCents: testAtmInitialCash,
+		cassettes: map[int64]int64{testBanknoteCents: testAtmInitialCash / testBanknoteCents},
+		balances:  map[int64]int64{testAtmAccountId: testAtmInitialCash, testUserAccountId: 5 * testBanknoteCents},
+	}}
+}
+
+func (st atmState) clone() atmState {
+	st.cassettes = maps.Clone(st.cassettes)
+	st.balances = maps.Clone(st.balances)
+	st.cashLog = slices.Clone(st.cashLog)
+	st.audit = slices.Clone(st.audit)
+	return st
+}
+
+func (l *atmLedger) step(ctx context.Context, name string) error {
+	if ctx.Value(fakeTxKey{}) == nil {
+		l.outsideTx = append(l.outsideTx, name)
+	}
+	if name == l.failOn {
+		return errStepFailed
+	}
+	return nil
+}
+
+func (m fakeTransactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(fakeTxKey{}) != nil {
+		return fn(ctx)
+	}
+	return m.WithinNewTransaction(ctx, fn)
+}
+
+func (m fakeTransactionManager) WithinNewTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := m.l.atmState.clone()
+	tx := &fakeTx{}
+	if err := fn(context.WithValue(ctx, fakeTxKey{}, tx)); err != nil {
+		m.l.atmState = snapshot
+		return err
+	}
+	m.l.screeningHits = append(m.l.screeningHits, tx.screeningHits...)
+	return nil
+}
+
+func (s fakeAtmStorage) GetAtmDataById(_ context.Context, atmId int64) (AtmData, error) {
+	return AtmData{Id: atmId, AccountId: testAtmAccountId, Login: "atm", Status: AtmStatusActive, CashCents: s.l.cashCents}, nil
+}
+
+func (s fakeAtmStorage) GetAtmCassettes(ctx context.Context, _ int64) ([]AtmBanknotesData, error) {
+	if err := s.l.step(ctx, "GetAtmCassettes"); err != nil {
+		return nil, err
+	}
+	cassettes := make([]AtmBanknotesData, 0, len(s.l.cassettes))
+	for denominationCents, count := range s.l.cassettes {
+		cassettes = append(cassettes, AtmBanknotesData{DenominationCents: denominationCents, Count: count})
+	}
+	return cassettes, nil
+}
+
+func (s fakeAtmStorage) UpdateAtmCassettes(ctx context.Context, _ int64, banknotes []AtmBanknotesData) error {
+	if err := s.l.step(ctx, "UpdateAtmCassettes"); err != nil {
+		return err
+	}
+	for _, entry := range banknotes {
+		s.l.cassettes[entry.DenominationCents] += entry.Count
+	}
+	return nil
+}
+
+func (s fakeAtmStorage) UpdateAtmCash(ctx context.Context, amountCents, _ int64) (int64, error) {
+	if err := s.l.step(ctx, "UpdateAtmCash"); err != nil {
+		return 0, err
+	}
+	s.l.cashCents += amountCents
+	return s.l.cashCents, nil
+}
+
+func (s fakeAtmStorage) LogCashOperation(ctx context.Context, _, amountCents, _ int64) error {
+	if err := s.l.step(ctx, "LogCashOperation"); err != nil {
+		return err
+	}
+	s.l.cashLog = append(s.l.cashLog, amountCents)
+	return nil
+}
+
+func (s fakeAccountStorage) GetAccountDataById(_ context.Context, accountId int64) (UserAccountData, error) {
+	data := UserAccountData{
+		Id:              accountId,
+		Number:          strconv.FormatInt(accountId, 10),
+		Status:          "ACTIVE",
+		BalanceCents:    s.l.balances[accountId],
+		AvailableCents:  s.l.balances[accountId],
+		OwnerIdentified: true,
+	}
+	if accountId == testUserAccountId {
+		data.UserId = testUserId
+	}
+	return data, nil
+}
+
+func (s fakeAccountStorage) UpdateAtmAccount(ctx context.Context, amountCents, accountId int64) error {
+	if err := s.l.step(ctx, "UpdateAtmAccount"); err != nil {
+		return err
+	}
+	s.l.balances[accountId] += amountCents
+	return nil
+}
+
+func (s fakeTransactionStorage) CreateTransaction(ctx context.Context, senderId, receiverId, amountCents int64, _ string) (int64, error) {
+	if err := s.l.step(ctx, "CreateTransaction"); err != nil {
+		return 0, err
+	}
+	s.l.balances[senderId] -= amountCents
+	s.l.balances[receiverId] += amountCents
+	s.l.transactions++
+	return int64(s.l.transactions), nil
+}
+
+func (s fakeCardStorage) GetCardByNumber(_ context.Context, number string) (CardData, error) {
+	return CardData{
+		Id:        1,
+		AccountId: testUserAccountId,
+		UserId:    testUserId,
+		Number:    number,
+		Status:    CardStatusActive,
+		CvvHash:   []byte("cvv"),
+		ExpiresAt: time.Now().AddDate(1, 0, 0),
+	}, nil
+}
+
+func (s fakeCardStorage) CreateCardOperation(ctx context.Context, _ CardOperationData) (int64, error) {
+	if err := s.l.step(ctx, "CreateCardOperation"); err != nil {
+		return 0, err
+	}
+	s.l.cardOperations++
+	return int64(s.l.cardOperations), nil
+}
+
+func (s fakeFraudStorage) GetTransferStats(context.Context, int64, int64, time.Time, time.Time, time.Time) (TransferStatsData, error) {
+	return TransferStatsData{RecentCount: int64(s.l.transactions), HasTransfersTo: true}, nil
+}
+
+func (s fakeFraudStorage) HoldTransactionForReview(ctx context.Context, _ int64, _ int, _ []string) error {
+	if err := s.l.step(ctx, "HoldTransactionForReview"); err != nil {
+		return err
+	}
+	s.l.reviewHolds++
+	return nil
+}
+
+func (fakeScreeningStorage) GetAccountOwnerNames(context.Context, int64) ([]string, error) {
+	return nil, nil
+}
+
+func (s fakeScreeningStorage) CreateScreeningHits(ctx context.Context, hits []ScreeningHitData) error {
+	if err := s.l.step(ctx, "CreateScreeningHits"); err != nil {
+		return err
+	}
+	tx := ctx.Value(fakeTxKey{}).(*fakeTx)
+	tx.screeningHits = append(tx.screeningHits, hits...)
+	return nil
+}
+
+func (s fakeScreener) Screen(_ context.Context, subject ScreeningSubject) []ScreeningMatch {
+	if s.l.sanctionedNumber == "" || subject.AccountNumber != s.l.sanctionedNumber {
+		return nil
+	}
+	return []ScreeningMatch{{ListType: "ACCOUNT", Entry: subject.AccountNumber}}
+}
+
+func (fakePasswordHasher) CompareHashAndPassword(context.Context, string, []byte) error {
+	return nil
+}
+
+func (s fakeAuditStorage) AppendAuditEntry(ctx context.Context, entry AuditEntryData) error {
+	if entry.Outcome == AuditOutcomeSuccess && ctx.Value(fakeTxKey{}) == nil {
+		s.l.outsideTx = append(s.l.outsideTx, "AppendAuditEntry")
+	}
+	if s.l.failOn == "AppendAuditEntry" {
+		return errStepFailed
+	}
+	s.l.audit = append(s.l.audit, entry)
+	return nil
+}
+
+func newAtmTestService(l *atmLedger) Service {
+	return NewService(fakeAccountStorage{l: l}, fakePasswordHasher{}, fakeAtmStorage{l: l}, fakeTransactionStorage{l: l}, nil, nil, nil, nil,
+		fakeCardStorage{l: l}, fakeTransactionManager{l: l}, nil, nil, nil, nil, nil, fakeFraudStorage{l: l}, fakeScreeningStorage{l: l},
+		fakeScreener{l: l}, fakeAuditStorage{l: l}, nil, StepUpPolicy{}, BeneficiaryPolicy{})
+}
+
+func TestATMOperationsRollback(t *testing.T) {
+	banknotes := []AtmBanknotesData{{DenominationCents: testBanknoteCents, Count: 2}}
+
+	tests := []struct {
+		name  string
+		steps []string
+		run   func(ctx context.Context, s *Service) error
+	}{
+		{
+			name:  "ATMWithdrawal",
+			steps: []string{"GetAtmCassettes", "UpdateAtmCassettes", "UpdateAtmCash", "UpdateAtmAccount", "LogCashOperation", "AppendAuditEntry"},
+			run: func(ctx context.Context, s *Service) error {
+				_, err := s.ATMWithdrawal(ctx, testAtmId, 2*testBanknoteCents)
+				return err
+			},
+		},
+		{
+			name:  "ATMUserSupplement",
+			steps: []string{"UpdateAtmCassettes", "UpdateAtmCash", "UpdateAtmAccount", "CreateTransaction", "LogCashOperation", "AppendAuditEntry"},
+			run: func(ctx context.Context, s *Service) error {
+				return s.ATMUserSupplement(ctx, testAtmId, banknotes, testCardNumber, "0000")
+			},
+		},
+		{
+			name:  "ATMUserWithdrawal",
+			steps: []string{"CreateTransaction", "CreateCardOperation", "GetAtmCassettes", "UpdateAtmCassettes", "UpdateAtmCash", "UpdateAtmAccount", "LogCashOperation", "AppendAuditEntry"},
+			run: func(ctx context.Context, s *Service) error {
+				_, err := s.ATMUserWithdrawal(ctx, testAtmId, 2*testBanknoteCents, testCardNumber, "0000")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/success", func(t *testing.T) {
+			l := newAtmLedger()
+			s := newAtmTestService(l)
+			initial := l.atmState.clone()
+
+			if err := tt.run(context.Background(), &s); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if reflect.DeepEqual(l.atmState, initial) {
+				t.Fatal("operation left no changes")
+			}
+			if len(l.cashLog) != 1 {
+				t.Fatalf("cash log has %d entries, want 1", len(l.cashLog))
+			}
+			if len(l.outsideTx) != 0 {
+				t.Fatalf("storage calls outside transaction: %v", l.outsideTx)
+			}
+			if len(l.audit) != 1 || l.audit[0].Outcome != AuditOutcomeSuccess {
+				t.Fatalf("audit entries = %+v, want one successful entry", l.audit)
+			}
+		})
+
+		for _, step := range tt.steps {
+			t.Run(tt.name+"/fail "+step, func(t *testing.T) {
+				l := newAtmLedger()
+				l.failOn = step
+				s := newAtmTestService(l)
+				initial := l.atmState.clone()
+
+				err := tt.run(context.Background(), &s)
+				if !errors.Is(err, errStepFailed) {
+					t.Fatalf("error = %v, want %v", err, errStepFailed)
+				}
+				if len(l.outsideTx) != 0 {
+					t.Fatalf("storage calls outside transaction: %v", l.outsideTx)
+				}
+				if step != "AppendAuditEntry" {
+					if len(l.audit) != 1 || l.audit[0].Outcome != AuditOutcomeFailure {
+						t.Fatalf("audit entries = %+v, want one failed entry", l.audit)
+					}
+					l.audit = nil
+				}
+				if !reflect.DeepEqual(l.atmState, initial) {
+					t.Fatalf("state after rollback = %+v, want %+v", l.atmState, initial)
+				}
+			})
+		}
+	}
+}
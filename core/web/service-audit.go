@@ -0,0 +1,210 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+const (
+	AuditActorUser   = "USER"
+	AuditActorAtm    = "ATM"
+	AuditActorSystem = "SYSTEM"
+
+	AuditOutcomeSuccess = "SUCCESS"
+	AuditOutcomeFailure = "FAILURE"
+
+	AuditActionAccountOpen              = "ACCOUNT_OPEN"
+	AuditActionAccountBlock             = "ACCOUNT_BLOCK"
+	AuditActionUserIdentification       = "USER_IDENTIFICATION"
+	AuditActionTransfer                 = "TRANSFER"
+	AuditActionBatchTransfer            = "BATCH_TRANSFER"
+	AuditActionStepUpCreate             = "STEP_UP_CREATE"
+	AuditActionStepUpConfirm            = "STEP_UP_CONFIRM"
+	AuditActionAliasVerify              = "ALIAS_VERIFY"
+	AuditActionAliasBind                = "ALIAS_BIND"
+	AuditActionAliasDelete              = "ALIAS_DELETE"
+	AuditActionBeneficiaryAdd           = "BENEFICIARY_ADD"
+	AuditActionBeneficiaryRename        = "BENEFICIARY_RENAME"
+	AuditActionBeneficiaryDelete        = "BENEFICIARY_DELETE"
+	AuditActionCardIssue                = "CARD_ISSUE"
+	AuditActionCardBlock                = "CARD_BLOCK"
+	AuditActionCardUnblock              = "CARD_UNBLOCK"
+	AuditActionCardLimits               = "CARD_LIMITS"
+	AuditActionCardPayment              = "CARD_PAYMENT"
+	AuditActionCardHoldCreate           = "CARD_HOLD_CREATE"
+	AuditActionCardHoldCapture          = "CARD_HOLD_CAPTURE"
+	AuditActionCardHoldRelease          = "CARD_HOLD_RELEASE"
+	AuditActionPaymentRequestCreate     = "PAYMENT_REQUEST_CREATE"
+	AuditActionPaymentRequestAccept     = "PAYMENT_REQUEST_ACCEPT"
+	AuditActionPaymentRequestDecline    = "PAYMENT_REQUEST_DECLINE"
+	AuditActionPaymentFileImport        = "PAYMENT_FILE_IMPORT"
+	AuditActionPaymentFileConfirm       = "PAYMENT_FILE_CONFIRM"
+	AuditActionPaymentFileProcess       = "PAYMENT_FILE_PROCESS"
+	AuditActionTransactionReviewApprove = "TRANSACTION_REVIEW_APPROVE"
+	AuditActionTransactionReviewReject  = "TRANSACTION_REVIEW_REJECT"
+	AuditActionTokenRevoke              = "TOKEN_REVOKE"
+	AuditActionAtmLogin                 = "ATM_LOGIN"
+	AuditActionAtmSupplement            = "ATM_SUPPLEMENT"
+	AuditActionAtmWithdrawal            = "ATM_WITHDRAWAL"
+	AuditActionAtmUserSupplement        = "ATM_USER_SUPPLEMENT"
+	AuditActionAtmUserWithdrawal        = "ATM_USER_WITHDRAWAL"
+	AuditActionAtmRegister              = "ATM_REGISTER"
+	AuditActionAtmRotatePassword        = "ATM_ROTATE_PASSWORD"
+	AuditActionAtmStatus                = "ATM_STATUS"
+
+	auditVerifyBatchSize = 1000
+)
+
+type (
+	auditCtxKey struct{}
+
+	auditValues map[string]any
+
+	auditRecord struct {
+		entry   AuditEntryData
+		before  any
+		after   any
+		written bool
+	}
+)
+
+func WithAuditRequest(ctx context.Context, request AuditRequestData) context.Context {
+	return context.WithValue(ctx, auditCtxKey{}, request)
+}
+
+func WithAuditActor(ctx context.Context, actorType, actorId string) context.Context {
+	request, _ := ctx.Value(auditCtxKey{}).(AuditRequestData)
+	request.ActorType = actorType
+	request.ActorId = actorId
+	return context.WithValue(ctx, auditCtxKey{}, request)
+}
+
+func (e *AuditEntryData) ComputeHash() string {
+	payload, _ := json.Marshal(struct {
+		ActorType string          `json:"actorType"`
+		ActorId   string          `json:"actorId"`
+		RequestId string          `json:"requestId"`
+		Ip        string          `json:"ip"`
+		UserAgent string          `json:"userAgent"`
+		Action    string          `json:"action"`
+		EntityId  int64           `json:"entityId"`
+		Before    json.RawMessage `json:"before"`
+		After     json.RawMessage `json:"after"`
+		Outcome   string          `json:"outcome"`
+		Error     string          `json:"error"`
+		CreatedAt string          `json:"createdAt"`
+		PrevHash  string          `json:"prevHash"`
+	}{e.ActorType, e.ActorId, e.RequestId, e.Ip, e.UserAgent, e.Action, e.EntityId, e.Before, e.After, e.Outcome, e.Error,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano), e.PrevHash})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Service) GetAuditEntries(ctx context.Context, filter AuditFilterData, limit, offset int64) ([]AuditEntryData, int64, error) {
+	return s.auditStorage.GetAuditEntries(ctx, filter, limit, offset)
+}
+
+func (s *Service) VerifyAuditLog(ctx context.Context) (AuditVerificationData, error) {
+	var (
+		result   AuditVerificationData
+		prevHash string
+		afterId  int64
+	)
+	for {
+		entries, err := s.auditStorage.GetAuditEntriesAfter(ctx, afterId, auditVerifyBatchSize)
+		if err != nil {
+			return AuditVerificationData{}, err
+		}
+		for i := range entries {
+			if entries[i].PrevHash != prevHash || entries[i].ComputeHash() != entries[i].Hash {
+				result.BrokenId = entries[i].Id
+				return result, nil
+			}
+			prevHash = entries[i].Hash
+			result.Checked++
+		}
+		if len(entries) < auditVerifyBatchSize {
+			result.Valid = true
+			return result, nil
+		}
+		afterId = entries[len(entries)-1].Id
+	}
+}
+
+func (s *Service) startAudit(ctx context.Context, action string, entityId int64) *auditRecord {
+	request, _ := ctx.Value(auditCtxKey{}).(AuditRequestData)
+	if request.ActorType == "" {
+		request.ActorType = AuditActorSystem
+	}
+	return &auditRecord{entry: AuditEntryData{
+		ActorType: request.ActorType,
+		ActorId:   request.ActorId,
+		RequestId: request.RequestId,
+		Ip:        request.Ip,
+		UserAgent: request.UserAgent,
+		Action:    action,
+		EntityId:  entityId,
+	}}
+}
+
+func (s *Service) withinAuditedTransaction(ctx context.Context, record *auditRecord, fn func(ctx context.Context) error) error {
+	err := s.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil || record.written {
+			return err
+		}
+		return s.appendAudit(ctx, record, nil)
+	})
+	if err != nil {
+		record.written = false
+	}
+	return err
+}
+
+func (s *Service) finishAudit(ctx context.Context, record *auditRecord, err error) error {
+	if record.written {
+		return err
+	}
+	if appendErr := s.appendAudit(context.WithoutCancel(ctx), record, err); appendErr != nil {
+		if err != nil {
+			log.Println(appendErr)
+			return err
+		}
+		return appendErr
+	}
+	return err
+}
+
+func (s *Service) appendAudit(ctx context.Context, record *auditRecord, err error) error {
+	entry := record.entry
+	entry.Outcome = AuditOutcomeSuccess
+	if err != nil {
+		entry.Outcome = AuditOutcomeFailure
+		entry.Error = err.Error()
+	}
+
+	var marshalErr error
+	if entry.Before, marshalErr = marshalAuditValue(record.before); marshalErr != nil {
+		log.Println(marshalErr)
+	}
+	if entry.After, marshalErr = marshalAuditValue(record.after); marshalErr != nil {
+		log.Println(marshalErr)
+	}
+
+	if err = s.auditStorage.AppendAuditEntry(ctx, entry); err != nil {
+		return err
+	}
+	record.written = true
+	return nil
+}
+
+func marshalAuditValue(value any) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
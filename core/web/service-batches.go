@@ -0,0 +1,231 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	MaxBatchTransferItems = 1000
+)
+
+var batchItemErrorCodes = []cerrors.Code{
+	ercodes.SameAccount,
+	ercodes.BlockedAccount,
+	ercodes.NotEnoughMoney,
+	ercodes.AccountNotFound,
+	ercodes.IdentificationRequired,
+	ercodes.ReceiverBalanceLimit,
+	ercodes.BeneficiaryCoolingOff,
+	ercodes.TransferDenied,
+}
+
+func (s *Service) MakeBatchTransaction(ctx context.Context, senderId, userId int64, items []BatchTransferItem, confirmationToken string) (_ BatchTransferData, err error) {
+	audit := s.startAudit(ctx, AuditActionBatchTransfer, senderId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	senderAccountData, err := s.accountStorage.GetAccountDataById(ctx, senderId)
+	if err != nil {
+		return BatchTransferData{}, err
+	}
+	if senderAccountData.UserId != userId {
+		return BatchTransferData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+	if senderAccountData.Status == "BLOCKED" {
+		return BatchTransferData{}, cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт отправителя заблокирован")
+	}
+	result := BatchTransferData{Items: make([]BatchTransferResult, len(items))}
+	receiverIds := make([]int64, 0, len(items))
+	for i := range items {
+		result.Items[i] = BatchTransferResult{Index: i, AmountCents: items[i].AmountCents}
+
+		receiverId, err := s.resolveBatchReceiver(ctx, items[i])
+		if err != nil {
+			if !hasErrorCode(err, ercodes.AliasNotFound, ercodes.InvalidAlias, ercodes.AccountNotFound, ercodes.InvalidAccountNumber) {
+				return BatchTransferData{}, err
+			}
+			result.Items[i].Error = errorUserMessage(err)
+			continue
+		}
+		items[i].ReceiverId = receiverId
+		result.Items[i].ReceiverId = receiverId
+		receiverIds = append(receiverIds, receiverId)
+
+		if result.TotalCents > math.MaxInt64-items[i].AmountCents {
+			return BatchTransferData{}, cerrors.NewErrorWithUserMessage(ercodes.NotEnoughMoney, nil, "Недостаточно средств")
+		}
+		result.TotalCents += items[i].AmountCents
+	}
+
+	receiversData, err := s.accountStorage.GetAccountsDataByIds(ctx, receiverIds)
+	if err != nil {
+		return BatchTransferData{}, err
+	}
+
+	failed, stepUpRequired := false, false
+	var largestCents int64
+	incomingCents := make(map[int64]int64, len(receiversData))
+	for i := range result.Items {
+		item := &result.Items[i]
+		if item.Error == "" {
+			receiverData, ok := receiversData[item.ReceiverId]
+			incomingCents[item.ReceiverId] += item.AmountCents
+			switch {
+			case !ok:
+				item.Error = "Счёт получателя не найден"
+			case item.ReceiverId == senderId:
+				item.Error = "Нельзя перевести деньги на тот же счёт"
+			case receiverData.Status == "BLOCKED":
+				item.Error = "Счёт получателя заблокирован"
+			case checkKycIncoming(receiverData, incomingCents[item.ReceiverId]) != nil:
+				item.Error = "Превышен лимит остатка на счёте получателя без идентификации"
+			}
+		}
+		if item.Error == "" && !stepUpRequired {
+			if stepUpRequired, err = s.isStepUpRequired(ctx, senderId, item.ReceiverId, item.AmountCents, userId); err != nil {
+				return BatchTransferData{}, err
+			}
+		}
+		largestCents = max(largestCents, item.AmountCents)
+		failed = failed || item.Error != ""
+	}
+	if failed {
+		return result, nil
+	}
+
+	if senderAccountData.AvailableCents < result.TotalCents {
+		return BatchTransferData{}, cerrors.NewErrorWithUserMessage(ercodes.NotEnoughMoney, nil, "Недостаточно средств")
+	}
+	if err = s.checkKycOutgoing(ctx, senderAccountData, largestCents, result.TotalCents); err != nil {
+		return BatchTransferData{}, err
+	}
+	audit.before = auditValues{"balanceCents": senderAccountData.BalanceCents, "stepUpRequired": stepUpRequired}
+
+	failedIndex := -1
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		if stepUpRequired {
+			err := s.confirmOperationStepUp(ctx, StepUpChallengeData{
+				Operation:     StepUpOperationBatch,
+				UserId:        userId,
+				SenderId:      senderId,
+				AmountCents:   result.TotalCents,
+				OperationHash: batchOperationHash(items),
+			}, confirmationToken)
+			if err != nil {
+				return err
+			}
+		}
+
+		batchId, err := s.transactionStorage.CreateTransactionBatch(ctx, senderId, len(items), result.TotalCents)
+		if err != nil {
+			return err
+		}
+
+		for i := range items {
+			transactionId, err := s.makeTransaction(ctx, senderId, items[i].ReceiverId, items[i].AmountCents, userId, items[i].Description, true, true)
+			if err != nil {
+				if hasErrorCode(err, batchItemErrorCodes...) {
+					failedIndex = i
+				}
+				return err
+			}
+			if err = s.transactionStorage.LinkTransactionToBatch(ctx, batchId, transactionId); err != nil {
+				return err
+			}
+			result.Items[i].TransactionId = transactionId
+		}
+
+		result.BatchId = batchId
+		audit.after = auditValues{"balanceCents": senderAccountData.BalanceCents - result.TotalCents, "batchId": result.BatchId, "itemsCount": len(items), "totalCents": result.TotalCents}
+		return nil
+	})
+	if err != nil {
+		if failedIndex < 0 {
+			return BatchTransferData{}, err
+		}
+		result.BatchId = 0
+		for i := range result.Items {
+			result.Items[i].TransactionId = 0
+		}
+		result.Items[failedIndex].Error = errorUserMessage(err)
+		return result, nil
+	}
+	return result, nil
+}
+
+func (s *Service) CreateBatchStepUpChallenge(ctx context.Context, senderId, userId int64, items []BatchTransferItem) (_ StepUpChallengeData, err error) {
+	audit := s.startAudit(ctx, AuditActionStepUpCreate, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	senderAccountData, err := s.accountStorage.GetAccountDataById(ctx, senderId)
+	if err != nil {
+		return StepUpChallengeData{}, err
+	}
+	if senderAccountData.UserId != userId {
+		return StepUpChallengeData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+
+	var totalCents int64
+	for i := range items {
+		if items[i].ReceiverId, err = s.resolveBatchReceiver(ctx, items[i]); err != nil {
+			return StepUpChallengeData{}, err
+		}
+		if totalCents > math.MaxInt64-items[i].AmountCents {
+			return StepUpChallengeData{}, cerrors.NewErrorWithUserMessage(ercodes.NotEnoughMoney, nil, "Недостаточно средств")
+		}
+		totalCents += items[i].AmountCents
+	}
+
+	return s.createStepUpChallenge(ctx, audit, StepUpChallengeData{
+		Operation:     StepUpOperationBatch,
+		UserId:        userId,
+		SenderId:      senderId,
+		AmountCents:   totalCents,
+		OperationHash: batchOperationHash(items),
+	})
+}
+
+func (s *Service) GetTransactionBatch(ctx context.Context, batchId, userId int64) (TransactionBatchData, error) {
+	batchData, err := s.transactionStorage.GetTransactionBatch(ctx, batchId)
+	if err != nil {
+		return TransactionBatchData{}, err
+	}
+
+	senderAccountData, err := s.accountStorage.GetAccountDataById(ctx, batchData.SenderId)
+	if err != nil {
+		return TransactionBatchData{}, err
+	}
+	if senderAccountData.UserId != userId {
+		return TransactionBatchData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+
+	return batchData, nil
+}
+
+func (s *Service) resolveBatchReceiver(ctx context.Context, item BatchTransferItem) (int64, error) {
+	switch {
+	case item.ReceiverAlias != "":
+		aliasData, err := s.getPaymentAlias(ctx, item.ReceiverAlias)
+		if err != nil {
+			return 0, err
+		}
+		return aliasData.AccountId, nil
+	case item.ReceiverNumber != "":
+		return s.ResolveAccountNumber(ctx, item.ReceiverNumber)
+	default:
+		return item.ReceiverId, nil
+	}
+}
+
+func batchOperationHash(items []BatchTransferItem) string {
+	hash := sha256.New()
+	for _, item := range items {
+		_, _ = fmt.Fprintf(hash, "%d\t%d\t%q\n", item.ReceiverId, item.AmountCents, item.Description)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
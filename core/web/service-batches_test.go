@@ -0,0 +1,98 @@
+package web
+
+import (
+	"context"
+	"testing"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s fakeAccountStorage) GetAccountsDataByIds(ctx context.Context, accountIds []int64) (map[int64]UserAccountData, error) {
+	accountsData := make(map[int64]UserAccountData, len(accountIds))
+	for _, accountId := range accountIds {
+		accountsData[accountId], _ = s.GetAccountDataById(ctx, accountId)
+	}
+	return accountsData, nil
+}
+
+func (s fakeTransactionStorage) CreateTransactionBatch(ctx context.Context, _ int64, _ int, _ int64) (int64, error) {
+	if err := s.l.step(ctx, "CreateTransactionBatch"); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (s fakeTransactionStorage) LinkTransactionToBatch(ctx context.Context, _, _ int64) error {
+	return s.l.step(ctx, "LinkTransactionToBatch")
+}
+
+func TestMakeBatchTransactionSkipsVelocity(t *testing.T) {
+	const itemAmountCents = 1000
+
+	tests := []struct {
+		name       string
+		itemsCount int
+	}{
+		{name: "below review count", itemsCount: fraudVelocityReviewCount - 1},
+		{name: "above deny count", itemsCount: fraudVelocityDenyCount + 5},
+		{name: "payroll", itemsCount: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newAtmLedger()
+			s := newAtmTestService(l)
+
+			items := make([]BatchTransferItem, tt.itemsCount)
+			for i := range items {
+				items[i] = BatchTransferItem{ReceiverId: testAtmAccountId, AmountCents: itemAmountCents}
+			}
+
+			result, err := s.MakeBatchTransaction(context.Background(), testUserAccountId, testUserId, items, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, item := range result.Items {
+				if item.Error != "" {
+					t.Fatalf("item %d failed: %s", item.Index, item.Error)
+				}
+			}
+			if result.BatchId == 0 {
+				t.Fatal("batch was not created")
+			}
+			if l.transactions != tt.itemsCount {
+				t.Fatalf("transactions = %d, want %d", l.transactions, tt.itemsCount)
+			}
+			if l.reviewHolds != 0 {
+				t.Fatalf("review holds = %d, want 0", l.reviewHolds)
+			}
+			if want := int64(5*testBanknoteCents - tt.itemsCount*itemAmountCents); l.balances[testUserAccountId] != want {
+				t.Fatalf("sender balance = %d, want %d", l.balances[testUserAccountId], want)
+			}
+		})
+	}
+}
+
+func TestMakeBatchTransactionRequiresBatchStepUp(t *testing.T) {
+	const thresholdCents = 1000
+
+	l := newAtmLedger()
+	s := newAtmTestService(l)
+	s.stepUpPolicy = StepUpPolicy{ThresholdCents: thresholdCents}
+
+	items := make([]BatchTransferItem, fraudVelocityDenyCount+5)
+	for i := range items {
+		items[i] = BatchTransferItem{ReceiverId: testAtmAccountId, AmountCents: thresholdCents}
+	}
+	items[len(items)-1].AmountCents = thresholdCents + 1
+
+	result, err := s.MakeBatchTransaction(context.Background(), testUserAccountId, testUserId, items, "")
+	if !hasErrorCode(err, ercodes.StepUpRequired) {
+		t.Fatalf("err = %v, want StepUpRequired", err)
+	}
+	if result.BatchId != 0 || l.transactions != 0 {
+		t.Fatalf("batch %d executed %d transactions, want none", result.BatchId, l.transactions)
+	}
+	if l.balances[testUserAccountId] != 5*testBanknoteCents {
+		t.Fatalf("sender balance = %d, want %d", l.balances[testUserAccountId], 5*testBanknoteCents)
+	}
+}
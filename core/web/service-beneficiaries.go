@@ -0,0 +1,126 @@
+package web
+
+import (
+	"context"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) AddBeneficiary(ctx context.Context, userId, accountId int64, nickname string) (_ BeneficiaryData, err error) {
+	audit := s.startAudit(ctx, AuditActionBeneficiaryAdd, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	accountData, err := s.accountStorage.GetAccountDataById(ctx, accountId)
+	if err != nil {
+		return BeneficiaryData{}, err
+	}
+	if accountData.UserId == userId {
+		return BeneficiaryData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Нельзя добавить собственный счёт в получатели")
+	}
+
+	var beneficiaryId int64
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		var err error
+		if beneficiaryId, err = s.beneficiaryStorage.CreateBeneficiary(ctx, userId, accountId, nickname); err != nil {
+			return err
+		}
+		audit.entry.EntityId = beneficiaryId
+		audit.after = auditValues{"accountId": accountId, "nickname": nickname}
+		return nil
+	})
+	if err != nil {
+		return BeneficiaryData{}, err
+	}
+
+	now := time.Now()
+	return BeneficiaryData{
+		Id:              beneficiaryId,
+		UserId:          userId,
+		AccountId:       accountId,
+		AccountNumber:   accountData.Number,
+		Nickname:        nickname,
+		CreatedAt:       now,
+		CoolingOffUntil: now.Add(s.beneficiaryPolicy.CoolingOff),
+	}, nil
+}
+
+func (s *Service) GetBeneficiaries(ctx context.Context, userId int64) ([]BeneficiaryData, error) {
+	beneficiaries, err := s.beneficiaryStorage.GetUserBeneficiaries(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range beneficiaries {
+		beneficiaries[i].CoolingOffUntil = beneficiaries[i].CreatedAt.Add(s.beneficiaryPolicy.CoolingOff)
+	}
+	return beneficiaries, nil
+}
+
+func (s *Service) RenameBeneficiary(ctx context.Context, beneficiaryId, userId int64, nickname string) (err error) {
+	audit := s.startAudit(ctx, AuditActionBeneficiaryRename, beneficiaryId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+	audit.after = auditValues{"nickname": nickname}
+
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		return s.beneficiaryStorage.UpdateBeneficiaryNickname(ctx, beneficiaryId, userId, nickname)
+	})
+}
+
+func (s *Service) DeleteBeneficiary(ctx context.Context, beneficiaryId, userId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionBeneficiaryDelete, beneficiaryId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		return s.beneficiaryStorage.DeleteBeneficiary(ctx, beneficiaryId, userId)
+	})
+}
+
+func (s *Service) checkBeneficiaryCoolingOff(ctx context.Context, userId, receiverId, amountCents int64) error {
+	if userId == 0 || s.beneficiaryPolicy.CoolingOff <= 0 {
+		return nil
+	}
+
+	beneficiary, err := s.beneficiaryStorage.GetUserBeneficiaryByAccount(ctx, userId, receiverId)
+	if err != nil {
+		if hasErrorCode(err, ercodes.BeneficiaryNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !time.Now().Before(beneficiary.CreatedAt.Add(s.beneficiaryPolicy.CoolingOff)) {
+		return nil
+	}
+
+	sentCents, err := s.beneficiaryStorage.GetUserTransfersSumTo(ctx, userId, receiverId, beneficiary.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if sentCents+amountCents > s.beneficiaryPolicy.CoolingOffLimitCents {
+		return cerrors.NewErrorWithUserMessage(ercodes.BeneficiaryCoolingOff, nil, "Превышен лимит переводов новому получателю, повторите позже")
+	}
+	return nil
+}
+
+func (s *Service) labelBeneficiaries(ctx context.Context, userId, accountId int64, transactions []AccountTransactionsData) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	beneficiaries, err := s.beneficiaryStorage.GetUserBeneficiaries(ctx, userId)
+	if err != nil {
+		return err
+	}
+	nicknames := make(map[int64]string, len(beneficiaries))
+	for _, beneficiary := range beneficiaries {
+		nicknames[beneficiary.AccountId] = beneficiary.Nickname
+	}
+
+	for i := range transactions {
+		counterpartyId := transactions[i].SenderId
+		if counterpartyId == accountId {
+			counterpartyId = transactions[i].ReceiverId
+		}
+		transactions[i].CounterpartyNickname = nicknames[counterpartyId]
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package web
+
+import (
+	"context"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+	"x-bank-ms-bank/pan"
+)
+
+const (
+	CardOperationPurchase      = "PURCHASE"
+	CardOperationAtmWithdrawal = "ATM_WITHDRAWAL"
+
+	CardHoldStatusActive   = "ACTIVE"
+	CardHoldStatusCaptured = "CAPTURED"
+	CardHoldStatusReleased = "RELEASED"
+	CardHoldStatusExpired  = "EXPIRED"
+
+	cardHoldTTL = 7 * 24 * time.Hour
+)
+
+func (s *Service) AuthorizeCardPayment(ctx context.Context, merchantUserId int64, payment CardPaymentData) (_ CardOperationData, err error) {
+	audit := s.startAudit(ctx, AuditActionCardPayment, payment.MerchantAccountId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+	audit.after = auditValues{"card": pan.Mask(pan.Normalize(payment.CardNumber)), "amountCents": payment.AmountCents}
+
+	cardData, err := s.authorizeCard(ctx, merchantUserId, payment)
+	if err != nil {
+		return CardOperationData{}, err
+	}
+
+	var operation CardOperationData
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		operation, err = s.chargeCard(ctx, cardData, payment.MerchantAccountId, payment.AmountCents, CardOperationPurchase, payment.Description)
+		return err
+	})
+	if err != nil {
+		return CardOperationData{}, err
+	}
+	return operation, nil
+}
+
+func (s *Service) CreateCardHold(ctx context.Context, merchantUserId int64, payment CardPaymentData) (_ CardHoldData, err error) {
+	audit := s.startAudit(ctx, AuditActionCardHoldCreate, payment.MerchantAccountId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+	audit.after = auditValues{"card": pan.Mask(pan.Normalize(payment.CardNumber)), "amountCents": payment.AmountCents}
+
+	cardData, err := s.authorizeCard(ctx, merchantUserId, payment)
+	if err != nil {
+		return CardHoldData{}, err
+	}
+
+	hold := CardHoldData{
+		CardId:      cardData.Id,
+		AccountId:   cardData.AccountId,
+		ReceiverId:  payment.MerchantAccountId,
+		AmountCents: payment.AmountCents,
+		Status:      CardHoldStatusActive,
+		Description: payment.Description,
+		ExpiresAt:   time.Now().Add(cardHoldTTL),
+	}
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		if err := s.checkCardLimits(ctx, cardData, payment.AmountCents); err != nil {
+			return err
+		}
+
+		accountData, err := s.accountStorage.GetAccountDataById(ctx, cardData.AccountId)
+		if err != nil {
+			return err
+		}
+		if accountData.Status == "BLOCKED" {
+			return cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт отправителя заблокирован")
+		}
+		if accountData.AvailableCents < payment.AmountCents {
+			return cerrors.NewErrorWithUserMessage(ercodes.NotEnoughMoney, nil, "Недостаточно средств")
+		}
+
+		hold.Id, err = s.cardStorage.CreateCardHold(ctx, hold)
+		audit.entry.EntityId = hold.Id
+		return err
+	})
+	if err != nil {
+		return CardHoldData{}, err
+	}
+	return hold, nil
+}
+
+func (s *Service) CaptureCardHold(ctx context.Context, holdId, merchantUserId, amountCents int64) (_ CardHoldData, err error) {
+	audit := s.startAudit(ctx, AuditActionCardHoldCapture, holdId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	var hold CardHoldData
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		var err error
+		hold, err = s.getActiveCardHold(ctx, holdId, merchantUserId)
+		if err != nil {
+			return err
+		}
+		audit.before = auditValues{"status": hold.Status, "amountCents": hold.AmountCents}
+		if amountCents == 0 {
+			amountCents = hold.AmountCents
+		}
+		if amountCents < 0 || amountCents > hold.AmountCents {
+			return cerrors.NewErrorWithUserMessage(ercodes.InvalidCardHoldAmount, nil, "Сумма списания превышает сумму блокировки")
+		}
+
+		hold.Status = CardHoldStatusCaptured
+		hold.CapturedCents = amountCents
+		audit.after = auditValues{"status": hold.Status, "capturedCents": hold.CapturedCents}
+		if err = s.cardStorage.UpdateCardHold(ctx, hold); err != nil {
+			return err
+		}
+
+		cardData, err := s.cardStorage.GetCardById(ctx, hold.CardId)
+		if err != nil {
+			return err
+		}
+		operation, err := s.transferFromCard(ctx, cardData, hold.ReceiverId, amountCents, CardOperationPurchase, hold.Description)
+		if err != nil {
+			return err
+		}
+
+		hold.TransactionId = operation.TransactionId
+		return s.cardStorage.UpdateCardHold(ctx, hold)
+	})
+	if err != nil {
+		return CardHoldData{}, err
+	}
+	return hold, nil
+}
+
+func (s *Service) ReleaseCardHold(ctx context.Context, holdId, merchantUserId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionCardHoldRelease, holdId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		hold, err := s.getActiveCardHold(ctx, holdId, merchantUserId)
+		if err != nil {
+			return err
+		}
+		audit.before = auditValues{"status": hold.Status, "amountCents": hold.AmountCents}
+
+		hold.Status = CardHoldStatusReleased
+		audit.after = auditValues{"status": hold.Status}
+		return s.cardStorage.UpdateCardHold(ctx, hold)
+	})
+}
+
+func (s *Service) getActiveCardHold(ctx context.Context, holdId, merchantUserId int64) (CardHoldData, error) {
+	hold, err := s.cardStorage.GetCardHoldForUpdate(ctx, holdId)
+	if err != nil {
+		return CardHoldData{}, err
+	}
+
+	merchantAccountData, err := s.accountStorage.GetAccountDataById(ctx, hold.ReceiverId)
+	if err != nil {
+		return CardHoldData{}, err
+	}
+	if merchantAccountData.UserId != merchantUserId {
+		return CardHoldData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+
+	if hold.Status != CardHoldStatusActive || !time.Now().Before(hold.ExpiresAt) {
+		return CardHoldData{}, cerrors.NewErrorWithUserMessage(ercodes.CardHoldNotActive, nil, "Блокировка средств уже завершена")
+	}
+	return hold, nil
+}
+
+func (s *Service) authorizeCard(ctx context.Context, merchantUserId int64, payment CardPaymentData) (CardData, error) {
+	merchantAccountData, err := s.accountStorage.GetAccountDataById(ctx, payment.MerchantAccountId)
+	if err != nil {
+		return CardData{}, err
+	}
+	if merchantAccountData.UserId != merchantUserId {
+		return CardData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+	if merchantAccountData.Status == "BLOCKED" {
+		return CardData{}, cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт получателя заблокирован")
+	}
+
+	cardData, err := s.getActiveCard(ctx, payment.CardNumber)
+	if err != nil {
+		return CardData{}, err
+	}
+	if cardData.CvvHash == nil {
+		return CardData{}, cerrors.NewErrorWithUserMessage(ercodes.WrongCardData, nil, "Неверные данные карты")
+	}
+
+	wrongCardDataErr := cerrors.NewErrorWithUserMessage(ercodes.WrongCardData, nil, "Неверные данные карты")
+	if int(cardData.ExpiresAt.Month()) != payment.ExpiryMonth || cardData.ExpiresAt.Year()%100 != payment.ExpiryYear%100 {
+		return CardData{}, s.registerCardFailure(ctx, cardData, wrongCardDataErr)
+	}
+	if err = s.passwordHasher.CompareHashAndPassword(ctx, payment.Cvv, cardData.CvvHash); err != nil {
+		if hasErrorCode(err, ercodes.WrongPassword) {
+			return CardData{}, s.registerCardFailure(ctx, cardData, wrongCardDataErr)
+		}
+		return CardData{}, err
+	}
+
+	if err = s.resetCardFailures(ctx, cardData); err != nil {
+		return CardData{}, err
+	}
+	return cardData, nil
+}
+
+func (s *Service) chargeCard(ctx context.Context, cardData CardData, receiverId, amountCents int64, operationType, description string) (CardOperationData, error) {
+	if err := s.checkCardLimits(ctx, cardData, amountCents); err != nil {
+		return CardOperationData{}, err
+	}
+	return s.transferFromCard(ctx, cardData, receiverId, amountCents, operationType, description)
+}
+
+func (s *Service) checkCardLimits(ctx context.Context, cardData CardData, amountCents int64) error {
+	if cardData.PerTransactionLimitCents > 0 && amountCents > cardData.PerTransactionLimitCents {
+		return cerrors.NewErrorWithUserMessage(ercodes.CardLimitExceeded, nil, "Превышен лимит на одну операцию по карте")
+	}
+	if cardData.DailyLimitCents > 0 {
+		now := time.Now()
+		spentCents, err := s.cardStorage.GetCardSpentSince(ctx, cardData.Id, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+		if err != nil {
+			return err
+		}
+		if spentCents+amountCents > cardData.DailyLimitCents {
+			return cerrors.NewErrorWithUserMessage(ercodes.CardLimitExceeded, nil, "Превышен дневной лимит по карте")
+		}
+	}
+	return nil
+}
+
+func (s *Service) transferFromCard(ctx context.Context, cardData CardData, receiverId, amountCents int64, operationType, description string) (CardOperationData, error) {
+	transactionId, err := s.makeTransaction(ctx, cardData.AccountId, receiverId, amountCents, cardData.UserId, description, false, false)
+	if err != nil {
+		return CardOperationData{}, err
+	}
+
+	operation := CardOperationData{
+		CardId:        cardData.Id,
+		Type:          operationType,
+		ReceiverId:    receiverId,
+		TransactionId: transactionId,
+		AmountCents:   amountCents,
+		Description:   description,
+	}
+	if operation.Id, err = s.cardStorage.CreateCardOperation(ctx, operation); err != nil {
+		return CardOperationData{}, err
+	}
+	return operation, nil
+}
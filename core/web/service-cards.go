@@ -0,0 +1,197 @@
+package web
+
+import (
+	"context"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+	"x-bank-ms-bank/pan"
+)
+
+const (
+	CardStatusActive  = "ACTIVE"
+	CardStatusBlocked = "BLOCKED"
+
+	cardNumberPrefix   = "220000"
+	cardNumberDigits   = 9
+	cardNumberAttempts = 3
+	cardPinHashCost    = 10
+	cardMaxPinTries    = 3
+	cardCvvDigits      = 3
+	cardValidityYears  = 4
+)
+
+func (s *Service) IssueCard(ctx context.Context, accountId, userId int64, pin string) (_ CardData, _ string, err error) {
+	audit := s.startAudit(ctx, AuditActionCardIssue, accountId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	accountData, err := s.accountStorage.GetAccountDataById(ctx, accountId)
+	if err != nil {
+		return CardData{}, "", err
+	}
+	if accountData.UserId != userId {
+		return CardData{}, "", cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+	if accountData.Status == "BLOCKED" {
+		return CardData{}, "", cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт заблокирован")
+	}
+
+	pinHash, err := s.passwordHasher.HashPassword(ctx, []byte(pin), cardPinHashCost)
+	if err != nil {
+		return CardData{}, "", err
+	}
+	cvv, err := s.randomGenerator.GenerateString(ctx, "0123456789", cardCvvDigits)
+	if err != nil {
+		return CardData{}, "", err
+	}
+	cvvHash, err := s.passwordHasher.HashPassword(ctx, []byte(cvv), cardPinHashCost)
+	if err != nil {
+		return CardData{}, "", err
+	}
+
+	now := time.Now()
+	expiresAt := time.Date(now.Year()+cardValidityYears, now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < cardNumberAttempts; i++ {
+		var digits string
+		digits, err = s.randomGenerator.GenerateString(ctx, "0123456789", cardNumberDigits)
+		if err != nil {
+			return CardData{}, "", err
+		}
+
+		cardData := CardData{
+			AccountId: accountId,
+			UserId:    userId,
+			Number:    pan.New(cardNumberPrefix, digits),
+			PinHash:   pinHash,
+			CvvHash:   cvvHash,
+			Status:    CardStatusActive,
+			ExpiresAt: expiresAt,
+		}
+		err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+			var err error
+			if cardData.Id, err = s.cardStorage.CreateCard(ctx, cardData); err != nil {
+				return err
+			}
+			audit.after = auditValues{"cardId": cardData.Id, "card": pan.Mask(cardData.Number), "status": cardData.Status}
+			return nil
+		})
+		if err == nil {
+			return cardData, cvv, nil
+		}
+		if !hasErrorCode(err, ercodes.CardNumberExists) {
+			return CardData{}, "", err
+		}
+	}
+	return CardData{}, "", err
+}
+
+func (s *Service) GetCards(ctx context.Context, userId int64) ([]CardData, error) {
+	return s.cardStorage.GetUserCards(ctx, userId)
+}
+
+func (s *Service) BlockCard(ctx context.Context, cardId, userId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionCardBlock, cardId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	cardData, err := s.getUserCard(ctx, cardId, userId)
+	if err != nil {
+		return err
+	}
+	audit.before = auditValues{"status": cardData.Status}
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		audit.after = auditValues{"status": CardStatusBlocked}
+		return s.cardStorage.UpdateCardStatus(ctx, cardId, CardStatusBlocked)
+	})
+}
+
+func (s *Service) UnblockCard(ctx context.Context, cardId, userId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionCardUnblock, cardId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	cardData, err := s.getUserCard(ctx, cardId, userId)
+	if err != nil {
+		return err
+	}
+	audit.before = auditValues{"status": cardData.Status}
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		audit.after = auditValues{"status": CardStatusActive}
+		return s.cardStorage.UpdateCardStatus(ctx, cardId, CardStatusActive)
+	})
+}
+
+func (s *Service) SetCardLimits(ctx context.Context, cardId, userId, perTransactionLimitCents, dailyLimitCents int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionCardLimits, cardId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	cardData, err := s.getUserCard(ctx, cardId, userId)
+	if err != nil {
+		return err
+	}
+	audit.before = auditValues{"perTransactionLimitCents": cardData.PerTransactionLimitCents, "dailyLimitCents": cardData.DailyLimitCents}
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		audit.after = auditValues{"perTransactionLimitCents": perTransactionLimitCents, "dailyLimitCents": dailyLimitCents}
+		return s.cardStorage.UpdateCardLimits(ctx, cardId, perTransactionLimitCents, dailyLimitCents)
+	})
+}
+
+func (s *Service) getUserCard(ctx context.Context, cardId, userId int64) (CardData, error) {
+	cardData, err := s.cardStorage.GetCardById(ctx, cardId)
+	if err != nil {
+		return CardData{}, err
+	}
+	if cardData.UserId != userId {
+		return CardData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+	return cardData, nil
+}
+
+func (s *Service) getActiveCard(ctx context.Context, cardNumber string) (CardData, error) {
+	cardData, err := s.cardStorage.GetCardByNumber(ctx, pan.Normalize(cardNumber))
+	if err != nil {
+		return CardData{}, err
+	}
+	if cardData.Status == CardStatusBlocked {
+		return CardData{}, cerrors.NewErrorWithUserMessage(ercodes.CardBlocked, nil, "Карта заблокирована")
+	}
+	if !time.Now().Before(cardData.ExpiresAt.AddDate(0, 0, 1)) {
+		return CardData{}, cerrors.NewErrorWithUserMessage(ercodes.CardExpired, nil, "Срок действия карты истёк")
+	}
+	return cardData, nil
+}
+
+func (s *Service) verifyCardPin(ctx context.Context, cardNumber, pin string) (CardData, error) {
+	cardData, err := s.getActiveCard(ctx, cardNumber)
+	if err != nil {
+		return CardData{}, err
+	}
+
+	if err = s.passwordHasher.CompareHashAndPassword(ctx, pin, cardData.PinHash); err != nil {
+		if !hasErrorCode(err, ercodes.WrongPassword) {
+			return CardData{}, err
+		}
+		return CardData{}, s.registerCardFailure(ctx, cardData, cerrors.NewErrorWithUserMessage(ercodes.WrongPin, nil, "Неверный PIN"))
+	}
+
+	if err = s.resetCardFailures(ctx, cardData); err != nil {
+		return CardData{}, err
+	}
+	return cardData, nil
+}
+
+func (s *Service) registerCardFailure(ctx context.Context, cardData CardData, failureErr error) error {
+	if err := s.cardStorage.RegisterCardPinFailure(ctx, cardData.Id, cardMaxPinTries); err != nil {
+		return err
+	}
+	if cardData.PinTries+1 >= cardMaxPinTries {
+		return cerrors.NewErrorWithUserMessage(ercodes.CardBlocked, nil, "Превышено число попыток ввода данных карты, карта заблокирована")
+	}
+	return failureErr
+}
+
+func (s *Service) resetCardFailures(ctx context.Context, cardData CardData) error {
+	if cardData.PinTries == 0 {
+		return nil
+	}
+	return s.cardStorage.ResetCardPinTries(ctx, cardData.Id)
+}
@@ -0,0 +1,166 @@
+package web
+
+import (
+	"context"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	TransactionReviewStatusPending  = "PENDING"
+	TransactionReviewStatusApproved = "APPROVED"
+	TransactionReviewStatusRejected = "REJECTED"
+
+	FraudReasonVelocity               = "VELOCITY"
+	FraudReasonAmountSpike            = "AMOUNT_SPIKE"
+	FraudReasonNewReceiverLargeAmount = "NEW_RECEIVER_LARGE_AMOUNT"
+	FraudReasonRoundTrip              = "ROUND_TRIP"
+	FraudReasonSanctions              = "SANCTIONS"
+
+	fraudReviewScore = 50
+	fraudDenyScore   = 100
+
+	fraudVelocityWindow      = time.Minute
+	fraudVelocityReviewCount = 5
+	fraudVelocityDenyCount   = 10
+	fraudVelocityScore       = 50
+
+	fraudHistoryPeriod    = 30 * 24 * time.Hour
+	fraudHistoryMinCount  = 3
+	fraudSpikeMultiplier  = 10
+	fraudAmountSpikeScore = 40
+
+	fraudLargeAmountCents = 50_000_00
+	fraudNewReceiverScore = 50
+
+	fraudRoundTripWindow = time.Hour
+	fraudRoundTripScore  = 50
+)
+
+func (s *Service) GetTransactionReviews(ctx context.Context, status string) ([]TransactionReviewData, error) {
+	return s.fraudStorage.GetTransactionReviews(ctx, status)
+}
+
+func (s *Service) ApproveTransactionReview(ctx context.Context, transactionId, reviewerId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionTransactionReviewApprove, transactionId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+	audit.before = auditValues{"status": TransactionReviewStatusPending}
+	audit.after = auditValues{"status": TransactionReviewStatusApproved}
+
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		if err := s.getPendingTransactionReview(ctx, transactionId); err != nil {
+			return err
+		}
+		return s.fraudStorage.ApproveTransactionReview(ctx, transactionId, reviewerId)
+	})
+}
+
+func (s *Service) RejectTransactionReview(ctx context.Context, transactionId, reviewerId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionTransactionReviewReject, transactionId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+	audit.before = auditValues{"status": TransactionReviewStatusPending}
+	audit.after = auditValues{"status": TransactionReviewStatusRejected}
+
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		if err := s.getPendingTransactionReview(ctx, transactionId); err != nil {
+			return err
+		}
+		return s.fraudStorage.RejectTransactionReview(ctx, transactionId, reviewerId)
+	})
+}
+
+func (s *Service) getPendingTransactionReview(ctx context.Context, transactionId int64) error {
+	review, err := s.fraudStorage.GetTransactionReviewForUpdate(ctx, transactionId)
+	if err != nil {
+		return err
+	}
+	if review.Status != TransactionReviewStatusPending {
+		return cerrors.NewErrorWithUserMessage(ercodes.TransactionReviewNotPending, nil, "Перевод уже проверен")
+	}
+	return nil
+}
+
+func (s *Service) createScoredTransaction(ctx context.Context, senderId, receiverId, amountCents, userId int64, description string, hits []ScreeningHitData, reviewHold, bulk bool) (int64, error) {
+	var (
+		score   int
+		reasons []string
+		err     error
+	)
+	if userId != 0 {
+		if score, reasons, err = s.scoreTransfer(ctx, senderId, receiverId, amountCents, bulk); err != nil {
+			return 0, err
+		}
+	}
+	if len(hits) != 0 {
+		score = max(score, fraudReviewScore)
+		reasons = append(reasons, FraudReasonSanctions)
+	}
+
+	if score >= fraudDenyScore || (!reviewHold && score >= fraudReviewScore) {
+		if err = s.storeDeniedScreeningHits(ctx, hits); err != nil {
+			return 0, err
+		}
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.TransferDenied, nil, "Перевод отклонён системой безопасности")
+	}
+	if score < fraudReviewScore {
+		return s.transactionStorage.CreateTransaction(ctx, senderId, receiverId, amountCents, description)
+	}
+
+	var transactionId int64
+	err = s.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		transactionId, err = s.transactionStorage.CreateTransaction(ctx, senderId, receiverId, amountCents, description)
+		if err != nil {
+			return err
+		}
+		if err = s.fraudStorage.HoldTransactionForReview(ctx, transactionId, score, reasons); err != nil {
+			return err
+		}
+		if len(hits) == 0 {
+			return nil
+		}
+		for i := range hits {
+			hits[i].TransactionId = transactionId
+		}
+		return s.screeningStorage.CreateScreeningHits(ctx, hits)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return transactionId, nil
+}
+
+func (s *Service) scoreTransfer(ctx context.Context, senderId, receiverId, amountCents int64, bulk bool) (int, []string, error) {
+	now := time.Now()
+	stats, err := s.fraudStorage.GetTransferStats(ctx, senderId, receiverId, now.Add(-fraudVelocityWindow), now.Add(-fraudHistoryPeriod), now.Add(-fraudRoundTripWindow))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var (
+		score   int
+		reasons []string
+	)
+	if !bulk {
+		if stats.RecentCount >= fraudVelocityDenyCount {
+			score += fraudDenyScore
+			reasons = append(reasons, FraudReasonVelocity)
+		} else if stats.RecentCount >= fraudVelocityReviewCount {
+			score += fraudVelocityScore
+			reasons = append(reasons, FraudReasonVelocity)
+		}
+	}
+	if stats.HistoryCount >= fraudHistoryMinCount && amountCents > stats.HistorySumCents/stats.HistoryCount*fraudSpikeMultiplier {
+		score += fraudAmountSpikeScore
+		reasons = append(reasons, FraudReasonAmountSpike)
+	}
+	if !stats.HasTransfersTo && amountCents >= fraudLargeAmountCents {
+		score += fraudNewReceiverScore
+		reasons = append(reasons, FraudReasonNewReceiverLargeAmount)
+	}
+	if stats.HasRoundTrip {
+		score += fraudRoundTripScore
+		reasons = append(reasons, FraudReasonRoundTrip)
+	}
+	return score, reasons, nil
+}
@@ -0,0 +1,65 @@
+package web
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+	"x-bank-ms-bank/ercodes"
+)
+
+func TestDeniedTransferStoresScreeningHits(t *testing.T) {
+	expiresAt := time.Now().AddDate(1, 0, 0)
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, s *Service) error
+	}{
+		{
+			name: "AuthorizeCardPayment",
+			run: func(ctx context.Context, s *Service) error {
+				_, err := s.AuthorizeCardPayment(ctx, 0, CardPaymentData{
+					MerchantAccountId: testAtmAccountId,
+					CardNumber:        testCardNumber,
+					ExpiryMonth:       int(expiresAt.Month()),
+					ExpiryYear:        expiresAt.Year(),
+					Cvv:               "000",
+					AmountCents:       testBanknoteCents,
+				})
+				return err
+			},
+		},
+		{
+			name: "ATMUserWithdrawal",
+			run: func(ctx context.Context, s *Service) error {
+				_, err := s.ATMUserWithdrawal(ctx, testAtmId, testBanknoteCents, testCardNumber, "0000")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newAtmLedger()
+			l.sanctionedNumber = strconv.FormatInt(testAtmAccountId, 10)
+			s := newAtmTestService(l)
+			initial := l.atmState.clone()
+
+			err := tt.run(context.Background(), &s)
+			if !hasErrorCode(err, ercodes.TransferDenied) {
+				t.Fatalf("error = %v, want TransferDenied", err)
+			}
+			if len(l.screeningHits) != 1 || l.screeningHits[0].AccountId != testAtmAccountId {
+				t.Fatalf("screening hits = %+v, want one hit for account %d", l.screeningHits, testAtmAccountId)
+			}
+			if len(l.audit) != 1 || l.audit[0].Outcome != AuditOutcomeFailure {
+				t.Fatalf("audit entries = %+v, want one failed entry", l.audit)
+			}
+			l.audit = nil
+			if !reflect.DeepEqual(l.atmState, initial) {
+				t.Fatalf("state after rollback = %+v, want %+v", l.atmState, initial)
+			}
+		})
+	}
+}
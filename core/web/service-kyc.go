@@ -0,0 +1,104 @@
+package web
+
+import (
+	"context"
+	"strings"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	kycUnverifiedMaxAccounts          = 1
+	kycUnverifiedMaxBalanceCents      = 15_000_00
+	kycUnverifiedMaxTransferCents     = 5_000_00
+	kycUnverifiedMonthlyOutgoingCents = 40_000_00
+	kycUnverifiedOutgoingPeriodDays   = 30
+	ownerNameMaxLength                = 128
+)
+
+func (s *Service) SyncUserIdentification(ctx context.Context, userId int64, identified bool, fullName string) error {
+	identification := OwnerIdentificationData{Identified: identified}
+	if identified {
+		identification.FullName = strings.Join(strings.Fields(fullName), " ")
+		if runes := []rune(identification.FullName); len(runes) > ownerNameMaxLength {
+			identification.FullName = string(runes[:ownerNameMaxLength])
+		}
+	}
+	if cached, ok := s.identifiedUsers.Load(userId); ok && cached.(OwnerIdentificationData) == identification {
+		return nil
+	}
+
+	var exists bool
+	err := s.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var (
+			previous OwnerIdentificationData
+			changed  bool
+			err      error
+		)
+		previous, exists, changed, err = s.accountStorage.SetUserIdentified(ctx, userId, identification)
+		if err != nil || !changed {
+			return err
+		}
+
+		audit := s.startAudit(ctx, AuditActionUserIdentification, userId)
+		audit.before = auditValues{"identified": previous.Identified, "fullName": previous.FullName}
+		audit.after = auditValues{"identified": identification.Identified, "fullName": identification.FullName}
+		return s.appendAudit(ctx, audit, nil)
+	})
+	if err != nil {
+		return err
+	}
+	if exists {
+		s.identifiedUsers.Store(userId, identification)
+	}
+	return nil
+}
+
+func (s *Service) checkKycAccountsCount(ctx context.Context, userId int64) error {
+	accounts, err := s.accountStorage.GetUserAccounts(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if len(accounts) < kycUnverifiedMaxAccounts {
+		return nil
+	}
+
+	accountData, err := s.accountStorage.GetAccountDataById(ctx, accounts[0].Id)
+	if err != nil {
+		return err
+	}
+	if accountData.OwnerIdentified {
+		return nil
+	}
+	return cerrors.NewErrorWithUserMessage(ercodes.IdentificationRequired, nil, "Для открытия дополнительных счетов пройдите идентификацию")
+}
+
+func (s *Service) checkKycOutgoing(ctx context.Context, senderAccountData UserAccountData, largestCents, totalCents int64) error {
+	if senderAccountData.OwnerIdentified {
+		return nil
+	}
+	if largestCents > kycUnverifiedMaxTransferCents {
+		return cerrors.NewErrorWithUserMessage(ercodes.IdentificationRequired, nil, "Сумма перевода превышает лимит, пройдите идентификацию")
+	}
+
+	now := time.Now()
+	_, outgoingCents, err := s.transactionStorage.GetAccountTransfersSums(ctx, senderAccountData.Id, now.AddDate(0, 0, -kycUnverifiedOutgoingPeriodDays), now)
+	if err != nil {
+		return err
+	}
+	if outgoingCents+totalCents > kycUnverifiedMonthlyOutgoingCents {
+		return cerrors.NewErrorWithUserMessage(ercodes.IdentificationRequired, nil, "Превышен месячный лимит переводов, пройдите идентификацию")
+	}
+	return nil
+}
+
+func checkKycIncoming(receiverAccountData UserAccountData, amountCents int64) error {
+	if receiverAccountData.OwnerIdentified {
+		return nil
+	}
+	if receiverAccountData.BalanceCents+receiverAccountData.PendingIncomingCents+amountCents > kycUnverifiedMaxBalanceCents {
+		return cerrors.NewErrorWithUserMessage(ercodes.ReceiverBalanceLimit, nil, "Превышен лимит остатка на счёте получателя без идентификации")
+	}
+	return nil
+}
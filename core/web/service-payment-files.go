@@ -0,0 +1,311 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+	"x-bank-ms-bank/iban"
+)
+
+const (
+	PaymentFileFormatCSV     = "csv"
+	PaymentFileFormatPain001 = "pain.001"
+
+	PaymentFileStatusRejected             = "REJECTED"
+	PaymentFileStatusAwaitingConfirmation = "AWAITING_CONFIRMATION"
+	PaymentFileStatusProcessing           = "PROCESSING"
+	PaymentFileStatusCompleted            = "COMPLETED"
+	PaymentFileStatusCompletedWithErrors  = "COMPLETED_WITH_ERRORS"
+
+	PaymentFileItemStatusInvalid    = "INVALID"
+	PaymentFileItemStatusPending    = "PENDING"
+	PaymentFileItemStatusProcessing = "PROCESSING"
+	PaymentFileItemStatusCompleted  = "COMPLETED"
+	PaymentFileItemStatusFailed     = "FAILED"
+
+	MaxPaymentFileSize  = 5 << 20
+	MaxPaymentFileItems = 10000
+)
+
+func (s *Service) ImportPaymentFile(ctx context.Context, senderId, userId int64, format string, data []byte) (_ PaymentFileData, err error) {
+	audit := s.startAudit(ctx, AuditActionPaymentFileImport, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	senderAccountData, err := s.accountStorage.GetAccountDataById(ctx, senderId)
+	if err != nil {
+		return PaymentFileData{}, err
+	}
+	if senderAccountData.UserId != userId {
+		return PaymentFileData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+	if senderAccountData.Status == "BLOCKED" {
+		return PaymentFileData{}, cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт отправителя заблокирован")
+	}
+
+	items, err := s.paymentFileParser.ParsePaymentFile(ctx, format, data)
+	if err != nil {
+		return PaymentFileData{}, err
+	}
+	if len(items) == 0 || len(items) > MaxPaymentFileItems {
+		return PaymentFileData{}, cerrors.NewErrorWithUserMessage(ercodes.InvalidPaymentFile, nil, "Файл должен содержать от 1 до 10000 платежей")
+	}
+
+	hash := sha256.Sum256(data)
+	fileData := PaymentFileData{
+		SenderId:   senderId,
+		UserId:     userId,
+		Format:     format,
+		Hash:       hex.EncodeToString(hash[:]),
+		Status:     PaymentFileStatusProcessing,
+		ItemsCount: len(items),
+		Items:      items,
+	}
+
+	receiverIds := make([]int64, 0, len(items))
+	for i := range fileData.Items {
+		item := &fileData.Items[i]
+		if item.Error != "" {
+			continue
+		}
+		if item.AmountCents <= 0 {
+			item.Error = "Неверная сумма платежа"
+			continue
+		}
+		if item.DebtorAccount != "" && iban.Normalize(item.DebtorAccount) != senderAccountData.Number {
+			item.Error = "Счёт плательщика в файле не совпадает со счётом списания"
+			continue
+		}
+
+		item.ReceiverId, err = s.resolvePaymentFileReceiver(ctx, item.Receiver)
+		if err != nil {
+			if !hasErrorCode(err, ercodes.AliasNotFound, ercodes.InvalidAlias, ercodes.AccountNotFound, ercodes.InvalidAccountNumber) {
+				return PaymentFileData{}, err
+			}
+			item.Error = errorUserMessage(err)
+			continue
+		}
+		receiverIds = append(receiverIds, item.ReceiverId)
+
+		if fileData.TotalCents > math.MaxInt64-item.AmountCents {
+			return PaymentFileData{}, cerrors.NewErrorWithUserMessage(ercodes.InvalidPaymentFile, nil, "Слишком большая сумма платежей")
+		}
+		fileData.TotalCents += item.AmountCents
+	}
+
+	receiversData, err := s.accountStorage.GetAccountsDataByIds(ctx, receiverIds)
+	if err != nil {
+		return PaymentFileData{}, err
+	}
+
+	for i := range fileData.Items {
+		item := &fileData.Items[i]
+		if item.Error == "" {
+			receiverData, ok := receiversData[item.ReceiverId]
+			switch {
+			case !ok:
+				item.Error = "Счёт получателя не найден"
+			case item.ReceiverId == senderId:
+				item.Error = "Нельзя перевести деньги на тот же счёт"
+			case receiverData.Status == "BLOCKED":
+				item.Error = "Счёт получателя заблокирован"
+			}
+		}
+
+		item.Status = PaymentFileItemStatusPending
+		if item.Error != "" {
+			item.Status = PaymentFileItemStatusInvalid
+			fileData.Status = PaymentFileStatusRejected
+		}
+	}
+
+	if fileData.Status != PaymentFileStatusRejected && senderAccountData.AvailableCents < fileData.TotalCents {
+		return PaymentFileData{}, cerrors.NewErrorWithUserMessage(ercodes.NotEnoughMoney, nil, "Недостаточно средств для оплаты всех платежей файла")
+	}
+	for i := 0; i < len(fileData.Items) && fileData.Status == PaymentFileStatusProcessing; i++ {
+		item := fileData.Items[i]
+		stepUpRequired, err := s.isStepUpRequired(ctx, senderId, item.ReceiverId, item.AmountCents, userId)
+		if err != nil {
+			return PaymentFileData{}, err
+		}
+		if stepUpRequired {
+			fileData.Status = PaymentFileStatusAwaitingConfirmation
+		}
+	}
+
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		var err error
+		if fileData.Id, err = s.paymentFileStorage.CreatePaymentFile(ctx, fileData); err != nil {
+			return err
+		}
+		audit.entry.EntityId = fileData.Id
+		audit.after = auditValues{"senderId": senderId, "format": format, "hash": fileData.Hash, "itemsCount": fileData.ItemsCount, "totalCents": fileData.TotalCents, "status": fileData.Status}
+		return nil
+	})
+	if err != nil {
+		return PaymentFileData{}, err
+	}
+	return fileData, nil
+}
+
+func (s *Service) CreatePaymentFileStepUpChallenge(ctx context.Context, fileId, userId int64) (_ StepUpChallengeData, err error) {
+	audit := s.startAudit(ctx, AuditActionStepUpCreate, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	fileData, err := s.getAwaitingPaymentFile(ctx, fileId, userId)
+	if err != nil {
+		return StepUpChallengeData{}, err
+	}
+	return s.createStepUpChallenge(ctx, audit, paymentFileStepUp(fileData))
+}
+
+func (s *Service) ConfirmPaymentFile(ctx context.Context, fileId, userId int64, confirmationToken string) (err error) {
+	audit := s.startAudit(ctx, AuditActionPaymentFileConfirm, fileId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+	audit.before = auditValues{"status": PaymentFileStatusAwaitingConfirmation}
+
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		fileData, err := s.getAwaitingPaymentFile(ctx, fileId, userId)
+		if err != nil {
+			return err
+		}
+		if err = s.confirmOperationStepUp(ctx, paymentFileStepUp(fileData), confirmationToken); err != nil {
+			return err
+		}
+		audit.after = auditValues{"status": PaymentFileStatusProcessing}
+		return s.paymentFileStorage.UpdatePaymentFileStatus(ctx, fileId, PaymentFileStatusProcessing)
+	})
+}
+
+func (s *Service) getAwaitingPaymentFile(ctx context.Context, fileId, userId int64) (PaymentFileData, error) {
+	fileData, err := s.GetPaymentFile(ctx, fileId, userId)
+	if err != nil {
+		return PaymentFileData{}, err
+	}
+	if fileData.Status != PaymentFileStatusAwaitingConfirmation {
+		return PaymentFileData{}, cerrors.NewErrorWithUserMessage(ercodes.PaymentFileNotAwaitingConfirmation, nil, "Файл платежей не ожидает подтверждения")
+	}
+	return fileData, nil
+}
+
+func paymentFileStepUp(fileData PaymentFileData) StepUpChallengeData {
+	return StepUpChallengeData{
+		Operation:     StepUpOperationPaymentFile,
+		UserId:        fileData.UserId,
+		SenderId:      fileData.SenderId,
+		AmountCents:   fileData.TotalCents,
+		OperationHash: fileData.Hash,
+	}
+}
+
+func (s *Service) ProcessPaymentFileAsync(fileId int64) {
+	go func() {
+		if err := s.ProcessPaymentFile(context.Background(), fileId); err != nil {
+			log.Printf("payment file %d: %v", fileId, err)
+		}
+	}()
+}
+
+func (s *Service) ResumePaymentFiles(ctx context.Context) error {
+	fileIds, err := s.paymentFileStorage.GetPaymentFileIdsByStatus(ctx, PaymentFileStatusProcessing)
+	if err != nil {
+		return err
+	}
+
+	for _, fileId := range fileIds {
+		if err = s.ProcessPaymentFile(ctx, fileId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) ProcessPaymentFile(ctx context.Context, fileId int64) (err error) {
+	fileData, err := s.paymentFileStorage.GetPaymentFile(ctx, fileId)
+	if err != nil {
+		return err
+	}
+	if fileData.Status != PaymentFileStatusProcessing {
+		return nil
+	}
+
+	audit := s.startAudit(ctx, AuditActionPaymentFileProcess, fileId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+	audit.before = auditValues{"status": fileData.Status}
+
+	for _, item := range fileData.Items {
+		if item.Status != PaymentFileItemStatusPending {
+			continue
+		}
+		if err = s.processPaymentFileItem(ctx, fileData, item); err != nil {
+			return err
+		}
+	}
+
+	fileData, err = s.paymentFileStorage.GetPaymentFile(ctx, fileId)
+	if err != nil {
+		return err
+	}
+	status := PaymentFileStatusCompleted
+	for _, item := range fileData.Items {
+		if item.Status == PaymentFileItemStatusFailed || item.Status == PaymentFileItemStatusProcessing {
+			status = PaymentFileStatusCompletedWithErrors
+		}
+	}
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		audit.after = auditValues{"status": status}
+		return s.paymentFileStorage.UpdatePaymentFileStatus(ctx, fileId, status)
+	})
+}
+
+func (s *Service) processPaymentFileItem(ctx context.Context, fileData PaymentFileData, item PaymentFileItem) error {
+	return s.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		claimed, err := s.paymentFileStorage.ClaimPaymentFileItem(ctx, item.Id)
+		if err != nil || !claimed {
+			return err
+		}
+
+		transactionId, err := s.makeTransaction(ctx, fileData.SenderId, item.ReceiverId, item.AmountCents, fileData.UserId, item.Description, true, true)
+		if err != nil {
+			if hasErrorCode(err, ercodes.PostgresQuery, ercodes.PostgresScan) {
+				return err
+			}
+			return s.paymentFileStorage.UpdatePaymentFileItem(ctx, item.Id, PaymentFileItemStatusFailed, 0, errorUserMessage(err))
+		}
+		return s.paymentFileStorage.UpdatePaymentFileItem(ctx, item.Id, PaymentFileItemStatusCompleted, transactionId, "")
+	})
+}
+
+func (s *Service) GetPaymentFile(ctx context.Context, fileId, userId int64) (PaymentFileData, error) {
+	fileData, err := s.paymentFileStorage.GetPaymentFile(ctx, fileId)
+	if err != nil {
+		return PaymentFileData{}, err
+	}
+
+	senderAccountData, err := s.accountStorage.GetAccountDataById(ctx, fileData.SenderId)
+	if err != nil {
+		return PaymentFileData{}, err
+	}
+	if senderAccountData.UserId != userId {
+		return PaymentFileData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+
+	return fileData, nil
+}
+
+func (s *Service) resolvePaymentFileReceiver(ctx context.Context, receiver string) (int64, error) {
+	var item BatchTransferItem
+	if strings.HasPrefix(receiver, "+") || strings.Contains(receiver, "@") {
+		item.ReceiverAlias = receiver
+	} else if receiverId, err := strconv.ParseInt(receiver, 10, 64); err == nil {
+		item.ReceiverId = receiverId
+	} else {
+		item.ReceiverNumber = receiver
+	}
+	return s.resolveBatchReceiver(ctx, item)
+}
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	PaymentRequestStatusPending  = "PENDING"
+	PaymentRequestStatusAccepted = "ACCEPTED"
+	PaymentRequestStatusDeclined = "DECLINED"
+	PaymentRequestStatusExpired  = "EXPIRED"
+
+	DefaultPaymentRequestTTL = 72 * time.Hour
+	MaxPaymentRequestTTL     = 30 * 24 * time.Hour
+)
+
+func (s *Service) CreatePaymentRequest(ctx context.Context, requesterAccountId, payerAccountId, amountCents, userId int64, description string, ttl time.Duration) (_ int64, err error) {
+	audit := s.startAudit(ctx, AuditActionPaymentRequestCreate, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	if requesterAccountId == payerAccountId {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.SameAccount, nil, "Нельзя запросить деньги с того же счёта")
+	}
+
+	requesterAccountData, err := s.accountStorage.GetAccountDataById(ctx, requesterAccountId)
+	if err != nil {
+		return 0, err
+	}
+	if requesterAccountData.UserId != userId {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+	if requesterAccountData.Status == "BLOCKED" {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт получателя заблокирован")
+	}
+
+	payerAccountData, err := s.accountStorage.GetAccountDataById(ctx, payerAccountId)
+	if err != nil {
+		return 0, err
+	}
+	if payerAccountData.UserId == 0 {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Нельзя запросить деньги с этого счёта")
+	}
+	if payerAccountData.Status == "BLOCKED" {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт плательщика заблокирован")
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultPaymentRequestTTL
+	}
+	var requestId int64
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		var err error
+		if requestId, err = s.paymentRequestStorage.CreatePaymentRequest(ctx, requesterAccountId, payerAccountId, amountCents, description, ttl); err != nil {
+			return err
+		}
+		audit.entry.EntityId = requestId
+		audit.after = auditValues{"requesterAccountId": requesterAccountId, "payerAccountId": payerAccountId, "amountCents": amountCents, "status": PaymentRequestStatusPending}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return requestId, nil
+}
+
+func (s *Service) GetPaymentRequests(ctx context.Context, userId int64, incoming bool, limit, offset int64) ([]PaymentRequestData, int64, error) {
+	return s.paymentRequestStorage.GetUserPaymentRequests(ctx, userId, incoming, limit, offset)
+}
+
+func (s *Service) AcceptPaymentRequest(ctx context.Context, requestId, userId int64, confirmationToken string) (_ int64, err error) {
+	audit := s.startAudit(ctx, AuditActionPaymentRequestAccept, requestId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	requestData, err := s.getPayerPaymentRequest(ctx, requestId, userId)
+	if err != nil {
+		return 0, err
+	}
+	stepUpRequired, err := s.isStepUpRequired(ctx, requestData.PayerAccountId, requestData.RequesterAccountId, requestData.AmountCents, userId)
+	if err != nil {
+		return 0, err
+	}
+	audit.before = auditValues{"status": requestData.Status, "stepUpRequired": stepUpRequired}
+
+	description := requestData.Description
+	if description == "" {
+		description = fmt.Sprintf("Оплата запроса денег №%d", requestId)
+	}
+
+	var transactionId int64
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		if stepUpRequired {
+			if err := s.confirmStepUp(ctx, requestData.PayerAccountId, requestData.RequesterAccountId, requestData.AmountCents, userId, requestData.Description, confirmationToken); err != nil {
+				return err
+			}
+		}
+		if err := s.paymentRequestStorage.ClaimPaymentRequest(ctx, requestId, PaymentRequestStatusAccepted); err != nil {
+			return err
+		}
+
+		var err error
+		transactionId, err = s.MakeTransaction(ctx, requestData.PayerAccountId, requestData.RequesterAccountId, requestData.AmountCents, userId, description)
+		if err != nil {
+			return err
+		}
+		if err = s.paymentRequestStorage.LinkPaymentRequestTransaction(ctx, requestId, transactionId); err != nil {
+			return err
+		}
+		audit.after = auditValues{"status": PaymentRequestStatusAccepted, "transactionId": transactionId, "amountCents": requestData.AmountCents}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return transactionId, nil
+}
+
+func (s *Service) DeclinePaymentRequest(ctx context.Context, requestId, userId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionPaymentRequestDecline, requestId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	requestData, err := s.getPayerPaymentRequest(ctx, requestId, userId)
+	if err != nil {
+		return err
+	}
+	audit.before = auditValues{"status": requestData.Status}
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		audit.after = auditValues{"status": PaymentRequestStatusDeclined}
+		return s.paymentRequestStorage.ClaimPaymentRequest(ctx, requestId, PaymentRequestStatusDeclined)
+	})
+}
+
+func (s *Service) getPayerPaymentRequest(ctx context.Context, requestId, userId int64) (PaymentRequestData, error) {
+	requestData, err := s.paymentRequestStorage.GetPaymentRequest(ctx, requestId)
+	if err != nil {
+		return PaymentRequestData{}, err
+	}
+
+	payerAccountData, err := s.accountStorage.GetAccountDataById(ctx, requestData.PayerAccountId)
+	if err != nil {
+		return PaymentRequestData{}, err
+	}
+	if payerAccountData.UserId != userId {
+		return PaymentRequestData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+
+	if requestData.Status != PaymentRequestStatusPending {
+		return PaymentRequestData{}, cerrors.NewErrorWithUserMessage(ercodes.PaymentRequestNotPending, nil, "Запрос денег уже обработан или истёк")
+	}
+	return requestData, nil
+}
@@ -0,0 +1,71 @@
+package web
+
+import (
+	"context"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	ScreeningListUser    = "USER"
+	ScreeningListAccount = "ACCOUNT"
+	ScreeningListName    = "NAME"
+
+	ScreeningOperationTransfer      = "TRANSFER"
+	ScreeningOperationAtmSupplement = "ATM_SUPPLEMENT"
+	ScreeningOperationAtmWithdrawal = "ATM_WITHDRAWAL"
+)
+
+func (s *Service) screenAccounts(ctx context.Context, operation string, accounts ...UserAccountData) ([]ScreeningHitData, error) {
+	var hits []ScreeningHitData
+	for _, account := range accounts {
+		names, err := s.screeningStorage.GetAccountOwnerNames(ctx, account.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		matches := s.screener.Screen(ctx, ScreeningSubject{
+			UserId:        account.UserId,
+			AccountNumber: account.Number,
+			Names:         names,
+		})
+		for _, match := range matches {
+			hits = append(hits, ScreeningHitData{
+				Operation: operation,
+				UserId:    account.UserId,
+				AccountId: account.Id,
+				ListType:  match.ListType,
+				Entry:     match.Entry,
+			})
+		}
+	}
+	return hits, nil
+}
+
+func (s *Service) checkAtmScreening(ctx context.Context, operation string, accountId int64) error {
+	accountData, err := s.accountStorage.GetAccountDataById(ctx, accountId)
+	if err != nil {
+		return err
+	}
+	hits, err := s.screenAccounts(ctx, operation, accountData)
+	if err != nil {
+		return err
+	}
+	if len(hits) == 0 {
+		return nil
+	}
+
+	if err = s.storeDeniedScreeningHits(ctx, hits); err != nil {
+		return err
+	}
+	return cerrors.NewErrorWithUserMessage(ercodes.ScreeningHit, nil, "Операция недоступна, обратитесь в банк")
+}
+
+func (s *Service) storeDeniedScreeningHits(ctx context.Context, hits []ScreeningHitData) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	return s.transactionManager.WithinNewTransaction(ctx, func(ctx context.Context) error {
+		return s.screeningStorage.CreateScreeningHits(ctx, hits)
+	})
+}
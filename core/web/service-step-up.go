@@ -0,0 +1,261 @@
+package web
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	StepUpStatusPending   = "PENDING"
+	StepUpStatusConfirmed = "CONFIRMED"
+	StepUpStatusUsed      = "USED"
+	StepUpStatusFailed    = "FAILED"
+
+	StepUpOperationTransfer    = "TRANSFER"
+	StepUpOperationBatch       = "BATCH"
+	StepUpOperationPaymentFile = "PAYMENT_FILE"
+
+	stepUpCodeCharset  = "0123456789"
+	stepUpCodeLength   = 6
+	stepUpTokenCharset = "0123456789abcdef"
+	stepUpTokenLength  = 32
+	stepUpHashCost     = 10
+	stepUpMaxAttempts  = 3
+	stepUpCodeTTL      = 5 * time.Minute
+	stepUpTokenTTL     = 5 * time.Minute
+)
+
+func (s *Service) MakeUserTransaction(ctx context.Context, senderId, receiverId, amountCents, userId int64, description, confirmationToken string) (transactionId int64, err error) {
+	audit := s.startAudit(ctx, AuditActionTransfer, senderId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	if senderId == receiverId {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.SameAccount, nil, "Нельзя перевести деньги на тот же счёт")
+	}
+	required, err := s.isStepUpRequired(ctx, senderId, receiverId, amountCents, userId)
+	if err != nil {
+		return 0, err
+	}
+	audit.before = auditValues{"stepUpRequired": required}
+	audit.after = auditValues{"receiverId": receiverId, "amountCents": amountCents}
+
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		if required {
+			if err := s.confirmStepUp(ctx, senderId, receiverId, amountCents, userId, description, confirmationToken); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		transactionId, err = s.MakeTransaction(ctx, senderId, receiverId, amountCents, userId, description)
+		if err != nil {
+			return err
+		}
+		audit.after = auditValues{"receiverId": receiverId, "amountCents": amountCents, "transactionId": transactionId}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return transactionId, nil
+}
+
+func (s *Service) CreateStepUpChallenge(ctx context.Context, senderId, receiverId, amountCents, userId int64, description string) (_ StepUpChallengeData, err error) {
+	audit := s.startAudit(ctx, AuditActionStepUpCreate, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	if senderId == receiverId {
+		return StepUpChallengeData{}, cerrors.NewErrorWithUserMessage(ercodes.SameAccount, nil, "Нельзя перевести деньги на тот же счёт")
+	}
+	senderAccountData, err := s.accountStorage.GetAccountDataById(ctx, senderId)
+	if err != nil {
+		return StepUpChallengeData{}, err
+	}
+	if senderAccountData.UserId != userId {
+		return StepUpChallengeData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+	if _, err = s.accountStorage.GetAccountDataById(ctx, receiverId); err != nil {
+		return StepUpChallengeData{}, err
+	}
+
+	return s.createStepUpChallenge(ctx, audit, StepUpChallengeData{
+		Operation:   StepUpOperationTransfer,
+		UserId:      userId,
+		SenderId:    senderId,
+		ReceiverId:  receiverId,
+		AmountCents: amountCents,
+		Description: description,
+	})
+}
+
+func (s *Service) createStepUpChallenge(ctx context.Context, audit *auditRecord, challenge StepUpChallengeData) (StepUpChallengeData, error) {
+	code, err := s.randomGenerator.GenerateString(ctx, stepUpCodeCharset, stepUpCodeLength)
+	if err != nil {
+		return StepUpChallengeData{}, err
+	}
+	if challenge.CodeHash, err = s.passwordHasher.HashPassword(ctx, []byte(code), stepUpHashCost); err != nil {
+		return StepUpChallengeData{}, err
+	}
+	challenge.Status = StepUpStatusPending
+	challenge.ExpiresAt = time.Now().Add(stepUpCodeTTL)
+
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		var err error
+		if challenge.Id, err = s.stepUpStorage.CreateStepUpChallenge(ctx, challenge); err != nil {
+			return err
+		}
+		audit.entry.EntityId = challenge.Id
+		audit.after = auditValues{"operation": challenge.Operation, "senderId": challenge.SenderId, "receiverId": challenge.ReceiverId, "amountCents": challenge.AmountCents, "status": challenge.Status}
+		return s.stepUpNotifier.SendStepUpCode(ctx, challenge.UserId, code)
+	})
+	if err != nil {
+		return StepUpChallengeData{}, err
+	}
+	return challenge, nil
+}
+
+func (s *Service) ConfirmStepUpChallenge(ctx context.Context, challengeId, userId int64, code string) (_ string, _ time.Time, err error) {
+	audit := s.startAudit(ctx, AuditActionStepUpConfirm, challengeId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	var (
+		token     string
+		expiresAt time.Time
+		codeErr   error
+	)
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		challenge, err := s.getStepUpChallenge(ctx, challengeId, userId, StepUpStatusPending)
+		if err != nil {
+			return err
+		}
+		audit.before = auditValues{"status": challenge.Status, "attempts": challenge.Attempts}
+
+		if err = s.passwordHasher.CompareHashAndPassword(ctx, code, challenge.CodeHash); err != nil {
+			challenge.Attempts++
+			if challenge.Attempts >= stepUpMaxAttempts {
+				challenge.Status = StepUpStatusFailed
+			}
+			codeErr = cerrors.NewErrorWithUserMessage(ercodes.WrongStepUpCode, err, "Неверный код подтверждения")
+			if err = s.stepUpStorage.UpdateStepUpChallenge(ctx, challenge); err != nil {
+				return err
+			}
+			audit.after = auditValues{"status": challenge.Status, "attempts": challenge.Attempts}
+			return s.appendAudit(ctx, audit, codeErr)
+		}
+
+		secret, err := s.randomGenerator.GenerateString(ctx, stepUpTokenCharset, stepUpTokenLength)
+		if err != nil {
+			return err
+		}
+		if challenge.TokenHash, err = s.passwordHasher.HashPassword(ctx, []byte(secret), stepUpHashCost); err != nil {
+			return err
+		}
+		challenge.Status = StepUpStatusConfirmed
+		challenge.ExpiresAt = time.Now().Add(stepUpTokenTTL)
+		if err = s.stepUpStorage.UpdateStepUpChallenge(ctx, challenge); err != nil {
+			return err
+		}
+
+		token = strconv.FormatInt(challenge.Id, 10) + "." + secret
+		expiresAt = challenge.ExpiresAt
+		audit.after = auditValues{"status": challenge.Status, "attempts": challenge.Attempts}
+		return nil
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if codeErr != nil {
+		return "", time.Time{}, codeErr
+	}
+	return token, expiresAt, nil
+}
+
+func (s *Service) confirmStepUp(ctx context.Context, senderId, receiverId, amountCents, userId int64, description, confirmationToken string) error {
+	return s.confirmOperationStepUp(ctx, StepUpChallengeData{
+		Operation:   StepUpOperationTransfer,
+		UserId:      userId,
+		SenderId:    senderId,
+		ReceiverId:  receiverId,
+		AmountCents: amountCents,
+		Description: description,
+	}, confirmationToken)
+}
+
+func (s *Service) confirmOperationStepUp(ctx context.Context, expected StepUpChallengeData, confirmationToken string) error {
+	if confirmationToken == "" {
+		return cerrors.NewErrorWithUserMessage(ercodes.StepUpRequired, nil, "Перевод требует подтверждения кодом")
+	}
+
+	challenge, err := s.useConfirmationToken(ctx, confirmationToken, expected.UserId)
+	if err != nil {
+		return err
+	}
+	if challenge.Operation != expected.Operation || challenge.SenderId != expected.SenderId || challenge.ReceiverId != expected.ReceiverId ||
+		challenge.AmountCents != expected.AmountCents || challenge.Description != expected.Description || challenge.OperationHash != expected.OperationHash {
+		return cerrors.NewErrorWithUserMessage(ercodes.InvalidConfirmationToken, nil, "Подтверждение выдано для другого перевода")
+	}
+	return nil
+}
+
+func (s *Service) useConfirmationToken(ctx context.Context, confirmationToken string, userId int64) (StepUpChallengeData, error) {
+	rawId, secret, ok := strings.Cut(confirmationToken, ".")
+	challengeId, err := strconv.ParseInt(rawId, 10, 64)
+	if !ok || err != nil {
+		return StepUpChallengeData{}, cerrors.NewErrorWithUserMessage(ercodes.InvalidConfirmationToken, err, "Неверный токен подтверждения")
+	}
+
+	challenge, err := s.getStepUpChallenge(ctx, challengeId, userId, StepUpStatusConfirmed)
+	if err != nil {
+		return StepUpChallengeData{}, err
+	}
+	if err = s.passwordHasher.CompareHashAndPassword(ctx, secret, challenge.TokenHash); err != nil {
+		return StepUpChallengeData{}, cerrors.NewErrorWithUserMessage(ercodes.InvalidConfirmationToken, err, "Неверный токен подтверждения")
+	}
+
+	challenge.Status = StepUpStatusUsed
+	if err = s.stepUpStorage.UpdateStepUpChallenge(ctx, challenge); err != nil {
+		return StepUpChallengeData{}, err
+	}
+	return challenge, nil
+}
+
+func (s *Service) getStepUpChallenge(ctx context.Context, challengeId, userId int64, status string) (StepUpChallengeData, error) {
+	challenge, err := s.stepUpStorage.GetStepUpChallengeForUpdate(ctx, challengeId)
+	if err != nil {
+		return StepUpChallengeData{}, err
+	}
+	if challenge.UserId != userId {
+		return StepUpChallengeData{}, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+	if challenge.Status != status || !time.Now().Before(challenge.ExpiresAt) {
+		return StepUpChallengeData{}, cerrors.NewErrorWithUserMessage(ercodes.StepUpChallengeNotActive, nil, "Подтверждение недействительно")
+	}
+	return challenge, nil
+}
+
+func (s *Service) isStepUpRequired(ctx context.Context, senderId, receiverId, amountCents, userId int64) (bool, error) {
+	if s.stepUpPolicy.ThresholdCents > 0 && amountCents > s.stepUpPolicy.ThresholdCents {
+		return true, nil
+	}
+	if !s.stepUpPolicy.FirstTimeReceiver || senderId == receiverId {
+		return false, nil
+	}
+
+	receiverAccountData, err := s.accountStorage.GetAccountDataById(ctx, receiverId)
+	if err != nil {
+		return false, err
+	}
+	if receiverAccountData.UserId == userId {
+		return false, nil
+	}
+
+	hasTransfers, err := s.stepUpStorage.HasUserTransfersTo(ctx, userId, receiverId)
+	if err != nil {
+		return false, err
+	}
+	return !hasTransfers, nil
+}
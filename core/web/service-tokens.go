@@ -0,0 +1,21 @@
+package web
+
+import (
+	"context"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) (err error) {
+	audit := s.startAudit(ctx, AuditActionTokenRevoke, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+	audit.after = auditValues{"jti": jti, "expiresAt": expiresAt}
+
+	if jti == "" {
+		return cerrors.NewErrorWithUserMessage(ercodes.TokenWithoutId, nil, "Токен не содержит идентификатора")
+	}
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		return s.tokenStorage.RevokeToken(ctx, jti, expiresAt)
+	})
+}
@@ -2,25 +2,82 @@ package web
 
 import (
 	"context"
+	"log"
+	"sync"
+	"time"
 	"x-bank-ms-bank/cerrors"
 	"x-bank-ms-bank/ercodes"
+	"x-bank-ms-bank/iban"
 )
 
 type (
 	Service struct {
-		accountStorage     AccountStorage
-		passwordHasher     PasswordHasher
-		atmStorage         AtmStorage
-		transactionStorage TransactionStorage
+		accountStorage        AccountStorage
+		passwordHasher        PasswordHasher
+		atmStorage            AtmStorage
+		transactionStorage    TransactionStorage
+		aliasStorage          AliasStorage
+		paymentRequestStorage PaymentRequestStorage
+		paymentFileStorage    PaymentFileStorage
+		paymentFileParser     PaymentFileParser
+		cardStorage           CardStorage
+		transactionManager    TransactionManager
+		accountEventStorage   AccountEventStorage
+		tokenStorage          TokenStorage
+		beneficiaryStorage    BeneficiaryStorage
+		stepUpStorage         StepUpStorage
+		stepUpNotifier        StepUpNotifier
+		fraudStorage          FraudStorage
+		screeningStorage      ScreeningStorage
+		screener              Screener
+		auditStorage          AuditStorage
+		randomGenerator       RandomGenerator
+		stepUpPolicy          StepUpPolicy
+		beneficiaryPolicy     BeneficiaryPolicy
+
+		accountEvents   *accountEventHub
+		identifiedUsers *sync.Map
 	}
 )
 
-func NewService(accountStorage AccountStorage, passwordHasher PasswordHasher, atmStorage AtmStorage, transactionStorage TransactionStorage) Service {
+const (
+	accountNumberCountryCode = "XB"
+	accountNumberBankCode    = "0001"
+	accountNumberDigits      = 16
+	accountNumberAttempts    = 3
+
+	atmMaxLoginAttempts = 5
+	atmLockDuration     = 15 * time.Minute
+	atmSessionIdCharset = "0123456789abcdef"
+	atmSessionIdLength  = 32
+)
+
+func NewService(accountStorage AccountStorage, passwordHasher PasswordHasher, atmStorage AtmStorage, transactionStorage TransactionStorage, aliasStorage AliasStorage, paymentRequestStorage PaymentRequestStorage, paymentFileStorage PaymentFileStorage, paymentFileParser PaymentFileParser, cardStorage CardStorage, transactionManager TransactionManager, accountEventStorage AccountEventStorage, tokenStorage TokenStorage, beneficiaryStorage BeneficiaryStorage, stepUpStorage StepUpStorage, stepUpNotifier StepUpNotifier, fraudStorage FraudStorage, screeningStorage ScreeningStorage, screener Screener, auditStorage AuditStorage, randomGenerator RandomGenerator, stepUpPolicy StepUpPolicy, beneficiaryPolicy BeneficiaryPolicy) Service {
 	return Service{
-		accountStorage:     accountStorage,
-		passwordHasher:     passwordHasher,
-		atmStorage:         atmStorage,
-		transactionStorage: transactionStorage,
+		accountStorage:        accountStorage,
+		passwordHasher:        passwordHasher,
+		atmStorage:            atmStorage,
+		transactionStorage:    transactionStorage,
+		aliasStorage:          aliasStorage,
+		paymentRequestStorage: paymentRequestStorage,
+		paymentFileStorage:    paymentFileStorage,
+		paymentFileParser:     paymentFileParser,
+		cardStorage:           cardStorage,
+		transactionManager:    transactionManager,
+		accountEventStorage:   accountEventStorage,
+		tokenStorage:          tokenStorage,
+		beneficiaryStorage:    beneficiaryStorage,
+		stepUpStorage:         stepUpStorage,
+		stepUpNotifier:        stepUpNotifier,
+		fraudStorage:          fraudStorage,
+		screeningStorage:      screeningStorage,
+		screener:              screener,
+		auditStorage:          auditStorage,
+		randomGenerator:       randomGenerator,
+		stepUpPolicy:          stepUpPolicy,
+		beneficiaryPolicy:     beneficiaryPolicy,
+		accountEvents:         newAccountEventHub(),
+		identifiedUsers:       &sync.Map{},
 	}
 }
 
@@ -28,105 +85,337 @@ func (s *Service) GetAccounts(ctx context.Context, userId int64) ([]UserAccountD
 	return s.accountStorage.GetUserAccounts(ctx, userId)
 }
 
-func (s *Service) OpenAccount(ctx context.Context, userId int64) error {
-	return s.accountStorage.OpenUserAccount(ctx, userId)
+func (s *Service) OpenAccount(ctx context.Context, userId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionAccountOpen, 0)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	if err = s.checkKycAccountsCount(ctx, userId); err != nil {
+		return err
+	}
+
+	for i := 0; i < accountNumberAttempts; i++ {
+		var number string
+		number, err = s.generateAccountNumber(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+			audit.after = auditValues{"userId": userId, "number": number}
+			return s.accountStorage.OpenUserAccount(ctx, userId, number)
+		})
+		if !hasErrorCode(err, ercodes.AccountNumberExists) {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *Service) ResolveAccountNumber(ctx context.Context, number string) (int64, error) {
+	number = iban.Normalize(number)
+	if !iban.IsValid(number) {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.InvalidAccountNumber, nil, "Неверный номер счёта")
+	}
+	return s.accountStorage.GetAccountIdByNumber(ctx, number)
+}
+
+func (s *Service) generateAccountNumber(ctx context.Context) (string, error) {
+	digits, err := s.randomGenerator.GenerateString(ctx, "0123456789", accountNumberDigits)
+	if err != nil {
+		return "", err
+	}
+	return iban.New(accountNumberCountryCode, accountNumberBankCode+digits), nil
 }
 
-func (s *Service) BlockAccount(ctx context.Context, accountId, userId int64) error {
+func (s *Service) BlockAccount(ctx context.Context, accountId, userId int64) (err error) {
+	audit := s.startAudit(ctx, AuditActionAccountBlock, accountId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
 	accountInfo, err := s.accountStorage.GetAccountDataById(ctx, accountId)
 	if err != nil {
 		return err
 	}
+	audit.before = auditValues{"status": accountInfo.Status}
 	if accountInfo.UserId != userId {
 		return cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
 	}
-	return s.accountStorage.BlockUserAccount(ctx, accountId)
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		audit.after = auditValues{"status": "BLOCKED"}
+		return s.accountStorage.BlockUserAccount(ctx, accountId)
+	})
 }
 
-func (s *Service) GetAccountHistory(ctx context.Context, accountId, userId, limit, offset int64) ([]AccountTransactionsData, int64, error) {
+func (s *Service) GetAccountHistory(ctx context.Context, accountId, userId, limit, offset int64) (UserAccountData, []AccountTransactionsData, int64, error) {
 	accountInfo, err := s.accountStorage.GetAccountDataById(ctx, accountId)
 	if err != nil {
-		return []AccountTransactionsData{}, 0, err
+		return UserAccountData{}, []AccountTransactionsData{}, 0, err
 	}
 	if accountInfo.UserId != userId {
-		return []AccountTransactionsData{}, 0, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+		return UserAccountData{}, []AccountTransactionsData{}, 0, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+	}
+
+	transactions, total, err := s.accountStorage.GetAccountHistory(ctx, accountId, limit, offset)
+	if err != nil {
+		return UserAccountData{}, []AccountTransactionsData{}, 0, err
+	}
+	if err = s.labelBeneficiaries(ctx, userId, accountId, transactions); err != nil {
+		return UserAccountData{}, []AccountTransactionsData{}, 0, err
 	}
+	return accountInfo, transactions, total, nil
+}
 
-	return s.accountStorage.GetAccountHistory(ctx, accountId, limit, offset)
+func (s *Service) MakeTransaction(ctx context.Context, senderId, receiverId, amountCents, userId int64, description string) (int64, error) {
+	return s.makeTransaction(ctx, senderId, receiverId, amountCents, userId, description, true, false)
 }
 
-func (s *Service) MakeTransaction(ctx context.Context, senderId, receiverId, amountCents, userId int64, description string) error {
+func (s *Service) makeTransaction(ctx context.Context, senderId, receiverId, amountCents, userId int64, description string, reviewHold, bulk bool) (int64, error) {
+	if senderId == receiverId {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.SameAccount, nil, "Нельзя перевести деньги на тот же счёт")
+	}
+
 	senderAccountData, err := s.accountStorage.GetAccountDataById(ctx, senderId)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if senderAccountData.Status == "BLOCKED" {
-		return cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт отправителя заблокирован")
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт отправителя заблокирован")
 	}
-	if senderAccountData.BalanceCents < amountCents {
-		return cerrors.NewErrorWithUserMessage(ercodes.NotEnoughMoney, nil, "Недостаточно средств")
+	if senderAccountData.AvailableCents < amountCents {
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.NotEnoughMoney, nil, "Недостаточно средств")
 	}
 	if userId != 0 && senderAccountData.UserId != userId {
-		return cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.AccessDenied, nil, "Ошибка доступа")
 	}
 
 	receiverAccountData, err := s.accountStorage.GetAccountDataById(ctx, receiverId)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if receiverAccountData.Status == "BLOCKED" {
-		return cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт получателя заблокирован")
+		return 0, cerrors.NewErrorWithUserMessage(ercodes.BlockedAccount, nil, "Счёт получателя заблокирован")
 	}
 
-	return s.transactionStorage.CreateTransaction(ctx, senderId, receiverId, amountCents, description)
+	if err = s.checkKycOutgoing(ctx, senderAccountData, amountCents, amountCents); err != nil {
+		return 0, err
+	}
+	if err = checkKycIncoming(receiverAccountData, amountCents); err != nil {
+		return 0, err
+	}
+	if err = s.checkBeneficiaryCoolingOff(ctx, userId, receiverId, amountCents); err != nil {
+		return 0, err
+	}
+
+	hits, err := s.screenAccounts(ctx, ScreeningOperationTransfer, senderAccountData, receiverAccountData)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.createScoredTransaction(ctx, senderId, receiverId, amountCents, userId, description, hits, reviewHold, bulk)
 }
 
-func (s *Service) ATMSupplement(ctx context.Context, login, password string, amountCents int64) error {
-	_, err := s.changeATMState(ctx, login, password, amountCents, 0)
-	return err
+func (s *Service) ATMSupplement(ctx context.Context, atmId int64, banknotes []AtmBanknotesData) (err error) {
+	audit := s.startAudit(ctx, AuditActionAtmSupplement, atmId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	atmData, err := s.getATM(ctx, atmId)
+	if err != nil {
+		return err
+	}
+	audit.entry.ActorId = atmData.Login
+	audit.before = auditValues{"cashCents": atmData.CashCents}
+
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		amountCents, err := s.supplyATM(ctx, atmData, banknotes)
+		if err != nil {
+			return err
+		}
+		if err = s.atmStorage.LogCashOperation(ctx, atmData.Id, amountCents, 0); err != nil {
+			return err
+		}
+		audit.after = auditValues{"cashCents": atmData.CashCents + amountCents, "amountCents": amountCents}
+		return nil
+	})
 }
 
-func (s *Service) ATMWithdrawal(ctx context.Context, login, password string, amountCents int64) error {
-	_, err := s.changeATMState(ctx, login, password, -amountCents, 0)
-	return err
+func (s *Service) ATMWithdrawal(ctx context.Context, atmId int64, amountCents int64) (_ []AtmBanknotesData, err error) {
+	audit := s.startAudit(ctx, AuditActionAtmWithdrawal, atmId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	atmData, err := s.getATM(ctx, atmId)
+	if err != nil {
+		return nil, err
+	}
+	audit.entry.ActorId = atmData.Login
+	audit.before = auditValues{"cashCents": atmData.CashCents}
+
+	var banknotes []AtmBanknotesData
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		banknotes, err = s.dispenseATM(ctx, atmData, amountCents)
+		if err != nil {
+			return err
+		}
+		if err = s.atmStorage.LogCashOperation(ctx, atmData.Id, -amountCents, 0); err != nil {
+			return err
+		}
+		audit.after = auditValues{"cashCents": atmData.CashCents - amountCents, "amountCents": amountCents}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return banknotes, nil
 }
 
-func (s *Service) ATMUserSupplement(ctx context.Context, login, password string, amountCents, accountId, userId int64) error {
-	atmAccountId, err := s.changeATMState(ctx, login, password, amountCents, accountId)
+func (s *Service) ATMUserSupplement(ctx context.Context, atmId int64, banknotes []AtmBanknotesData, cardNumber, pin string) (err error) {
+	audit := s.startAudit(ctx, AuditActionAtmUserSupplement, atmId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	atmData, err := s.getATM(ctx, atmId)
+	if err != nil {
+		return err
+	}
+	audit.entry.ActorId = atmData.Login
+	audit.before = auditValues{"cashCents": atmData.CashCents}
+	cardData, err := s.verifyCardPin(ctx, cardNumber, pin)
 	if err != nil {
 		return err
 	}
-	return s.MakeTransaction(ctx, atmAccountId, accountId, amountCents, userId, "Пополнение счёта")
+	if err = s.checkAtmScreening(ctx, ScreeningOperationAtmSupplement, cardData.AccountId); err != nil {
+		return err
+	}
+
+	return s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		amountCents, err := s.supplyATM(ctx, atmData, banknotes)
+		if err != nil {
+			return err
+		}
+		if _, err = s.makeTransaction(ctx, atmData.AccountId, cardData.AccountId, amountCents, 0, "Пополнение счёта", false, false); err != nil {
+			return err
+		}
+		if err = s.atmStorage.LogCashOperation(ctx, atmData.Id, amountCents, cardData.AccountId); err != nil {
+			return err
+		}
+		audit.after = auditValues{"cashCents": atmData.CashCents + amountCents, "amountCents": amountCents, "accountId": cardData.AccountId}
+		return nil
+	})
 }
 
-func (s *Service) ATMUserWithdrawal(ctx context.Context, login, password string, amountCents, accountId, userId int64) error {
-	atmAccountId, err := s.changeATMState(ctx, login, password, -amountCents, accountId)
+func (s *Service) ATMUserWithdrawal(ctx context.Context, atmId int64, amountCents int64, cardNumber, pin string) (_ []AtmBanknotesData, err error) {
+	audit := s.startAudit(ctx, AuditActionAtmUserWithdrawal, atmId)
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
+	atmData, err := s.getATM(ctx, atmId)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	audit.entry.ActorId = atmData.Login
+	audit.before = auditValues{"cashCents": atmData.CashCents}
+	cardData, err := s.verifyCardPin(ctx, cardNumber, pin)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkAtmScreening(ctx, ScreeningOperationAtmWithdrawal, cardData.AccountId); err != nil {
+		return nil, err
+	}
+
+	var banknotes []AtmBanknotesData
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		_, err = s.chargeCard(ctx, cardData, atmData.AccountId, amountCents, CardOperationAtmWithdrawal, "Снятие наличных")
+		if err != nil {
+			return err
+		}
+		banknotes, err = s.dispenseATM(ctx, atmData, amountCents)
+		if err != nil {
+			return err
+		}
+		if err = s.atmStorage.LogCashOperation(ctx, atmData.Id, -amountCents, cardData.AccountId); err != nil {
+			return err
+		}
+		audit.after = auditValues{"cashCents": atmData.CashCents - amountCents, "amountCents": amountCents, "accountId": cardData.AccountId}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return s.MakeTransaction(ctx, atmAccountId, accountId, -amountCents, userId, "Снятие денег со счёта")
+	return banknotes, nil
 }
 
-func (s *Service) changeATMState(ctx context.Context, login, password string, amountCents, userAccountId int64) (int64, error) {
+func (s *Service) ATMLogin(ctx context.Context, login, password string) (_ ATMSessionData, err error) {
+	audit := s.startAudit(ctx, AuditActionAtmLogin, 0)
+	audit.entry.ActorType = AuditActorAtm
+	audit.entry.ActorId = login
+	defer func() { err = s.finishAudit(ctx, audit, err) }()
+
 	atmData, err := s.atmStorage.GetAtmDataByLogin(ctx, login)
 	if err != nil {
-		return 0, err
+		if hasErrorCode(err, ercodes.AtmNotFound) {
+			return ATMSessionData{}, cerrors.NewErrorWithUserMessage(ercodes.WrongPassword, err, "Неверный логин или пароль")
+		}
+		return ATMSessionData{}, err
+	}
+	audit.entry.EntityId = atmData.Id
+	if atmData.IsLocked {
+		return ATMSessionData{}, cerrors.NewErrorWithUserMessage(ercodes.AtmLocked, nil, "Банкомат временно заблокирован из-за неудачных попыток входа")
+	}
+	if atmData.Status == AtmStatusDisabled {
+		return ATMSessionData{}, cerrors.NewErrorWithUserMessage(ercodes.AtmDisabled, nil, "Банкомат отключён")
 	}
 
 	if err = s.passwordHasher.CompareHashAndPassword(ctx, password, atmData.PasswordHash); err != nil {
-		return 0, err
+		if hasErrorCode(err, ercodes.WrongPassword) {
+			if failureErr := s.atmStorage.RegisterAtmLoginFailure(ctx, atmData.Id, atmMaxLoginAttempts, atmLockDuration); failureErr != nil {
+				return ATMSessionData{}, failureErr
+			}
+		}
+		return ATMSessionData{}, err
 	}
 
-	if err = s.atmStorage.UpdateAtmCash(ctx, amountCents, atmData.Id); err != nil {
-		return 0, err
+	sessionId, err := s.randomGenerator.GenerateString(ctx, atmSessionIdCharset, atmSessionIdLength)
+	if err != nil {
+		return ATMSessionData{}, err
+	}
+	err = s.withinAuditedTransaction(ctx, audit, func(ctx context.Context) error {
+		return s.atmStorage.ResetAtmLoginFailures(ctx, atmData.Id)
+	})
+	if err != nil {
+		return ATMSessionData{}, err
+	}
+	return ATMSessionData{
+		Id:    sessionId,
+		AtmId: atmData.Id,
+	}, nil
+}
+
+func (s *Service) getATM(ctx context.Context, atmId int64) (AtmData, error) {
+	atmData, err := s.atmStorage.GetAtmDataById(ctx, atmId)
+	if err != nil {
+		return AtmData{}, err
+	}
+	if atmData.IsLocked {
+		return AtmData{}, cerrors.NewErrorWithUserMessage(ercodes.AtmLocked, nil, "Банкомат временно заблокирован")
+	}
+	if atmData.Status == AtmStatusDisabled {
+		return AtmData{}, cerrors.NewErrorWithUserMessage(ercodes.AtmDisabled, nil, "Банкомат отключён")
+	}
+	return atmData, nil
+}
+
+func (s *Service) changeATMState(ctx context.Context, atmData AtmData, amountCents int64) error {
+	cashCents, err := s.atmStorage.UpdateAtmCash(ctx, amountCents, atmData.Id)
+	if err != nil {
+		return err
 	}
 	if err = s.accountStorage.UpdateAtmAccount(ctx, amountCents, atmData.AccountId); err != nil {
-		return 0, err
+		return err
 	}
-	if err = s.atmStorage.LogCashOperation(ctx, atmData.Id, amountCents, userAccountId); err != nil {
-		return 0, err
+
+	if cashCents < atmData.LowCashThresholdCents && cashCents-amountCents >= atmData.LowCashThresholdCents {
+		if err = s.atmStorage.CreateAtmAlert(ctx, atmData.Id, AtmAlertLowCash, cashCents); err != nil {
+			return err
+		}
+		log.Printf("atm %d: low cash, %d cents left", atmData.Id, cashCents)
 	}
-	return atmData.AccountId, nil
+	return nil
 }
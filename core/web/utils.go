@@ -0,0 +1,27 @@
+package web
+
+import (
+	"errors"
+	"x-bank-ms-bank/cerrors"
+)
+
+func hasErrorCode(err error, codes ...cerrors.Code) bool {
+	var cErr *cerrors.Error
+	if !errors.As(err, &cErr) {
+		return false
+	}
+	for _, code := range codes {
+		if cErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func errorUserMessage(err error) string {
+	var cErr *cerrors.Error
+	if errors.As(err, &cErr) && cErr.UserMessage != "" {
+		return cErr.UserMessage
+	}
+	return err.Error()
+}
@@ -15,4 +15,57 @@ const (
 	NotEnoughMoney
 	WrongPassword
 	AccessDenied
+	AliasNotFound
+	AliasAlreadyExists
+	InvalidAlias
+	AccountNotFound
+	InvalidAccountNumber
+	AccountNumberExists
+	PaymentRequestNotFound
+	PaymentRequestNotPending
+	TransactionBatchNotFound
+	PaymentFileNotFound
+	DuplicatePaymentFile
+	InvalidPaymentFile
+	InvalidBanknotes
+	NotEnoughAtmCash
+	AtmCannotDispense
+	AtmNotFound
+	AtmLocked
+	CardNotFound
+	CardNumberExists
+	CardBlocked
+	WrongPin
+	AtmDisabled
+	AtmLoginExists
+	CardExpired
+	WrongCardData
+	CardLimitExceeded
+	CardHoldNotFound
+	CardHoldNotActive
+	InvalidCardHoldAmount
+	AccountEventNotFound
+	ES256Authorization
+	EdDSAAuthorization
+	JWKSAuthorization
+	TokenWithoutId
+	StepUpRequired
+	StepUpChallengeNotFound
+	StepUpChallengeNotActive
+	WrongStepUpCode
+	InvalidConfirmationToken
+	StepUpNotification
+	IdentificationRequired
+	ReceiverBalanceLimit
+	BeneficiaryNotFound
+	BeneficiaryAlreadyExists
+	BeneficiaryCoolingOff
+	TransferDenied
+	TransactionReviewNotFound
+	TransactionReviewNotPending
+	ScreeningHit
+	SameAccount
+	AliasVerificationNotFound
+	AliasVerificationNotActive
+	PaymentFileNotAwaitingConfirmation
 )
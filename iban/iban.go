@@ -0,0 +1,63 @@
+package iban
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	minLength = 15
+	maxLength = 34
+)
+
+func New(countryCode, bban string) string {
+	checkDigits := 98 - mod97(bban+countryCode+"00")
+	return countryCode + leftPad(strconv.Itoa(checkDigits), 2) + bban
+}
+
+func Normalize(number string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(number), " ", ""))
+}
+
+func IsValid(number string) bool {
+	if len(number) < minLength || len(number) > maxLength {
+		return false
+	}
+	for i, c := range number {
+		switch {
+		case i < 2 && (c < 'A' || c > 'Z'):
+			return false
+		case i >= 2 && i < 4 && (c < '0' || c > '9'):
+			return false
+		case i >= 4 && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9'):
+			return false
+		}
+	}
+
+	return mod97(number[4:]+number[:4]) == 1
+}
+
+func mod97(value string) int {
+	remainder := 0
+	for _, c := range value {
+		var digits int
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+			continue
+		case c >= 'A' && c <= 'Z':
+			digits = int(c-'A') + 10
+		default:
+			return -1
+		}
+		remainder = (remainder*100 + digits) % 97
+	}
+	return remainder
+}
+
+func leftPad(value string, size int) string {
+	if len(value) >= size {
+		return value
+	}
+	return strings.Repeat("0", size-len(value)) + value
+}
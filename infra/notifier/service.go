@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const requestTimeout = 5 * time.Second
+
+type (
+	Service struct {
+		webhookUrl string
+		client     *http.Client
+	}
+
+	stepUpCodeMessage struct {
+		UserId int64  `json:"userId"`
+		Code   string `json:"code"`
+	}
+
+	aliasCodeMessage struct {
+		AliasType string `json:"aliasType"`
+		Alias     string `json:"alias"`
+		Code      string `json:"code"`
+	}
+)
+
+func NewService(webhookUrl string) Service {
+	return Service{
+		webhookUrl: webhookUrl,
+		client:     &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (s *Service) SendStepUpCode(ctx context.Context, userId int64, code string) error {
+	if s.webhookUrl == "" {
+		log.Printf("step-up code for user %d: %s", userId, code)
+		return nil
+	}
+	return s.send(ctx, stepUpCodeMessage{UserId: userId, Code: code})
+}
+
+func (s *Service) SendAliasVerificationCode(ctx context.Context, aliasType, value, code string) error {
+	if s.webhookUrl == "" {
+		log.Printf("alias verification code for %s %s: %s", aliasType, value, code)
+		return nil
+	}
+	return s.send(ctx, aliasCodeMessage{AliasType: aliasType, Alias: value, Code: code})
+}
+
+func (s *Service) send(ctx context.Context, message any) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return cerrors.NewErrorWithUserMessage(ercodes.StepUpNotification, err, "Не удалось отправить код подтверждения")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return cerrors.NewErrorWithUserMessage(ercodes.StepUpNotification, err, "Не удалось отправить код подтверждения")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return cerrors.NewErrorWithUserMessage(ercodes.StepUpNotification, err, "Не удалось отправить код подтверждения")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return cerrors.NewErrorWithUserMessage(ercodes.StepUpNotification, fmt.Errorf("webhook responded %s", resp.Status), "Не удалось отправить код подтверждения")
+	}
+	return nil
+}
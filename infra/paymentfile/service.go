@@ -0,0 +1,204 @@
+package paymentfile
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+type (
+	Service struct {
+		currency string
+	}
+
+	pain001Document struct {
+		XMLName    xml.Name `xml:"Document"`
+		Initiation struct {
+			GroupHeader struct {
+				MessageId            string `xml:"MsgId"`
+				NumberOfTransactions string `xml:"NbOfTxs"`
+				ControlSum           string `xml:"CtrlSum"`
+			} `xml:"GrpHdr"`
+			PaymentInformation []struct {
+				DebtorAccount pain001Account `xml:"DbtrAcct"`
+				Transactions  []struct {
+					EndToEndId string `xml:"PmtId>EndToEndId"`
+					Amount     struct {
+						Value    string `xml:",chardata"`
+						Currency string `xml:"Ccy,attr"`
+					} `xml:"Amt>InstdAmt"`
+					CreditorAccount pain001Account `xml:"CdtrAcct"`
+					Unstructured    []string       `xml:"RmtInf>Ustrd"`
+				} `xml:"CdtTrfTxInf"`
+			} `xml:"PmtInf"`
+		} `xml:"CstmrCdtTrfInitn"`
+	}
+
+	pain001Account struct {
+		IBAN    string `xml:"Id>IBAN"`
+		OtherId string `xml:"Id>Othr>Id"`
+	}
+)
+
+var requiredCSVColumns = []string{"receiver", "amount"}
+
+func NewService(currency string) Service {
+	return Service{
+		currency: currency,
+	}
+}
+
+func (s *Service) ParsePaymentFile(_ context.Context, format string, data []byte) ([]web.PaymentFileItem, error) {
+	switch format {
+	case web.PaymentFileFormatCSV:
+		return s.parseCSV(data)
+	case web.PaymentFileFormatPain001:
+		return s.parsePain001(data)
+	default:
+		return nil, cerrors.NewErrorWithUserMessage(ercodes.InvalidPaymentFile, nil, "Неподдерживаемый формат файла")
+	}
+}
+
+func (s *Service) parseCSV(data []byte) ([]web.PaymentFileItem, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	if firstLine, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, cerrors.NewErrorWithUserMessage(ercodes.InvalidPaymentFile, err, "Не удалось прочитать заголовок CSV")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredCSVColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, cerrors.NewErrorWithUserMessage(ercodes.InvalidPaymentFile, nil, fmt.Sprintf("В CSV отсутствует колонка %q", name))
+		}
+	}
+
+	var items []web.PaymentFileItem
+	for lineNo := 2; ; lineNo++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, cerrors.NewErrorWithUserMessage(ercodes.InvalidPaymentFile, err, fmt.Sprintf("Ошибка разбора CSV в строке %d", lineNo))
+		}
+
+		column := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		item := web.PaymentFileItem{
+			LineNo:      lineNo,
+			Receiver:    column("receiver"),
+			Description: column("description"),
+		}
+		if item.Receiver == "" {
+			item.Error = "Не указан получатель"
+		}
+		if item.AmountCents, err = parseAmountCents(column("amount")); err != nil && item.Error == "" {
+			item.Error = "Неверная сумма платежа"
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (s *Service) parsePain001(data []byte) ([]web.PaymentFileItem, error) {
+	var document pain001Document
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, cerrors.NewErrorWithUserMessage(ercodes.InvalidPaymentFile, err, "Файл не является документом pain.001")
+	}
+
+	var (
+		items      []web.PaymentFileItem
+		totalCents int64
+	)
+	for _, paymentInformation := range document.Initiation.PaymentInformation {
+		debtorAccount := paymentInformation.DebtorAccount.IBAN
+		for _, transaction := range paymentInformation.Transactions {
+			item := web.PaymentFileItem{
+				LineNo:        len(items) + 1,
+				Receiver:      strings.TrimSpace(transaction.CreditorAccount.IBAN),
+				Description:   strings.TrimSpace(strings.Join(transaction.Unstructured, " ")),
+				DebtorAccount: debtorAccount,
+			}
+			if item.Receiver == "" {
+				item.Receiver = strings.TrimSpace(transaction.CreditorAccount.OtherId)
+			}
+			if item.Description == "" {
+				item.Description = transaction.EndToEndId
+			}
+
+			var err error
+			switch {
+			case item.Receiver == "":
+				item.Error = "Не указан счёт получателя"
+			case transaction.Amount.Currency != "" && transaction.Amount.Currency != s.currency:
+				item.Error = fmt.Sprintf("Неподдерживаемая валюта %s", transaction.Amount.Currency)
+			}
+			if item.AmountCents, err = parseAmountCents(transaction.Amount.Value); err != nil && item.Error == "" {
+				item.Error = "Неверная сумма платежа"
+			}
+			totalCents += item.AmountCents
+			items = append(items, item)
+		}
+	}
+
+	header := document.Initiation.GroupHeader
+	if header.NumberOfTransactions != "" && header.NumberOfTransactions != strconv.Itoa(len(items)) {
+		return nil, cerrors.NewErrorWithUserMessage(ercodes.InvalidPaymentFile, nil, "Количество платежей не совпадает с NbOfTxs")
+	}
+	if header.ControlSum != "" {
+		controlSumCents, err := parseAmountCents(header.ControlSum)
+		if err != nil || controlSumCents != totalCents {
+			return nil, cerrors.NewErrorWithUserMessage(ercodes.InvalidPaymentFile, err, "Сумма платежей не совпадает с CtrlSum")
+		}
+	}
+
+	return items, nil
+}
+
+func parseAmountCents(value string) (int64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	units, fraction, _ := strings.Cut(value, ".")
+	if units == "" || len(fraction) > 2 {
+		return 0, errors.New("неверный формат суммы")
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	unitsValue, err := strconv.ParseInt(units, 10, 64)
+	if err != nil || unitsValue < 0 || unitsValue > (math.MaxInt64-99)/100 {
+		return 0, errors.New("неверный формат суммы")
+	}
+	fractionValue, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil || fractionValue < 0 {
+		return 0, errors.New("неверный формат суммы")
+	}
+
+	return unitsValue*100 + fractionValue, nil
+}
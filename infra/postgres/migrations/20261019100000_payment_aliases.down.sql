@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "paymentAliases";
+
+DROP TYPE IF EXISTS alias_type;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TYPE alias_type AS ENUM ('PHONE', 'EMAIL');
+
+CREATE TABLE "paymentAliases"
+(
+    "id"        BIGSERIAL PRIMARY KEY,
+    "accountId" BIGINT              NOT NULL REFERENCES "accounts" ("id"),
+    "type"      alias_type          NOT NULL,
+    "value"     VARCHAR(254) UNIQUE NOT NULL,
+    "ownerName" VARCHAR(128)        NOT NULL,
+    "createdAt" TIMESTAMP           NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "paymentAliases_accountId_index" ON "paymentAliases" ("accountId");
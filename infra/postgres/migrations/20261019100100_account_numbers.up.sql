@@ -0,0 +1,9 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "number" VARCHAR(34) UNIQUE;
+
+UPDATE "accounts"
+SET "number" = 'XB' || lpad((98 - ('0001' || lpad("id"::text, 16, '0') || '331100')::numeric % 97)::text, 2, '0') ||
+               '0001' || lpad("id"::text, 16, '0');
+
+ALTER TABLE "accounts"
+    ALTER COLUMN "number" SET NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "paymentRequests";
+
+DROP TYPE IF EXISTS status_payment_request;
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TYPE status_payment_request AS ENUM ('PENDING', 'ACCEPTED', 'DECLINED');
+
+CREATE TABLE "paymentRequests"
+(
+    "id"                 BIGSERIAL              NOT NULL PRIMARY KEY,
+    "requesterAccountId" BIGINT                 NOT NULL REFERENCES "accounts" ("id"),
+    "payerAccountId"     BIGINT                 NOT NULL REFERENCES "accounts" ("id"),
+    "amountCents"        BIGINT                 NOT NULL CHECK ( "amountCents" > 0 ),
+    "description"        TEXT                   NOT NULL DEFAULT '',
+    "status"             status_payment_request NOT NULL DEFAULT 'PENDING',
+    "expiresAt"          TIMESTAMP              NOT NULL,
+    "createdAt"          TIMESTAMP              NOT NULL DEFAULT current_timestamp,
+    "transactionId"      BIGINT REFERENCES "transactions" ("id"),
+    CHECK ( "requesterAccountId" != "payerAccountId" )
+);
+
+CREATE INDEX "paymentRequests_requesterAccountId_index" ON "paymentRequests" ("requesterAccountId");
+CREATE INDEX "paymentRequests_payerAccountId_index" ON "paymentRequests" ("payerAccountId");
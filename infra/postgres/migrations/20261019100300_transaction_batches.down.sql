@@ -0,0 +1,4 @@
+ALTER TABLE "transactions"
+    DROP COLUMN IF EXISTS "batchId";
+
+DROP TABLE IF EXISTS "transactionBatches";
\ No newline at end of file
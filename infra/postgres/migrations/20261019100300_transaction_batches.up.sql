@@ -0,0 +1,13 @@
+CREATE TABLE "transactionBatches"
+(
+    "id"          BIGSERIAL NOT NULL PRIMARY KEY,
+    "senderId"    BIGINT    NOT NULL REFERENCES "accounts" ("id"),
+    "itemsCount"  INT       NOT NULL CHECK ( "itemsCount" > 0 ),
+    "totalCents"  BIGINT    NOT NULL CHECK ( "totalCents" > 0 ),
+    "createdAt"   TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+ALTER TABLE "transactions"
+    ADD COLUMN "batchId" BIGINT REFERENCES "transactionBatches" ("id");
+
+CREATE INDEX "transactions_batchId_index" ON "transactions" ("batchId");
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "paymentFileItems";
+DROP TABLE IF EXISTS "paymentFiles";
+
+DROP TYPE IF EXISTS status_payment_file_item;
+DROP TYPE IF EXISTS status_payment_file;
\ No newline at end of file
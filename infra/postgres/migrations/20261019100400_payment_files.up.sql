@@ -0,0 +1,33 @@
+CREATE TYPE status_payment_file AS ENUM ('REJECTED', 'PROCESSING', 'COMPLETED', 'COMPLETED_WITH_ERRORS');
+CREATE TYPE status_payment_file_item AS ENUM ('INVALID', 'PENDING', 'PROCESSING', 'COMPLETED', 'FAILED');
+
+CREATE TABLE "paymentFiles"
+(
+    "id"         BIGSERIAL           NOT NULL PRIMARY KEY,
+    "senderId"   BIGINT              NOT NULL REFERENCES "accounts" ("id"),
+    "userId"     BIGINT              NOT NULL,
+    "format"     VARCHAR(16)         NOT NULL,
+    "hash"       CHAR(64)            NOT NULL,
+    "status"     status_payment_file NOT NULL,
+    "itemsCount" INT                 NOT NULL,
+    "totalCents" BIGINT              NOT NULL,
+    "createdAt"  TIMESTAMP           NOT NULL DEFAULT current_timestamp,
+    "updatedAt"  TIMESTAMP           NOT NULL DEFAULT current_timestamp,
+    UNIQUE ("senderId", "hash")
+);
+
+CREATE TABLE "paymentFileItems"
+(
+    "id"            BIGSERIAL                NOT NULL PRIMARY KEY,
+    "fileId"        BIGINT                   NOT NULL REFERENCES "paymentFiles" ("id"),
+    "lineNo"        INT                      NOT NULL,
+    "receiver"      TEXT                     NOT NULL,
+    "receiverId"    BIGINT REFERENCES "accounts" ("id"),
+    "amountCents"   BIGINT                   NOT NULL,
+    "description"   TEXT                     NOT NULL DEFAULT '',
+    "status"        status_payment_file_item NOT NULL,
+    "error"         TEXT                     NOT NULL DEFAULT '',
+    "transactionId" BIGINT REFERENCES "transactions" ("id")
+);
+
+CREATE INDEX "paymentFileItems_fileId_index" ON "paymentFileItems" ("fileId");
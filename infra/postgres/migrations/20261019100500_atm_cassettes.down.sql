@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "atmAlerts";
+DROP TABLE IF EXISTS "atmCassettes";
+
+ALTER TABLE "atms"
+    DROP COLUMN IF EXISTS "lowCashThresholdCents";
+
+DROP TYPE IF EXISTS atm_alert_type;
\ No newline at end of file
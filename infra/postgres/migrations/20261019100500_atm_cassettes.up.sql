@@ -0,0 +1,39 @@
+CREATE TYPE atm_alert_type AS ENUM ('LOW_CASH');
+
+ALTER TABLE "atms"
+    ADD COLUMN "lowCashThresholdCents" BIGINT NOT NULL DEFAULT 1000000 CHECK ( "lowCashThresholdCents" >= 0 );
+
+CREATE TABLE "atmCassettes"
+(
+    "id"                BIGSERIAL NOT NULL PRIMARY KEY,
+    "atmId"             BIGINT    NOT NULL REFERENCES "atms" ("id"),
+    "denominationCents" BIGINT    NOT NULL CHECK ( "denominationCents" > 0 ),
+    "count"             BIGINT    NOT NULL CHECK ( "count" >= 0 ) DEFAULT 0,
+    UNIQUE ("atmId", "denominationCents")
+);
+
+CREATE TABLE "atmAlerts"
+(
+    "id"        BIGSERIAL      NOT NULL PRIMARY KEY,
+    "atmId"     BIGINT         NOT NULL REFERENCES "atms" ("id"),
+    "type"      atm_alert_type NOT NULL,
+    "cashCents" BIGINT         NOT NULL,
+    "createdAt" TIMESTAMP      NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "atmAlerts_atmId_index" ON "atmAlerts" ("atmId");
+
+INSERT INTO "atmCassettes" ("atmId", "denominationCents", "count")
+SELECT "id", 500000, "cashCents" / 500000
+FROM "atms"
+WHERE "cashCents" >= 500000;
+
+INSERT INTO "atmCassettes" ("atmId", "denominationCents", "count")
+SELECT "id", 100000, "cashCents" % 500000 / 100000
+FROM "atms"
+WHERE "cashCents" % 500000 >= 100000;
+
+INSERT INTO "atmCassettes" ("atmId", "denominationCents", "count")
+SELECT "id", 10000, "cashCents" % 100000 / 10000
+FROM "atms"
+WHERE "cashCents" % 100000 >= 10000;
@@ -0,0 +1,3 @@
+ALTER TABLE "atms"
+    DROP COLUMN IF EXISTS "lockedUntil",
+    DROP COLUMN IF EXISTS "failedLoginAttempts";
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "atms"
+    ADD COLUMN "failedLoginAttempts" INT NOT NULL DEFAULT 0 CHECK ( "failedLoginAttempts" >= 0 ),
+    ADD COLUMN "lockedUntil"         TIMESTAMP;
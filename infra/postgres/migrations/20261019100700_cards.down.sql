@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "cards";
+
+DROP TYPE IF EXISTS status_card;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TYPE status_card AS ENUM ('ACTIVE', 'BLOCKED');
+
+CREATE TABLE "cards"
+(
+    "id"        BIGSERIAL   NOT NULL PRIMARY KEY,
+    "accountId" BIGINT      NOT NULL REFERENCES "accounts" ("id"),
+    "number"    VARCHAR(19) NOT NULL UNIQUE CHECK ( "number" ~ '^[0-9]{12,19}$' ),
+    "pinHash"   BYTEA       NOT NULL CHECK ( length("pinHash") <= 60 ),
+    "pinTries"  INT         NOT NULL DEFAULT 0 CHECK ( "pinTries" >= 0 ),
+    "status"    status_card NOT NULL DEFAULT 'ACTIVE',
+    "createdAt" TIMESTAMP   NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "cards_accountId_index" ON "cards" ("accountId");
@@ -0,0 +1,5 @@
+ALTER TABLE "atms"
+    DROP COLUMN IF EXISTS "createdAt",
+    DROP COLUMN IF EXISTS "status";
+
+DROP TYPE IF EXISTS status_atm;
\ No newline at end of file
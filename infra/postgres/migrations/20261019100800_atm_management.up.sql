@@ -0,0 +1,5 @@
+CREATE TYPE status_atm AS ENUM ('ACTIVE', 'DISABLED');
+
+ALTER TABLE "atms"
+    ADD COLUMN "status"    status_atm NOT NULL DEFAULT 'ACTIVE',
+    ADD COLUMN "createdAt" TIMESTAMP  NOT NULL DEFAULT current_timestamp;
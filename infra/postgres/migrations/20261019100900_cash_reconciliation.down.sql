@@ -0,0 +1,8 @@
+ALTER TABLE "transactions"
+    DROP COLUMN IF EXISTS "confirmedAt";
+
+DROP INDEX IF EXISTS "cashOperations_atmAccountId_createdAt_index";
+
+ALTER TABLE "cashOperations"
+    DROP COLUMN IF EXISTS "balanceAfterCents",
+    DROP COLUMN IF EXISTS "cashAfterCents";
\ No newline at end of file
@@ -0,0 +1,12 @@
+ALTER TABLE "cashOperations"
+    ADD COLUMN "cashAfterCents"    BIGINT,
+    ADD COLUMN "balanceAfterCents" BIGINT;
+
+CREATE INDEX "cashOperations_atmAccountId_createdAt_index" ON "cashOperations" ("atmAccountId", "createdAt");
+
+ALTER TABLE "transactions"
+    ADD COLUMN "confirmedAt" TIMESTAMP;
+
+UPDATE "transactions"
+SET "confirmedAt" = "createdAt"
+WHERE "status" = 'CONFIRMED';
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "cardOperations";
+
+ALTER TABLE "cards"
+    DROP COLUMN IF EXISTS "dailyLimitCents",
+    DROP COLUMN IF EXISTS "perTransactionLimitCents",
+    DROP COLUMN IF EXISTS "cvvHash",
+    DROP COLUMN IF EXISTS "expiresAt";
+
+DROP TYPE IF EXISTS card_operation_type;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TYPE card_operation_type AS ENUM ('PURCHASE', 'ATM_WITHDRAWAL');
+
+ALTER TABLE "cards"
+    ADD COLUMN "expiresAt"                DATE   NOT NULL DEFAULT (date_trunc('month', current_date) + INTERVAL '4 years 1 month - 1 day')::DATE,
+    ADD COLUMN "cvvHash"                  BYTEA CHECK ( length("cvvHash") <= 60 ),
+    ADD COLUMN "perTransactionLimitCents" BIGINT CHECK ( "perTransactionLimitCents" > 0 ),
+    ADD COLUMN "dailyLimitCents"          BIGINT CHECK ( "dailyLimitCents" > 0 );
+
+CREATE TABLE "cardOperations"
+(
+    "id"            BIGSERIAL           NOT NULL PRIMARY KEY,
+    "cardId"        BIGINT              NOT NULL REFERENCES "cards" ("id"),
+    "type"          card_operation_type NOT NULL,
+    "receiverId"    BIGINT              NOT NULL REFERENCES "accounts" ("id"),
+    "transactionId" BIGINT              NOT NULL REFERENCES "transactions" ("id"),
+    "amountCents"   BIGINT              NOT NULL CHECK ( "amountCents" > 0 ),
+    "description"   TEXT                NOT NULL DEFAULT '',
+    "createdAt"     TIMESTAMP           NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "cardOperations_cardId_createdAt_index" ON "cardOperations" ("cardId", "createdAt");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "cardHolds";
+
+DROP TYPE IF EXISTS status_card_hold;
\ No newline at end of file
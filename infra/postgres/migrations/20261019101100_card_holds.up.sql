@@ -0,0 +1,20 @@
+CREATE TYPE status_card_hold AS ENUM ('ACTIVE', 'CAPTURED', 'RELEASED', 'EXPIRED');
+
+CREATE TABLE "cardHolds"
+(
+    "id"            BIGSERIAL        NOT NULL PRIMARY KEY,
+    "cardId"        BIGINT           NOT NULL REFERENCES "cards" ("id"),
+    "accountId"     BIGINT           NOT NULL REFERENCES "accounts" ("id"),
+    "receiverId"    BIGINT           NOT NULL REFERENCES "accounts" ("id"),
+    "amountCents"   BIGINT           NOT NULL CHECK ( "amountCents" > 0 ),
+    "capturedCents" BIGINT           NOT NULL DEFAULT 0 CHECK ( "capturedCents" >= 0 AND "capturedCents" <= "amountCents" ),
+    "transactionId" BIGINT REFERENCES "transactions" ("id"),
+    "status"        status_card_hold NOT NULL DEFAULT 'ACTIVE',
+    "description"   TEXT             NOT NULL DEFAULT '',
+    "expiresAt"     TIMESTAMP        NOT NULL,
+    "createdAt"     TIMESTAMP        NOT NULL DEFAULT current_timestamp,
+    "updatedAt"     TIMESTAMP        NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "cardHolds_accountId_active_index" ON "cardHolds" ("accountId") WHERE "status" = 'ACTIVE';
+CREATE INDEX "cardHolds_cardId_active_index" ON "cardHolds" ("cardId") WHERE "status" = 'ACTIVE';
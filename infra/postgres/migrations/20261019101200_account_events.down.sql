@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "accountEvents";
+
+DROP TYPE IF EXISTS account_event_type;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TYPE account_event_type AS ENUM ('TRANSACTION_CREATED', 'TRANSACTION_CONFIRMED', 'BALANCE_CHANGED');
+
+CREATE TABLE "accountEvents"
+(
+    "id"            BIGSERIAL          NOT NULL PRIMARY KEY,
+    "accountId"     BIGINT             NOT NULL REFERENCES "accounts" ("id"),
+    "userId"        BIGINT,
+    "type"          account_event_type NOT NULL,
+    "transactionId" BIGINT REFERENCES "transactions" ("id"),
+    "amountCents"   BIGINT             NOT NULL,
+    "balanceCents"  BIGINT             NOT NULL,
+    "createdAt"     TIMESTAMP          NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "accountEvents_userId_id_index" ON "accountEvents" ("userId", "id");
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "revokedTokens";
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE "revokedTokens"
+(
+    "jti"       VARCHAR   NOT NULL PRIMARY KEY,
+    "expiresAt" TIMESTAMP NOT NULL,
+    "revokedAt" TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "revokedTokens_expiresAt_index" ON "revokedTokens" ("expiresAt");
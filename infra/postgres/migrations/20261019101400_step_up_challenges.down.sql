@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "transactions_receiverId_senderId_index";
+
+DROP TABLE IF EXISTS "stepUpChallenges";
+
+DROP TYPE IF EXISTS status_step_up;
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TYPE status_step_up AS ENUM ('PENDING', 'CONFIRMED', 'USED', 'FAILED');
+
+CREATE TABLE "stepUpChallenges"
+(
+    "id"          BIGSERIAL      NOT NULL PRIMARY KEY,
+    "userId"      BIGINT         NOT NULL,
+    "senderId"    BIGINT         NOT NULL REFERENCES "accounts" ("id"),
+    "receiverId"  BIGINT         NOT NULL REFERENCES "accounts" ("id"),
+    "amountCents" BIGINT         NOT NULL,
+    "description" VARCHAR        NOT NULL DEFAULT '',
+    "codeHash"    BYTEA          NOT NULL,
+    "tokenHash"   BYTEA,
+    "attempts"    INT            NOT NULL DEFAULT 0,
+    "status"      status_step_up NOT NULL DEFAULT 'PENDING',
+    "expiresAt"   TIMESTAMP      NOT NULL,
+    "createdAt"   TIMESTAMP      NOT NULL DEFAULT current_timestamp,
+    "updatedAt"   TIMESTAMP      NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "transactions_receiverId_senderId_index" ON "transactions" ("receiverId", "senderId");
@@ -0,0 +1,2 @@
+ALTER TABLE "accountOwners"
+    DROP COLUMN IF EXISTS "personalDataVerified";
\ No newline at end of file
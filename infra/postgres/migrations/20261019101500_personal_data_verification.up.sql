@@ -0,0 +1,6 @@
+ALTER TABLE "accountOwners"
+    ADD COLUMN "personalDataVerified" BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE "accountOwners"
+SET "personalDataVerified" = true
+WHERE "atmId" IS NOT NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE "beneficiaries"
+(
+    "id"        BIGSERIAL    NOT NULL PRIMARY KEY,
+    "userId"    BIGINT       NOT NULL,
+    "accountId" BIGINT       NOT NULL REFERENCES "accounts" ("id"),
+    "nickname"  VARCHAR(128) NOT NULL,
+    "createdAt" TIMESTAMP    NOT NULL DEFAULT current_timestamp,
+    UNIQUE ("userId", "accountId")
+);
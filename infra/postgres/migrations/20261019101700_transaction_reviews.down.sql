@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS "transactions_senderId_createdAt_index";
+
+DROP TABLE IF EXISTS "transactionReviews";
+
+DROP TYPE IF EXISTS status_transaction_review;
+
+UPDATE transactions SET "status" = 'CANCELLED' WHERE "status" = 'REVIEW';
+ALTER TYPE status_transaction RENAME TO status_transaction_old;
+CREATE TYPE status_transaction AS ENUM ('BLOCKED', 'CONFIRMED', 'CANCELLED');
+ALTER TABLE transactions
+    ALTER COLUMN "status" DROP DEFAULT,
+    ALTER COLUMN "status" TYPE status_transaction USING "status"::TEXT::status_transaction,
+    ALTER COLUMN "status" SET DEFAULT 'BLOCKED';
+DROP TYPE status_transaction_old;
\ No newline at end of file
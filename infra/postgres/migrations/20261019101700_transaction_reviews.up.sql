@@ -0,0 +1,16 @@
+ALTER TYPE status_transaction ADD VALUE 'REVIEW';
+
+CREATE TYPE status_transaction_review AS ENUM ('PENDING', 'APPROVED', 'REJECTED');
+
+CREATE TABLE "transactionReviews"
+(
+    "transactionId" BIGINT                    NOT NULL PRIMARY KEY REFERENCES "transactions" ("id"),
+    "score"         INT                       NOT NULL,
+    "reasons"       VARCHAR                   NOT NULL,
+    "status"        status_transaction_review NOT NULL DEFAULT 'PENDING',
+    "reviewerId"    BIGINT,
+    "createdAt"     TIMESTAMP                 NOT NULL DEFAULT current_timestamp,
+    "reviewedAt"    TIMESTAMP
+);
+
+CREATE INDEX "transactions_senderId_createdAt_index" ON "transactions" ("senderId", "createdAt");
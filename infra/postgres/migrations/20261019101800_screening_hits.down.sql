@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "screeningHits";
+
+DROP TYPE IF EXISTS screening_list_type;
+
+DROP TYPE IF EXISTS screening_operation;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TYPE screening_operation AS ENUM ('TRANSFER', 'ATM_SUPPLEMENT', 'ATM_WITHDRAWAL');
+
+CREATE TYPE screening_list_type AS ENUM ('USER', 'ACCOUNT', 'NAME');
+
+CREATE TABLE "screeningHits"
+(
+    "id"            BIGSERIAL PRIMARY KEY,
+    "operation"     screening_operation NOT NULL,
+    "userId"        BIGINT,
+    "accountId"     BIGINT              NOT NULL REFERENCES "accounts" ("id"),
+    "transactionId" BIGINT REFERENCES "transactions" ("id"),
+    "listType"      screening_list_type NOT NULL,
+    "entry"         VARCHAR             NOT NULL,
+    "createdAt"     TIMESTAMP           NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "screeningHits_accountId_index" ON "screeningHits" ("accountId");
@@ -0,0 +1,40 @@
+CREATE TABLE "auditLog"
+(
+    "id"        BIGSERIAL PRIMARY KEY,
+    "actorType" VARCHAR(16)  NOT NULL,
+    "actorId"   VARCHAR(128) NOT NULL DEFAULT '',
+    "requestId" VARCHAR(128) NOT NULL DEFAULT '',
+    "ip"        VARCHAR(64)  NOT NULL DEFAULT '',
+    "userAgent" VARCHAR      NOT NULL DEFAULT '',
+    "action"    VARCHAR(64)  NOT NULL,
+    "entityId"  BIGINT       NOT NULL DEFAULT 0,
+    "before"    JSON,
+    "after"     JSON,
+    "outcome"   VARCHAR(16)  NOT NULL,
+    "error"     VARCHAR      NOT NULL DEFAULT '',
+    "createdAt" TIMESTAMP    NOT NULL,
+    "prevHash"  VARCHAR(64)  NOT NULL,
+    "hash"      VARCHAR(64)  NOT NULL UNIQUE
+);
+
+CREATE INDEX "auditLog_actorId_index" ON "auditLog" ("actorId");
+CREATE INDEX "auditLog_action_entityId_index" ON "auditLog" ("action", "entityId");
+
+CREATE FUNCTION "auditLog_append_only"() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'auditLog is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "auditLog_append_only"
+    BEFORE UPDATE OR DELETE
+    ON "auditLog"
+    FOR EACH ROW
+EXECUTE FUNCTION "auditLog_append_only"();
+
+CREATE TRIGGER "auditLog_no_truncate"
+    BEFORE TRUNCATE
+    ON "auditLog"
+    FOR EACH STATEMENT
+EXECUTE FUNCTION "auditLog_append_only"();
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "rateLimitBuckets";
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE UNLOGGED TABLE "rateLimitBuckets"
+(
+    "key"           VARCHAR(256)     PRIMARY KEY,
+    "tokens"        DOUBLE PRECISION NOT NULL,
+    "ratePerSecond" DOUBLE PRECISION NOT NULL,
+    "burst"         INTEGER          NOT NULL,
+    "updatedAt"     TIMESTAMP        NOT NULL
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "paymentAliasVerifications";
+
+DROP TYPE IF EXISTS status_alias_verification;
+
+ALTER TABLE "accountOwners"
+    DROP COLUMN IF EXISTS "fullName";
\ No newline at end of file
@@ -0,0 +1,19 @@
+ALTER TABLE "accountOwners"
+    ADD COLUMN "fullName" VARCHAR(128);
+
+CREATE TYPE status_alias_verification AS ENUM ('PENDING', 'CONFIRMED', 'FAILED');
+
+CREATE TABLE "paymentAliasVerifications"
+(
+    "id"        BIGSERIAL                 NOT NULL PRIMARY KEY,
+    "userId"    BIGINT                    NOT NULL,
+    "accountId" BIGINT                    NOT NULL REFERENCES "accounts" ("id"),
+    "type"      alias_type                NOT NULL,
+    "value"     VARCHAR(254)              NOT NULL,
+    "codeHash"  BYTEA                     NOT NULL,
+    "attempts"  INT                       NOT NULL DEFAULT 0,
+    "status"    status_alias_verification NOT NULL DEFAULT 'PENDING',
+    "expiresAt" TIMESTAMP                 NOT NULL,
+    "createdAt" TIMESTAMP                 NOT NULL DEFAULT current_timestamp,
+    "updatedAt" TIMESTAMP                 NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1,15 @@
+UPDATE "paymentFiles" SET "status" = 'REJECTED' WHERE "status" = 'AWAITING_CONFIRMATION';
+ALTER TYPE status_payment_file RENAME TO status_payment_file_old;
+CREATE TYPE status_payment_file AS ENUM ('REJECTED', 'PROCESSING', 'COMPLETED', 'COMPLETED_WITH_ERRORS');
+ALTER TABLE "paymentFiles"
+    ALTER COLUMN "status" TYPE status_payment_file USING "status"::TEXT::status_payment_file;
+DROP TYPE status_payment_file_old;
+
+DELETE FROM "stepUpChallenges" WHERE "operation" <> 'TRANSFER';
+
+ALTER TABLE "stepUpChallenges"
+    DROP COLUMN IF EXISTS "operation",
+    DROP COLUMN IF EXISTS "operationHash",
+    ALTER COLUMN "receiverId" SET NOT NULL;
+
+DROP TYPE IF EXISTS step_up_operation;
\ No newline at end of file
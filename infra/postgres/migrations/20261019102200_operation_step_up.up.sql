@@ -0,0 +1,8 @@
+CREATE TYPE step_up_operation AS ENUM ('TRANSFER', 'BATCH', 'PAYMENT_FILE');
+
+ALTER TABLE "stepUpChallenges"
+    ADD COLUMN "operation"     step_up_operation NOT NULL DEFAULT 'TRANSFER',
+    ADD COLUMN "operationHash" VARCHAR(64)       NOT NULL DEFAULT '',
+    ALTER COLUMN "receiverId" DROP NOT NULL;
+
+ALTER TYPE status_payment_file ADD VALUE 'AWAITING_CONFIRMATION';
@@ -23,7 +23,8 @@ CREATE TABLE "accounts"
     "id"           BIGSERIAL PRIMARY KEY,
     "balanceCents" BIGINT         NOT NULL CHECK ( "balanceCents" >= 0 ) DEFAULT 0,
     "ownerId"      BIGINT         NOT NULL REFERENCES "accountOwners" ("id"),
-    "status"       status_account NOT NULL                               DEFAULT 'ACTIVE'
+    "status"       status_account NOT NULL                               DEFAULT 'ACTIVE',
+    "number"       VARCHAR(34)    UNIQUE NOT NULL
 );
 
 CREATE TABLE "transactions"
@@ -50,27 +51,400 @@ CREATE INDEX "transactions_senderId_index" ON "transactions" ("senderId");
 CREATE INDEX "transactions_receiverId_index" ON "transactions" ("receiverId");
 CREATE INDEX "accounts_ownerId_index" ON "accounts" ("ownerId");
 
+CREATE TYPE alias_type AS ENUM ('PHONE', 'EMAIL');
+
+CREATE TABLE "paymentAliases"
+(
+    "id"        BIGSERIAL PRIMARY KEY,
+    "accountId" BIGINT              NOT NULL REFERENCES "accounts" ("id"),
+    "type"      alias_type          NOT NULL,
+    "value"     VARCHAR(254) UNIQUE NOT NULL,
+    "ownerName" VARCHAR(128)        NOT NULL,
+    "createdAt" TIMESTAMP           NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "paymentAliases_accountId_index" ON "paymentAliases" ("accountId");
+
+CREATE TYPE status_payment_request AS ENUM ('PENDING', 'ACCEPTED', 'DECLINED');
+
+CREATE TABLE "paymentRequests"
+(
+    "id"                 BIGSERIAL              NOT NULL PRIMARY KEY,
+    "requesterAccountId" BIGINT                 NOT NULL REFERENCES "accounts" ("id"),
+    "payerAccountId"     BIGINT                 NOT NULL REFERENCES "accounts" ("id"),
+    "amountCents"        BIGINT                 NOT NULL CHECK ( "amountCents" > 0 ),
+    "description"        TEXT                   NOT NULL DEFAULT '',
+    "status"             status_payment_request NOT NULL DEFAULT 'PENDING',
+    "expiresAt"          TIMESTAMP              NOT NULL,
+    "createdAt"          TIMESTAMP              NOT NULL DEFAULT current_timestamp,
+    "transactionId"      BIGINT REFERENCES "transactions" ("id"),
+    CHECK ( "requesterAccountId" != "payerAccountId" )
+);
+
+CREATE INDEX "paymentRequests_requesterAccountId_index" ON "paymentRequests" ("requesterAccountId");
+CREATE INDEX "paymentRequests_payerAccountId_index" ON "paymentRequests" ("payerAccountId");
+
+CREATE TABLE "transactionBatches"
+(
+    "id"          BIGSERIAL NOT NULL PRIMARY KEY,
+    "senderId"    BIGINT    NOT NULL REFERENCES "accounts" ("id"),
+    "itemsCount"  INT       NOT NULL CHECK ( "itemsCount" > 0 ),
+    "totalCents"  BIGINT    NOT NULL CHECK ( "totalCents" > 0 ),
+    "createdAt"   TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+ALTER TABLE "transactions"
+    ADD COLUMN "batchId" BIGINT REFERENCES "transactionBatches" ("id");
+
+CREATE INDEX "transactions_batchId_index" ON "transactions" ("batchId");
+
+CREATE TYPE status_payment_file AS ENUM ('REJECTED', 'PROCESSING', 'COMPLETED', 'COMPLETED_WITH_ERRORS');
+CREATE TYPE status_payment_file_item AS ENUM ('INVALID', 'PENDING', 'PROCESSING', 'COMPLETED', 'FAILED');
+
+CREATE TABLE "paymentFiles"
+(
+    "id"         BIGSERIAL           NOT NULL PRIMARY KEY,
+    "senderId"   BIGINT              NOT NULL REFERENCES "accounts" ("id"),
+    "userId"     BIGINT              NOT NULL,
+    "format"     VARCHAR(16)         NOT NULL,
+    "hash"       CHAR(64)            NOT NULL,
+    "status"     status_payment_file NOT NULL,
+    "itemsCount" INT                 NOT NULL,
+    "totalCents" BIGINT              NOT NULL,
+    "createdAt"  TIMESTAMP           NOT NULL DEFAULT current_timestamp,
+    "updatedAt"  TIMESTAMP           NOT NULL DEFAULT current_timestamp,
+    UNIQUE ("senderId", "hash")
+);
+
+CREATE TABLE "paymentFileItems"
+(
+    "id"            BIGSERIAL                NOT NULL PRIMARY KEY,
+    "fileId"        BIGINT                   NOT NULL REFERENCES "paymentFiles" ("id"),
+    "lineNo"        INT                      NOT NULL,
+    "receiver"      TEXT                     NOT NULL,
+    "receiverId"    BIGINT REFERENCES "accounts" ("id"),
+    "amountCents"   BIGINT                   NOT NULL,
+    "description"   TEXT                     NOT NULL DEFAULT '',
+    "status"        status_payment_file_item NOT NULL,
+    "error"         TEXT                     NOT NULL DEFAULT '',
+    "transactionId" BIGINT REFERENCES "transactions" ("id")
+);
+
+CREATE INDEX "paymentFileItems_fileId_index" ON "paymentFileItems" ("fileId");
+
+CREATE TYPE atm_alert_type AS ENUM ('LOW_CASH');
+
+ALTER TABLE "atms"
+    ADD COLUMN "lowCashThresholdCents" BIGINT NOT NULL DEFAULT 1000000 CHECK ( "lowCashThresholdCents" >= 0 );
+
+CREATE TABLE "atmCassettes"
+(
+    "id"                BIGSERIAL NOT NULL PRIMARY KEY,
+    "atmId"             BIGINT    NOT NULL REFERENCES "atms" ("id"),
+    "denominationCents" BIGINT    NOT NULL CHECK ( "denominationCents" > 0 ),
+    "count"             BIGINT    NOT NULL CHECK ( "count" >= 0 ) DEFAULT 0,
+    UNIQUE ("atmId", "denominationCents")
+);
+
+CREATE TABLE "atmAlerts"
+(
+    "id"        BIGSERIAL      NOT NULL PRIMARY KEY,
+    "atmId"     BIGINT         NOT NULL REFERENCES "atms" ("id"),
+    "type"      atm_alert_type NOT NULL,
+    "cashCents" BIGINT         NOT NULL,
+    "createdAt" TIMESTAMP      NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "atmAlerts_atmId_index" ON "atmAlerts" ("atmId");
+
+ALTER TABLE "atms"
+    ADD COLUMN "failedLoginAttempts" INT NOT NULL DEFAULT 0 CHECK ( "failedLoginAttempts" >= 0 ),
+    ADD COLUMN "lockedUntil"         TIMESTAMP;
+
+CREATE TYPE status_card AS ENUM ('ACTIVE', 'BLOCKED');
+
+CREATE TABLE "cards"
+(
+    "id"        BIGSERIAL   NOT NULL PRIMARY KEY,
+    "accountId" BIGINT      NOT NULL REFERENCES "accounts" ("id"),
+    "number"    VARCHAR(19) NOT NULL UNIQUE CHECK ( "number" ~ '^[0-9]{12,19}$' ),
+    "pinHash"   BYTEA       NOT NULL CHECK ( length("pinHash") <= 60 ),
+    "pinTries"  INT         NOT NULL DEFAULT 0 CHECK ( "pinTries" >= 0 ),
+    "status"    status_card NOT NULL DEFAULT 'ACTIVE',
+    "createdAt" TIMESTAMP   NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "cards_accountId_index" ON "cards" ("accountId");
+
+CREATE TYPE status_atm AS ENUM ('ACTIVE', 'DISABLED');
+
+ALTER TABLE "atms"
+    ADD COLUMN "status"    status_atm NOT NULL DEFAULT 'ACTIVE',
+    ADD COLUMN "createdAt" TIMESTAMP  NOT NULL DEFAULT current_timestamp;
+
+ALTER TABLE "cashOperations"
+    ADD COLUMN "cashAfterCents"    BIGINT,
+    ADD COLUMN "balanceAfterCents" BIGINT;
+
+CREATE INDEX "cashOperations_atmAccountId_createdAt_index" ON "cashOperations" ("atmAccountId", "createdAt");
+
+ALTER TABLE "transactions"
+    ADD COLUMN "confirmedAt" TIMESTAMP;
+
+CREATE TYPE card_operation_type AS ENUM ('PURCHASE', 'ATM_WITHDRAWAL');
+
+ALTER TABLE "cards"
+    ADD COLUMN "expiresAt"                DATE   NOT NULL DEFAULT (date_trunc('month', current_date) + INTERVAL '4 years 1 month - 1 day')::DATE,
+    ADD COLUMN "cvvHash"                  BYTEA CHECK ( length("cvvHash") <= 60 ),
+    ADD COLUMN "perTransactionLimitCents" BIGINT CHECK ( "perTransactionLimitCents" > 0 ),
+    ADD COLUMN "dailyLimitCents"          BIGINT CHECK ( "dailyLimitCents" > 0 );
+
+CREATE TABLE "cardOperations"
+(
+    "id"            BIGSERIAL           NOT NULL PRIMARY KEY,
+    "cardId"        BIGINT              NOT NULL REFERENCES "cards" ("id"),
+    "type"          card_operation_type NOT NULL,
+    "receiverId"    BIGINT              NOT NULL REFERENCES "accounts" ("id"),
+    "transactionId" BIGINT              NOT NULL REFERENCES "transactions" ("id"),
+    "amountCents"   BIGINT              NOT NULL CHECK ( "amountCents" > 0 ),
+    "description"   TEXT                NOT NULL DEFAULT '',
+    "createdAt"     TIMESTAMP           NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "cardOperations_cardId_createdAt_index" ON "cardOperations" ("cardId", "createdAt");
+
+CREATE TYPE status_card_hold AS ENUM ('ACTIVE', 'CAPTURED', 'RELEASED', 'EXPIRED');
+
+CREATE TABLE "cardHolds"
+(
+    "id"            BIGSERIAL        NOT NULL PRIMARY KEY,
+    "cardId"        BIGINT           NOT NULL REFERENCES "cards" ("id"),
+    "accountId"     BIGINT           NOT NULL REFERENCES "accounts" ("id"),
+    "receiverId"    BIGINT           NOT NULL REFERENCES "accounts" ("id"),
+    "amountCents"   BIGINT           NOT NULL CHECK ( "amountCents" > 0 ),
+    "capturedCents" BIGINT           NOT NULL DEFAULT 0 CHECK ( "capturedCents" >= 0 AND "capturedCents" <= "amountCents" ),
+    "transactionId" BIGINT REFERENCES "transactions" ("id"),
+    "status"        status_card_hold NOT NULL DEFAULT 'ACTIVE',
+    "description"   TEXT             NOT NULL DEFAULT '',
+    "expiresAt"     TIMESTAMP        NOT NULL,
+    "createdAt"     TIMESTAMP        NOT NULL DEFAULT current_timestamp,
+    "updatedAt"     TIMESTAMP        NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "cardHolds_accountId_active_index" ON "cardHolds" ("accountId") WHERE "status" = 'ACTIVE';
+CREATE INDEX "cardHolds_cardId_active_index" ON "cardHolds" ("cardId") WHERE "status" = 'ACTIVE';
+
+CREATE TYPE account_event_type AS ENUM ('TRANSACTION_CREATED', 'TRANSACTION_CONFIRMED', 'BALANCE_CHANGED');
+
+CREATE TABLE "accountEvents"
+(
+    "id"            BIGSERIAL          NOT NULL PRIMARY KEY,
+    "accountId"     BIGINT             NOT NULL REFERENCES "accounts" ("id"),
+    "userId"        BIGINT,
+    "type"          account_event_type NOT NULL,
+    "transactionId" BIGINT REFERENCES "transactions" ("id"),
+    "amountCents"   BIGINT             NOT NULL,
+    "balanceCents"  BIGINT             NOT NULL,
+    "createdAt"     TIMESTAMP          NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "accountEvents_userId_id_index" ON "accountEvents" ("userId", "id");
+
+CREATE TABLE "revokedTokens"
+(
+    "jti"       VARCHAR   NOT NULL PRIMARY KEY,
+    "expiresAt" TIMESTAMP NOT NULL,
+    "revokedAt" TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "revokedTokens_expiresAt_index" ON "revokedTokens" ("expiresAt");
+
+CREATE TYPE status_step_up AS ENUM ('PENDING', 'CONFIRMED', 'USED', 'FAILED');
+
+CREATE TABLE "stepUpChallenges"
+(
+    "id"          BIGSERIAL      NOT NULL PRIMARY KEY,
+    "userId"      BIGINT         NOT NULL,
+    "senderId"    BIGINT         NOT NULL REFERENCES "accounts" ("id"),
+    "receiverId"  BIGINT         NOT NULL REFERENCES "accounts" ("id"),
+    "amountCents" BIGINT         NOT NULL,
+    "description" VARCHAR        NOT NULL DEFAULT '',
+    "codeHash"    BYTEA          NOT NULL,
+    "tokenHash"   BYTEA,
+    "attempts"    INT            NOT NULL DEFAULT 0,
+    "status"      status_step_up NOT NULL DEFAULT 'PENDING',
+    "expiresAt"   TIMESTAMP      NOT NULL,
+    "createdAt"   TIMESTAMP      NOT NULL DEFAULT current_timestamp,
+    "updatedAt"   TIMESTAMP      NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "transactions_receiverId_senderId_index" ON "transactions" ("receiverId", "senderId");
+
+ALTER TABLE "accountOwners"
+    ADD COLUMN "personalDataVerified" BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE "accountOwners"
+SET "personalDataVerified" = true
+WHERE "atmId" IS NOT NULL;
+
+CREATE TABLE "beneficiaries"
+(
+    "id"        BIGSERIAL    NOT NULL PRIMARY KEY,
+    "userId"    BIGINT       NOT NULL,
+    "accountId" BIGINT       NOT NULL REFERENCES "accounts" ("id"),
+    "nickname"  VARCHAR(128) NOT NULL,
+    "createdAt" TIMESTAMP    NOT NULL DEFAULT current_timestamp,
+    UNIQUE ("userId", "accountId")
+);
+
+ALTER TYPE status_transaction ADD VALUE 'REVIEW';
+
+CREATE TYPE status_transaction_review AS ENUM ('PENDING', 'APPROVED', 'REJECTED');
+
+CREATE TABLE "transactionReviews"
+(
+    "transactionId" BIGINT                    NOT NULL PRIMARY KEY REFERENCES "transactions" ("id"),
+    "score"         INT                       NOT NULL,
+    "reasons"       VARCHAR                   NOT NULL,
+    "status"        status_transaction_review NOT NULL DEFAULT 'PENDING',
+    "reviewerId"    BIGINT,
+    "createdAt"     TIMESTAMP                 NOT NULL DEFAULT current_timestamp,
+    "reviewedAt"    TIMESTAMP
+);
+
+CREATE INDEX "transactions_senderId_createdAt_index" ON "transactions" ("senderId", "createdAt");
+
+CREATE TYPE screening_operation AS ENUM ('TRANSFER', 'ATM_SUPPLEMENT', 'ATM_WITHDRAWAL');
+
+CREATE TYPE screening_list_type AS ENUM ('USER', 'ACCOUNT', 'NAME');
+
+CREATE TABLE "screeningHits"
+(
+    "id"            BIGSERIAL PRIMARY KEY,
+    "operation"     screening_operation NOT NULL,
+    "userId"        BIGINT,
+    "accountId"     BIGINT              NOT NULL REFERENCES "accounts" ("id"),
+    "transactionId" BIGINT REFERENCES "transactions" ("id"),
+    "listType"      screening_list_type NOT NULL,
+    "entry"         VARCHAR             NOT NULL,
+    "createdAt"     TIMESTAMP           NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX "screeningHits_accountId_index" ON "screeningHits" ("accountId");
+
+CREATE TABLE "auditLog"
+(
+    "id"        BIGSERIAL PRIMARY KEY,
+    "actorType" VARCHAR(16)  NOT NULL,
+    "actorId"   VARCHAR(128) NOT NULL DEFAULT '',
+    "requestId" VARCHAR(128) NOT NULL DEFAULT '',
+    "ip"        VARCHAR(64)  NOT NULL DEFAULT '',
+    "userAgent" VARCHAR      NOT NULL DEFAULT '',
+    "action"    VARCHAR(64)  NOT NULL,
+    "entityId"  BIGINT       NOT NULL DEFAULT 0,
+    "before"    JSON,
+    "after"     JSON,
+    "outcome"   VARCHAR(16)  NOT NULL,
+    "error"     VARCHAR      NOT NULL DEFAULT '',
+    "createdAt" TIMESTAMP    NOT NULL,
+    "prevHash"  VARCHAR(64)  NOT NULL,
+    "hash"      VARCHAR(64)  NOT NULL UNIQUE
+);
+
+CREATE INDEX "auditLog_actorId_index" ON "auditLog" ("actorId");
+CREATE INDEX "auditLog_action_entityId_index" ON "auditLog" ("action", "entityId");
+
+CREATE FUNCTION "auditLog_append_only"() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'auditLog is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "auditLog_append_only"
+    BEFORE UPDATE OR DELETE
+    ON "auditLog"
+    FOR EACH ROW
+EXECUTE FUNCTION "auditLog_append_only"();
+
+CREATE TRIGGER "auditLog_no_truncate"
+    BEFORE TRUNCATE
+    ON "auditLog"
+    FOR EACH STATEMENT
+EXECUTE FUNCTION "auditLog_append_only"();
+
+CREATE UNLOGGED TABLE "rateLimitBuckets"
+(
+    "key"           VARCHAR(256)     PRIMARY KEY,
+    "tokens"        DOUBLE PRECISION NOT NULL,
+    "ratePerSecond" DOUBLE PRECISION NOT NULL,
+    "burst"         INTEGER          NOT NULL,
+    "updatedAt"     TIMESTAMP        NOT NULL
+);
+
+ALTER TABLE "accountOwners"
+    ADD COLUMN "fullName" VARCHAR(128);
+
+CREATE TYPE status_alias_verification AS ENUM ('PENDING', 'CONFIRMED', 'FAILED');
+
+CREATE TABLE "paymentAliasVerifications"
+(
+    "id"        BIGSERIAL                 NOT NULL PRIMARY KEY,
+    "userId"    BIGINT                    NOT NULL,
+    "accountId" BIGINT                    NOT NULL REFERENCES "accounts" ("id"),
+    "type"      alias_type                NOT NULL,
+    "value"     VARCHAR(254)              NOT NULL,
+    "codeHash"  BYTEA                     NOT NULL,
+    "attempts"  INT                       NOT NULL DEFAULT 0,
+    "status"    status_alias_verification NOT NULL DEFAULT 'PENDING',
+    "expiresAt" TIMESTAMP                 NOT NULL,
+    "createdAt" TIMESTAMP                 NOT NULL DEFAULT current_timestamp,
+    "updatedAt" TIMESTAMP                 NOT NULL DEFAULT current_timestamp
+);
+
+CREATE TYPE step_up_operation AS ENUM ('TRANSFER', 'BATCH', 'PAYMENT_FILE');
+
+ALTER TABLE "stepUpChallenges"
+    ADD COLUMN "operation"     step_up_operation NOT NULL DEFAULT 'TRANSFER',
+    ADD COLUMN "operationHash" VARCHAR(64)       NOT NULL DEFAULT '',
+    ALTER COLUMN "receiverId" DROP NOT NULL;
+
+ALTER TYPE status_payment_file ADD VALUE 'AWAITING_CONFIRMATION';
+
 INSERT INTO "atms" ("cashCents", "login", "password")
 VALUES (5000000, 'atm001', '$2a$10$GHg/65CqcSqdLAeRcHUtxOYEAiHCKWF8I7WWJPLPv0mF54BkBBbh.'), -- password: atm
        (7500000, 'atm002', '$2a$10$qYNh0MLqcFiDBtYAL28GEOlLVa.sZWs.UhtSORr6iTGG5CuFNmW8S'); -- password: atm2
 
+INSERT INTO "atmCassettes" ("atmId", "denominationCents", "count")
+VALUES (1, 500000, 5),
+       (1, 100000, 10),
+       (1, 50000, 20),
+       (1, 10000, 50),
+       (2, 500000, 10),
+       (2, 200000, 10),
+       (2, 50000, 10);
+
+
+INSERT INTO "accountOwners" ("userId", "atmId", "personalDataVerified")
+VALUES (NULL, 1, true),
+       (NULL, 2, true),
+       (1, NULL, false);
 
-INSERT INTO "accountOwners" ("userId", "atmId")
-VALUES (NULL, 1),
-       (NULL, 2),
-       (1, NULL);
+INSERT INTO "accounts" ("balanceCents", "ownerId", "status", "number")
+VALUES (100000, 1, 'ACTIVE', 'XB0700010000000000000001'),
+       (200000, 2, 'ACTIVE', 'XB7700010000000000000002'),
+       (300000, 3, 'ACTIVE', 'XB5000010000000000000003'),
+       (400000, 3, 'BLOCKED', 'XB2300010000000000000004');
 
-INSERT INTO "accounts" ("balanceCents", "ownerId", "status")
-VALUES (100000, 1, 'ACTIVE'),
-       (200000, 2, 'ACTIVE'),
-       (300000, 3, 'ACTIVE'),
-       (400000, 3, 'BLOCKED');
+INSERT INTO "cards" ("accountId", "number", "pinHash", "cvvHash")
+VALUES (3, '2200000000000012', '$2a$10$fgt7q3xR8yRqzFjV1dg75OEBGC41kT1HKliAfxAITD20z0KqtNkwW',
+        '$2a$10$27P9YvJvC3QNHmB9vxvDAeODAFm5EDqkhoe9fr/c7S2ajtrhZ3v8W'); -- pin: 1234, cvv: 123
 
-INSERT INTO "transactions" ("senderId", "receiverId", "status", "amountCents", "description")
-VALUES (1, 2, 'CONFIRMED', 50000, 'Payment for services'),
-       (2, 3, 'CANCELLED', 100000, 'Refund'),
-       (3, 1, 'BLOCKED', 20000, 'Transfer'),
-       (1, 3, 'CONFIRMED', 30000, 'Gift');
+INSERT INTO "transactions" ("senderId", "receiverId", "status", "amountCents", "description", "confirmedAt")
+VALUES (1, 2, 'CONFIRMED', 50000, 'Payment for services', current_timestamp),
+       (2, 3, 'CANCELLED', 100000, 'Refund', NULL),
+       (3, 1, 'BLOCKED', 20000, 'Transfer', NULL),
+       (1, 3, 'CONFIRMED', 30000, 'Gift', current_timestamp);
 
 SELECT *
 FROM transactions;
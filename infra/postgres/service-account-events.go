@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"strconv"
+	"strings"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+const accountEventsChannel = "account_events"
+
+func (s *Service) ListenAccountEvents(ctx context.Context, handler func(userId, eventId int64)) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	return conn.Raw(func(driverConn any) error {
+		pgxConn := driverConn.(*stdlib.Conn).Conn()
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+accountEventsChannel); err != nil {
+			return s.wrapQueryError(err)
+		}
+
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				return s.wrapQueryError(err)
+			}
+
+			userId, eventId, ok := strings.Cut(notification.Payload, ":")
+			if !ok {
+				continue
+			}
+			parsedUserId, err := strconv.ParseInt(userId, 10, 64)
+			if err != nil {
+				continue
+			}
+			parsedEventId, err := strconv.ParseInt(eventId, 10, 64)
+			if err != nil {
+				continue
+			}
+			handler(parsedUserId, parsedEventId)
+		}
+	})
+}
+
+func (s *Service) GetAccountEvent(ctx context.Context, eventId int64) (web.AccountEventData, error) {
+	const query = `SELECT "id", "accountId", COALESCE("userId", 0), "type", COALESCE("transactionId", 0), "amountCents", "balanceCents", "createdAt" 
+					FROM "accountEvents" WHERE "id" = $1`
+
+	row := s.db.QueryRowContext(ctx, query, eventId)
+	if err := row.Err(); err != nil {
+		return web.AccountEventData{}, s.wrapQueryError(err)
+	}
+
+	var data web.AccountEventData
+	err := row.Scan(&data.Id, &data.AccountId, &data.UserId, &data.Type, &data.TransactionId, &data.AmountCents, &data.BalanceCents, &data.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.AccountEventData{}, cerrors.NewErrorWithUserMessage(ercodes.AccountEventNotFound, err, "Событие не найдено")
+		}
+		return web.AccountEventData{}, s.wrapScanError(err)
+	}
+	return data, nil
+}
+
+func (s *Service) GetAccountEventsAfter(ctx context.Context, userId, afterId, limit int64) ([]web.AccountEventData, error) {
+	const query = `SELECT "id", "accountId", COALESCE("userId", 0), "type", COALESCE("transactionId", 0), "amountCents", "balanceCents", "createdAt" 
+					FROM "accountEvents" WHERE "userId" = @userId AND "id" > @afterId ORDER BY "id" LIMIT @limit`
+
+	rows, err := s.db.QueryContext(ctx, query, pgx.NamedArgs{
+		"userId":  userId,
+		"afterId": afterId,
+		"limit":   limit,
+	})
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var events []web.AccountEventData
+	for rows.Next() {
+		var data web.AccountEventData
+		if err = rows.Scan(&data.Id, &data.AccountId, &data.UserId, &data.Type, &data.TransactionId, &data.AmountCents, &data.BalanceCents, &data.CreatedAt); err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		events = append(events, data)
+	}
+	return events, nil
+}
+
+func (s *Service) createAccountEvent(ctx context.Context, q querier, accountId int64, eventType string, transactionId, amountCents int64) error {
+	const query = `WITH event AS (
+						INSERT INTO "accountEvents" ("accountId", "userId", "type", "transactionId", "amountCents", "balanceCents") 
+						SELECT accounts."id", "accountOwners"."userId", @type, NULLIF(@transactionId::BIGINT, 0), @amountCents, accounts."balanceCents" 
+						FROM accounts 
+						LEFT JOIN "accountOwners" ON accounts."ownerId" = "accountOwners"."id" 
+						WHERE accounts."id" = @accountId 
+						RETURNING "id", "userId"
+					) 
+					SELECT pg_notify('` + accountEventsChannel + `', event."userId" || ':' || event."id") FROM event WHERE event."userId" IS NOT NULL`
+
+	_, err := q.ExecContext(ctx, query, pgx.NamedArgs{
+		"accountId":     accountId,
+		"type":          eventType,
+		"transactionId": transactionId,
+		"amountCents":   amountCents,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) CreatePaymentAlias(ctx context.Context, accountId int64, aliasType, value, ownerName string) error {
+	const query = `INSERT INTO "paymentAliases" ("accountId", "type", "value", "ownerName") VALUES (@accountId, @type, @value, @ownerName)`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"accountId": accountId,
+		"type":      aliasType,
+		"value":     value,
+		"ownerName": ownerName,
+	})
+	if err != nil {
+		if s.isUniqueViolation(err) {
+			return cerrors.NewErrorWithUserMessage(ercodes.AliasAlreadyExists, err, "Псевдоним уже привязан к счёту")
+		}
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) GetUserPaymentAliases(ctx context.Context, userId int64) ([]web.PaymentAliasData, error) {
+	const query = `SELECT "paymentAliases"."id", "paymentAliases"."accountId", "paymentAliases"."type", "paymentAliases"."value", 
+       				"paymentAliases"."ownerName", "paymentAliases"."createdAt" FROM "paymentAliases"
+					INNER JOIN accounts ON "paymentAliases"."accountId" = accounts.id
+					INNER JOIN "accountOwners" ON accounts."ownerId" = "accountOwners".id
+					WHERE "accountOwners"."userId" = $1 ORDER BY "paymentAliases"."createdAt" DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var aliasesData []web.PaymentAliasData
+	for rows.Next() {
+		var data web.PaymentAliasData
+		if err = rows.Scan(&data.Id, &data.AccountId, &data.Type, &data.Value, &data.OwnerName, &data.CreatedAt); err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		aliasesData = append(aliasesData, data)
+	}
+
+	return aliasesData, nil
+}
+
+func (s *Service) GetPaymentAlias(ctx context.Context, value string) (web.PaymentAliasData, error) {
+	const query = `SELECT "id", "accountId", "type", "value", "ownerName", "createdAt" FROM "paymentAliases" WHERE "value" = $1`
+
+	row := s.db.QueryRowContext(ctx, query, value)
+	if err := row.Err(); err != nil {
+		return web.PaymentAliasData{}, s.wrapQueryError(err)
+	}
+
+	var data web.PaymentAliasData
+	if err := row.Scan(&data.Id, &data.AccountId, &data.Type, &data.Value, &data.OwnerName, &data.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.PaymentAliasData{}, cerrors.NewErrorWithUserMessage(ercodes.AliasNotFound, err, "Получатель не найден")
+		}
+		return web.PaymentAliasData{}, s.wrapScanError(err)
+	}
+	return data, nil
+}
+
+func (s *Service) DeletePaymentAlias(ctx context.Context, aliasId, userId int64) error {
+	const query = `DELETE FROM "paymentAliases" USING accounts, "accountOwners"
+					WHERE "paymentAliases"."accountId" = accounts.id AND accounts."ownerId" = "accountOwners".id
+					AND "paymentAliases"."id" = @aliasId AND "accountOwners"."userId" = @userId`
+
+	result, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"aliasId": aliasId,
+		"userId":  userId,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	if affected == 0 {
+		return cerrors.NewErrorWithUserMessage(ercodes.AliasNotFound, nil, "Псевдоним не найден")
+	}
+	return nil
+}
+
+func (s *Service) CreatePaymentAliasVerification(ctx context.Context, verification web.PaymentAliasVerificationData) (int64, error) {
+	const query = `INSERT INTO "paymentAliasVerifications" ("userId", "accountId", "type", "value", "codeHash", "status", "expiresAt") 
+					VALUES (@userId, @accountId, @type, @value, @codeHash, @status, @expiresAt) RETURNING "id"`
+
+	var verificationId int64
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"userId":    verification.UserId,
+		"accountId": verification.AccountId,
+		"type":      verification.Type,
+		"value":     verification.Value,
+		"codeHash":  verification.CodeHash,
+		"status":    verification.Status,
+		"expiresAt": verification.ExpiresAt,
+	}).Scan(&verificationId)
+	if err != nil {
+		return 0, s.wrapQueryError(err)
+	}
+	return verificationId, nil
+}
+
+func (s *Service) GetPaymentAliasVerificationForUpdate(ctx context.Context, verificationId int64) (web.PaymentAliasVerificationData, error) {
+	const query = `SELECT "id", "userId", "accountId", "type", "value", "codeHash", "attempts", "status", "expiresAt", "createdAt" 
+					FROM "paymentAliasVerifications" WHERE "id" = $1 FOR UPDATE`
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, verificationId)
+	if err := row.Err(); err != nil {
+		return web.PaymentAliasVerificationData{}, s.wrapQueryError(err)
+	}
+
+	var verification web.PaymentAliasVerificationData
+	err := row.Scan(&verification.Id, &verification.UserId, &verification.AccountId, &verification.Type, &verification.Value,
+		&verification.CodeHash, &verification.Attempts, &verification.Status, &verification.ExpiresAt, &verification.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.PaymentAliasVerificationData{}, cerrors.NewErrorWithUserMessage(ercodes.AliasVerificationNotFound, err, "Подтверждение псевдонима не найдено")
+		}
+		return web.PaymentAliasVerificationData{}, s.wrapScanError(err)
+	}
+	return verification, nil
+}
+
+func (s *Service) UpdatePaymentAliasVerification(ctx context.Context, verification web.PaymentAliasVerificationData) error {
+	const query = `UPDATE "paymentAliasVerifications" SET "status" = @status, "attempts" = @attempts, "updatedAt" = current_timestamp 
+				 WHERE "id" = @verificationId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"verificationId": verification.Id,
+		"status":         verification.Status,
+		"attempts":       verification.Attempts,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"x-bank-ms-bank/core/web"
+)
+
+func (s *Service) GetAtmCassettes(ctx context.Context, atmId int64) ([]web.AtmBanknotesData, error) {
+	const query = `SELECT "denominationCents", "count" FROM "atmCassettes" WHERE "atmId" = $1 ORDER BY "denominationCents" DESC FOR UPDATE`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, atmId)
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var cassettes []web.AtmBanknotesData
+	for rows.Next() {
+		var data web.AtmBanknotesData
+		if err = rows.Scan(&data.DenominationCents, &data.Count); err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		cassettes = append(cassettes, data)
+	}
+	return cassettes, nil
+}
+
+func (s *Service) UpdateAtmCassettes(ctx context.Context, atmId int64, banknotes []web.AtmBanknotesData) error {
+	const query = `INSERT INTO "atmCassettes" ("atmId", "denominationCents", "count") VALUES (@atmId, @denominationCents, @count) 
+					ON CONFLICT ("atmId", "denominationCents") DO UPDATE SET "count" = "atmCassettes"."count" + EXCLUDED."count"`
+
+	for _, entry := range banknotes {
+		_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+			"atmId":             atmId,
+			"denominationCents": entry.DenominationCents,
+			"count":             entry.Count,
+		})
+		if err != nil {
+			return s.wrapQueryError(err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) CreateAtmAlert(ctx context.Context, atmId int64, alertType string, cashCents int64) error {
+	const query = `INSERT INTO "atmAlerts" ("atmId", "type", "cashCents") VALUES (@atmId, @type, @cashCents)`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"atmId":     atmId,
+		"type":      alertType,
+		"cashCents": cashCents,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) CreateAtm(ctx context.Context, login string, passwordHash []byte, accountNumber string) (web.AtmData, error) {
+	atmData := web.AtmData{
+		Login:         login,
+		AccountNumber: accountNumber,
+		Status:        web.AtmStatusActive,
+	}
+
+	err := s.WithinTransaction(ctx, func(ctx context.Context) error {
+		const queryAtm = `INSERT INTO atms ("cashCents", "login", "password") VALUES (0, @login, @password) RETURNING "id", "lowCashThresholdCents", "createdAt"`
+		err := s.conn(ctx).QueryRowContext(ctx, queryAtm, pgx.NamedArgs{
+			"login":    login,
+			"password": passwordHash,
+		}).Scan(&atmData.Id, &atmData.LowCashThresholdCents, &atmData.CreatedAt)
+		if err != nil {
+			if s.isUniqueViolation(err) {
+				return cerrors.NewErrorWithUserMessage(ercodes.AtmLoginExists, err, "Логин банкомата уже занят")
+			}
+			return s.wrapQueryError(err)
+		}
+
+		const queryOwner = `INSERT INTO "accountOwners" ("atmId", "personalDataVerified") VALUES ($1, true) RETURNING "id"`
+		var ownerId int64
+		if err = s.conn(ctx).QueryRowContext(ctx, queryOwner, atmData.Id).Scan(&ownerId); err != nil {
+			return s.wrapQueryError(err)
+		}
+
+		const queryAccount = `INSERT INTO accounts ("ownerId", "number") VALUES ($1, $2) RETURNING "id"`
+		if err = s.conn(ctx).QueryRowContext(ctx, queryAccount, ownerId, accountNumber).Scan(&atmData.AccountId); err != nil {
+			if s.isUniqueViolation(err) {
+				return cerrors.NewErrorWithUserMessage(ercodes.AccountNumberExists, err, "Номер счёта уже занят")
+			}
+			return s.wrapQueryError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return web.AtmData{}, err
+	}
+	return atmData, nil
+}
+
+func (s *Service) GetAtms(ctx context.Context, limit, offset int64) ([]web.AtmData, int64, error) {
+	const query = `SELECT atms.id, atms.login, atms."status", atms."cashCents", atms."lowCashThresholdCents", 
+       				COALESCE(atms."lockedUntil" > current_timestamp, false), atms."createdAt", accounts.id, accounts."number"
+					FROM atms
+					INNER JOIN "accountOwners" ON atms.id = "accountOwners"."atmId" 
+					INNER JOIN "accounts" ON "accountOwners".id = "accounts"."ownerId"
+					ORDER BY atms.id LIMIT @limit OFFSET @offset`
+
+	rows, err := s.db.QueryContext(ctx, query, pgx.NamedArgs{
+		"limit":  limit,
+		"offset": offset,
+	})
+	if err != nil {
+		return nil, 0, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var (
+		atmsData []web.AtmData
+		atmIds   []int64
+	)
+	for rows.Next() {
+		var data web.AtmData
+		err = rows.Scan(&data.Id, &data.Login, &data.Status, &data.CashCents, &data.LowCashThresholdCents,
+			&data.IsLocked, &data.CreatedAt, &data.AccountId, &data.AccountNumber)
+		if err != nil {
+			return nil, 0, s.wrapScanError(err)
+		}
+		atmsData = append(atmsData, data)
+		atmIds = append(atmIds, data.Id)
+	}
+
+	const queryCassettes = `SELECT "atmId", "denominationCents", "count" FROM "atmCassettes" WHERE "atmId" = ANY($1) ORDER BY "denominationCents" DESC`
+	cassetteRows, err := s.db.QueryContext(ctx, queryCassettes, atmIds)
+	if err != nil {
+		return nil, 0, s.wrapQueryError(err)
+	}
+	defer func() { _ = cassetteRows.Close() }()
+
+	cassettes := make(map[int64][]web.AtmBanknotesData, len(atmIds))
+	for cassetteRows.Next() {
+		var (
+			atmId int64
+			data  web.AtmBanknotesData
+		)
+		if err = cassetteRows.Scan(&atmId, &data.DenominationCents, &data.Count); err != nil {
+			return nil, 0, s.wrapScanError(err)
+		}
+		cassettes[atmId] = append(cassettes[atmId], data)
+	}
+	for i := range atmsData {
+		atmsData[i].Cassettes = cassettes[atmsData[i].Id]
+	}
+
+	const queryTotal = `SELECT COUNT("id") FROM atms`
+	var total int64
+	if err = s.db.QueryRowContext(ctx, queryTotal).Scan(&total); err != nil {
+		return nil, 0, s.wrapQueryError(err)
+	}
+
+	return atmsData, total, nil
+}
+
+func (s *Service) UpdateAtmPassword(ctx context.Context, atmId int64, passwordHash []byte) error {
+	const query = `UPDATE atms SET "password" = @password, "failedLoginAttempts" = 0, "lockedUntil" = NULL WHERE id = @atmId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"atmId":    atmId,
+		"password": passwordHash,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) UpdateAtmStatus(ctx context.Context, atmId int64, status string) error {
+	const query = `UPDATE atms SET "status" = @status WHERE id = @atmId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"atmId":  atmId,
+		"status": status,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
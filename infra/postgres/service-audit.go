@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+	"x-bank-ms-bank/core/web"
+)
+
+const auditLogLockKey = 7_310_001
+
+const auditEntryColumns = `"id", "actorType", "actorId", "requestId", "ip", "userAgent", "action", "entityId", "before", "after", 
+       				"outcome", "error", "createdAt", "prevHash", "hash"`
+
+func (s *Service) AppendAuditEntry(ctx context.Context, entry web.AuditEntryData) error {
+	return s.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.conn(ctx).ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditLogLockKey); err != nil {
+			return s.wrapQueryError(err)
+		}
+
+		const queryLast = `SELECT "hash" FROM "auditLog" ORDER BY "id" DESC LIMIT 1`
+		if err := s.conn(ctx).QueryRowContext(ctx, queryLast).Scan(&entry.PrevHash); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return s.wrapQueryError(err)
+		}
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.Hash = entry.ComputeHash()
+
+		const queryInsert = `INSERT INTO "auditLog" ("actorType", "actorId", "requestId", "ip", "userAgent", "action", "entityId", "before", "after", 
+                        "outcome", "error", "createdAt", "prevHash", "hash") 
+						VALUES (@actorType, @actorId, @requestId, @ip, @userAgent, @action, @entityId, @before, @after, 
+						        @outcome, @error, @createdAt, @prevHash, @hash)`
+		_, err := s.conn(ctx).ExecContext(ctx, queryInsert, pgx.NamedArgs{
+			"actorType": entry.ActorType,
+			"actorId":   entry.ActorId,
+			"requestId": entry.RequestId,
+			"ip":        entry.Ip,
+			"userAgent": entry.UserAgent,
+			"action":    entry.Action,
+			"entityId":  entry.EntityId,
+			"before":    nullableJSON(entry.Before),
+			"after":     nullableJSON(entry.After),
+			"outcome":   entry.Outcome,
+			"error":     entry.Error,
+			"createdAt": entry.CreatedAt,
+			"prevHash":  entry.PrevHash,
+			"hash":      entry.Hash,
+		})
+		if err != nil {
+			return s.wrapQueryError(err)
+		}
+		return nil
+	})
+}
+
+func (s *Service) GetAuditEntries(ctx context.Context, filter web.AuditFilterData, limit, offset int64) ([]web.AuditEntryData, int64, error) {
+	conditions := []string{"TRUE"}
+	args := pgx.NamedArgs{
+		"limit":  limit,
+		"offset": offset,
+	}
+	if filter.ActorType != "" {
+		conditions = append(conditions, `"actorType" = @actorType`)
+		args["actorType"] = filter.ActorType
+	}
+	if filter.ActorId != "" {
+		conditions = append(conditions, `"actorId" = @actorId`)
+		args["actorId"] = filter.ActorId
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, `"action" = @action`)
+		args["action"] = filter.Action
+	}
+	if filter.EntityId != 0 {
+		conditions = append(conditions, `"entityId" = @entityId`)
+		args["entityId"] = filter.EntityId
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, `"createdAt" >= @from`)
+		args["from"] = filter.From.UTC()
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, `"createdAt" < @to`)
+		args["to"] = filter.To.UTC()
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "auditLog" WHERE `+where, args).Scan(&total); err != nil {
+		return nil, 0, s.wrapQueryError(err)
+	}
+
+	query := `SELECT ` + auditEntryColumns + ` FROM "auditLog" WHERE ` + where + ` ORDER BY "id" DESC LIMIT @limit OFFSET @offset`
+	entries, err := s.queryAuditEntries(ctx, query, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+func (s *Service) GetAuditEntriesAfter(ctx context.Context, afterId, limit int64) ([]web.AuditEntryData, error) {
+	const query = `SELECT ` + auditEntryColumns + ` FROM "auditLog" WHERE "id" > @afterId ORDER BY "id" LIMIT @limit`
+	return s.queryAuditEntries(ctx, query, pgx.NamedArgs{
+		"afterId": afterId,
+		"limit":   limit,
+	})
+}
+
+func (s *Service) queryAuditEntries(ctx context.Context, query string, args pgx.NamedArgs) ([]web.AuditEntryData, error) {
+	rows, err := s.db.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []web.AuditEntryData
+	for rows.Next() {
+		var entry web.AuditEntryData
+		err = rows.Scan(&entry.Id, &entry.ActorType, &entry.ActorId, &entry.RequestId, &entry.Ip, &entry.UserAgent, &entry.Action, &entry.EntityId,
+			&entry.Before, &entry.After, &entry.Outcome, &entry.Error, &entry.CreatedAt, &entry.PrevHash, &entry.Hash)
+		if err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func nullableJSON(value []byte) any {
+	if value == nil {
+		return nil
+	}
+	return string(value)
+}
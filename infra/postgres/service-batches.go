@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) GetAccountsDataByIds(ctx context.Context, accountIds []int64) (map[int64]web.UserAccountData, error) {
+	const query = `SELECT accounts."id", accounts."number", ` + accountBalanceColumns + `, accounts."status", COALESCE("accountOwners"."userId", 0), 
+    COALESCE("accountOwners"."personalDataVerified", true) FROM accounts 
+    LEFT JOIN "accountOwners" ON accounts."ownerId" = "accountOwners".id WHERE accounts."id" = ANY($1)`
+
+	rows, err := s.db.QueryContext(ctx, query, accountIds)
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	accountsData := make(map[int64]web.UserAccountData, len(accountIds))
+	for rows.Next() {
+		var data web.UserAccountData
+		if err = rows.Scan(&data.Id, &data.Number, &data.BalanceCents, &data.AvailableCents, &data.PendingIncomingCents, &data.PendingOutgoingCents, &data.Status, &data.UserId, &data.OwnerIdentified); err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		data.BookedCents = data.BalanceCents + data.PendingOutgoingCents
+		accountsData[data.Id] = data
+	}
+
+	return accountsData, nil
+}
+
+func (s *Service) CreateTransactionBatch(ctx context.Context, senderId int64, itemsCount int, totalCents int64) (int64, error) {
+	const query = `INSERT INTO "transactionBatches" ("senderId", "itemsCount", "totalCents") VALUES (@senderId, @itemsCount, @totalCents) RETURNING id`
+
+	var batchId int64
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"senderId":   senderId,
+		"itemsCount": itemsCount,
+		"totalCents": totalCents,
+	}).Scan(&batchId)
+	if err != nil {
+		return 0, s.wrapQueryError(err)
+	}
+	return batchId, nil
+}
+
+func (s *Service) LinkTransactionToBatch(ctx context.Context, batchId, transactionId int64) error {
+	const query = `UPDATE transactions SET "batchId" = @batchId WHERE id = @transactionId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"batchId":       batchId,
+		"transactionId": transactionId,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) GetTransactionBatch(ctx context.Context, batchId int64) (web.TransactionBatchData, error) {
+	const queryBatch = `SELECT "id", "senderId", "totalCents", "createdAt" FROM "transactionBatches" WHERE "id" = $1`
+
+	row := s.db.QueryRowContext(ctx, queryBatch, batchId)
+	if err := row.Err(); err != nil {
+		return web.TransactionBatchData{}, s.wrapQueryError(err)
+	}
+
+	var batchData web.TransactionBatchData
+	if err := row.Scan(&batchData.Id, &batchData.SenderId, &batchData.TotalCents, &batchData.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.TransactionBatchData{}, cerrors.NewErrorWithUserMessage(ercodes.TransactionBatchNotFound, err, "Пакет переводов не найден")
+		}
+		return web.TransactionBatchData{}, s.wrapScanError(err)
+	}
+
+	const queryItems = `SELECT "id", "receiverId", "amountCents", COALESCE("description", ''), "status" FROM transactions WHERE "batchId" = $1 ORDER BY "id"`
+	rows, err := s.db.QueryContext(ctx, queryItems, batchId)
+	if err != nil {
+		return web.TransactionBatchData{}, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var data web.BatchTransactionData
+		if err = rows.Scan(&data.TransactionId, &data.ReceiverId, &data.AmountCents, &data.Description, &data.Status); err != nil {
+			return web.TransactionBatchData{}, s.wrapScanError(err)
+		}
+		batchData.Items = append(batchData.Items, data)
+	}
+
+	return batchData, nil
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) CreateBeneficiary(ctx context.Context, userId, accountId int64, nickname string) (int64, error) {
+	const query = `INSERT INTO "beneficiaries" ("userId", "accountId", "nickname") VALUES (@userId, @accountId, @nickname) RETURNING "id"`
+
+	var beneficiaryId int64
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"userId":    userId,
+		"accountId": accountId,
+		"nickname":  nickname,
+	}).Scan(&beneficiaryId)
+	if err != nil {
+		if s.isUniqueViolation(err) {
+			return 0, cerrors.NewErrorWithUserMessage(ercodes.BeneficiaryAlreadyExists, err, "Получатель уже сохранён")
+		}
+		return 0, s.wrapQueryError(err)
+	}
+	return beneficiaryId, nil
+}
+
+func (s *Service) GetUserBeneficiaries(ctx context.Context, userId int64) ([]web.BeneficiaryData, error) {
+	const query = `SELECT "beneficiaries"."id", "beneficiaries"."userId", "beneficiaries"."accountId", accounts."number", 
+       				"beneficiaries"."nickname", "beneficiaries"."createdAt" FROM "beneficiaries"
+					INNER JOIN accounts ON "beneficiaries"."accountId" = accounts.id
+					WHERE "beneficiaries"."userId" = $1 ORDER BY "beneficiaries"."nickname"`
+
+	rows, err := s.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var beneficiaries []web.BeneficiaryData
+	for rows.Next() {
+		var data web.BeneficiaryData
+		if err = rows.Scan(&data.Id, &data.UserId, &data.AccountId, &data.AccountNumber, &data.Nickname, &data.CreatedAt); err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		beneficiaries = append(beneficiaries, data)
+	}
+
+	return beneficiaries, nil
+}
+
+func (s *Service) GetUserBeneficiaryByAccount(ctx context.Context, userId, accountId int64) (web.BeneficiaryData, error) {
+	const query = `SELECT "beneficiaries"."id", "beneficiaries"."userId", "beneficiaries"."accountId", accounts."number", 
+       				"beneficiaries"."nickname", "beneficiaries"."createdAt" FROM "beneficiaries"
+					INNER JOIN accounts ON "beneficiaries"."accountId" = accounts.id
+					WHERE "beneficiaries"."userId" = $1 AND "beneficiaries"."accountId" = $2`
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, userId, accountId)
+	if err := row.Err(); err != nil {
+		return web.BeneficiaryData{}, s.wrapQueryError(err)
+	}
+
+	var data web.BeneficiaryData
+	if err := row.Scan(&data.Id, &data.UserId, &data.AccountId, &data.AccountNumber, &data.Nickname, &data.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.BeneficiaryData{}, cerrors.NewErrorWithUserMessage(ercodes.BeneficiaryNotFound, err, "Получатель не найден")
+		}
+		return web.BeneficiaryData{}, s.wrapScanError(err)
+	}
+	return data, nil
+}
+
+func (s *Service) UpdateBeneficiaryNickname(ctx context.Context, beneficiaryId, userId int64, nickname string) error {
+	const query = `UPDATE "beneficiaries" SET "nickname" = @nickname WHERE "id" = @beneficiaryId AND "userId" = @userId`
+
+	result, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"beneficiaryId": beneficiaryId,
+		"userId":        userId,
+		"nickname":      nickname,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return s.checkBeneficiaryAffected(result)
+}
+
+func (s *Service) DeleteBeneficiary(ctx context.Context, beneficiaryId, userId int64) error {
+	const query = `DELETE FROM "beneficiaries" WHERE "id" = @beneficiaryId AND "userId" = @userId`
+
+	result, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"beneficiaryId": beneficiaryId,
+		"userId":        userId,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return s.checkBeneficiaryAffected(result)
+}
+
+func (s *Service) checkBeneficiaryAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	if affected == 0 {
+		return cerrors.NewErrorWithUserMessage(ercodes.BeneficiaryNotFound, nil, "Получатель не найден")
+	}
+	return nil
+}
+
+func (s *Service) GetUserTransfersSumTo(ctx context.Context, userId, receiverId int64, since time.Time) (int64, error) {
+	const query = `SELECT COALESCE(SUM(transactions."amountCents"), 0) FROM transactions 
+					INNER JOIN accounts ON transactions."senderId" = accounts."id" 
+					INNER JOIN "accountOwners" ON accounts."ownerId" = "accountOwners"."id" 
+					WHERE "accountOwners"."userId" = @userId AND transactions."receiverId" = @receiverId 
+					AND transactions."status" <> 'CANCELLED' AND transactions."createdAt" >= @since`
+
+	var sumCents int64
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"userId":     userId,
+		"receiverId": receiverId,
+		"since":      since,
+	}).Scan(&sumCents)
+	if err != nil {
+		return 0, s.wrapQueryError(err)
+	}
+	return sumCents, nil
+}
@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) CreateCard(ctx context.Context, cardData web.CardData) (int64, error) {
+	const query = `INSERT INTO "cards" ("accountId", "number", "pinHash", "cvvHash", "expiresAt") 
+					VALUES (@accountId, @number, @pinHash, @cvvHash, @expiresAt) RETURNING "id"`
+
+	var cardId int64
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"accountId": cardData.AccountId,
+		"number":    cardData.Number,
+		"pinHash":   cardData.PinHash,
+		"cvvHash":   cardData.CvvHash,
+		"expiresAt": cardData.ExpiresAt,
+	}).Scan(&cardId)
+	if err != nil {
+		if s.isUniqueViolation(err) {
+			return 0, cerrors.NewErrorWithUserMessage(ercodes.CardNumberExists, err, "Номер карты уже занят")
+		}
+		return 0, s.wrapQueryError(err)
+	}
+	return cardId, nil
+}
+
+func (s *Service) GetCardByNumber(ctx context.Context, number string) (web.CardData, error) {
+	return s.getCard(ctx, `cards."number" = $1`, number)
+}
+
+func (s *Service) GetCardById(ctx context.Context, cardId int64) (web.CardData, error) {
+	return s.getCard(ctx, `cards."id" = $1`, cardId)
+}
+
+func (s *Service) getCard(ctx context.Context, condition string, arg any) (web.CardData, error) {
+	query := `SELECT cards."id", cards."accountId", COALESCE("accountOwners"."userId", 0), cards."number", cards."pinHash", cards."pinTries", cards."cvvHash", 
+					cards."status", cards."expiresAt", COALESCE(cards."perTransactionLimitCents", 0), COALESCE(cards."dailyLimitCents", 0), cards."createdAt" 
+					FROM cards 
+					INNER JOIN accounts ON cards."accountId" = accounts."id" 
+					LEFT JOIN "accountOwners" ON accounts."ownerId" = "accountOwners"."id" 
+					WHERE ` + condition
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, arg)
+	if err := row.Err(); err != nil {
+		return web.CardData{}, s.wrapQueryError(err)
+	}
+
+	var cardData web.CardData
+	err := row.Scan(&cardData.Id, &cardData.AccountId, &cardData.UserId, &cardData.Number, &cardData.PinHash, &cardData.PinTries, &cardData.CvvHash,
+		&cardData.Status, &cardData.ExpiresAt, &cardData.PerTransactionLimitCents, &cardData.DailyLimitCents, &cardData.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.CardData{}, cerrors.NewErrorWithUserMessage(ercodes.CardNotFound, err, "Карта не найдена")
+		}
+		return web.CardData{}, s.wrapScanError(err)
+	}
+	return cardData, nil
+}
+
+func (s *Service) GetUserCards(ctx context.Context, userId int64) ([]web.CardData, error) {
+	const query = `SELECT cards."id", cards."accountId", cards."number", cards."status", cards."expiresAt", 
+					COALESCE(cards."perTransactionLimitCents", 0), COALESCE(cards."dailyLimitCents", 0), cards."createdAt" 
+					FROM cards 
+					INNER JOIN accounts ON cards."accountId" = accounts."id" 
+					INNER JOIN "accountOwners" ON accounts."ownerId" = "accountOwners"."id" 
+					WHERE "accountOwners"."userId" = $1 ORDER BY cards."id"`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var cardsData []web.CardData
+	for rows.Next() {
+		cardData := web.CardData{UserId: userId}
+		if err = rows.Scan(&cardData.Id, &cardData.AccountId, &cardData.Number, &cardData.Status, &cardData.ExpiresAt,
+			&cardData.PerTransactionLimitCents, &cardData.DailyLimitCents, &cardData.CreatedAt); err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		cardsData = append(cardsData, cardData)
+	}
+	return cardsData, nil
+}
+
+func (s *Service) RegisterCardPinFailure(ctx context.Context, cardId int64, maxTries int) error {
+	const query = `UPDATE cards SET "pinTries" = "pinTries" + 1, 
+                 "status" = CASE WHEN "pinTries" + 1 >= @maxTries THEN 'BLOCKED'::status_card ELSE "status" END 
+				 WHERE "id" = @cardId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"cardId":   cardId,
+		"maxTries": maxTries,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) ResetCardPinTries(ctx context.Context, cardId int64) error {
+	const query = `UPDATE cards SET "pinTries" = 0 WHERE "id" = $1`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, cardId)
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) UpdateCardStatus(ctx context.Context, cardId int64, status string) error {
+	const query = `UPDATE cards SET "status" = @status::status_card, 
+                 "pinTries" = CASE WHEN @status::status_card = 'ACTIVE' THEN 0 ELSE "pinTries" END 
+				 WHERE "id" = @cardId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"cardId": cardId,
+		"status": status,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) UpdateCardLimits(ctx context.Context, cardId, perTransactionLimitCents, dailyLimitCents int64) error {
+	const query = `UPDATE cards SET "perTransactionLimitCents" = NULLIF(@perTransactionLimitCents::BIGINT, 0), 
+                 "dailyLimitCents" = NULLIF(@dailyLimitCents::BIGINT, 0) 
+				 WHERE "id" = @cardId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"cardId":                   cardId,
+		"perTransactionLimitCents": perTransactionLimitCents,
+		"dailyLimitCents":          dailyLimitCents,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) GetCardSpentSince(ctx context.Context, cardId int64, since time.Time) (int64, error) {
+	const query = `SELECT COALESCE((SELECT SUM("amountCents") FROM "cardOperations" WHERE "cardId" = cards."id" AND "createdAt" >= @since), 0) 
+						+ COALESCE((SELECT SUM("amountCents") FROM "cardHolds" WHERE "cardId" = cards."id" AND "status" = 'ACTIVE' 
+							AND "expiresAt" > current_timestamp AND "createdAt" >= @since), 0) 
+					FROM cards WHERE "id" = @cardId FOR UPDATE`
+
+	var spentCents int64
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"cardId": cardId,
+		"since":  since,
+	}).Scan(&spentCents)
+	if err != nil {
+		return 0, s.wrapQueryError(err)
+	}
+	return spentCents, nil
+}
+
+func (s *Service) CreateCardOperation(ctx context.Context, operation web.CardOperationData) (int64, error) {
+	const query = `INSERT INTO "cardOperations" ("cardId", "type", "receiverId", "transactionId", "amountCents", "description") 
+					VALUES (@cardId, @type, @receiverId, @transactionId, @amountCents, @description) RETURNING "id"`
+
+	var operationId int64
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"cardId":        operation.CardId,
+		"type":          operation.Type,
+		"receiverId":    operation.ReceiverId,
+		"transactionId": operation.TransactionId,
+		"amountCents":   operation.AmountCents,
+		"description":   operation.Description,
+	}).Scan(&operationId)
+	if err != nil {
+		return 0, s.wrapQueryError(err)
+	}
+	return operationId, nil
+}
+
+func (s *Service) CreateCardHold(ctx context.Context, hold web.CardHoldData) (int64, error) {
+	const query = `INSERT INTO "cardHolds" ("cardId", "accountId", "receiverId", "amountCents", "description", "expiresAt") 
+					VALUES (@cardId, @accountId, @receiverId, @amountCents, @description, @expiresAt) RETURNING "id"`
+
+	var holdId int64
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"cardId":      hold.CardId,
+		"accountId":   hold.AccountId,
+		"receiverId":  hold.ReceiverId,
+		"amountCents": hold.AmountCents,
+		"description": hold.Description,
+		"expiresAt":   hold.ExpiresAt,
+	}).Scan(&holdId)
+	if err != nil {
+		return 0, s.wrapQueryError(err)
+	}
+	if err = s.createAccountEvent(ctx, s.conn(ctx), hold.AccountId, web.AccountEventBalanceChanged, 0, -hold.AmountCents); err != nil {
+		return 0, err
+	}
+	return holdId, nil
+}
+
+func (s *Service) GetCardHoldForUpdate(ctx context.Context, holdId int64) (web.CardHoldData, error) {
+	const query = `SELECT "id", "cardId", "accountId", "receiverId", "amountCents", "capturedCents", COALESCE("transactionId", 0), "status", 
+					"description", "expiresAt", "createdAt" 
+					FROM "cardHolds" WHERE "id" = $1 FOR UPDATE`
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, holdId)
+	if err := row.Err(); err != nil {
+		return web.CardHoldData{}, s.wrapQueryError(err)
+	}
+
+	var hold web.CardHoldData
+	err := row.Scan(&hold.Id, &hold.CardId, &hold.AccountId, &hold.ReceiverId, &hold.AmountCents, &hold.CapturedCents, &hold.TransactionId, &hold.Status,
+		&hold.Description, &hold.ExpiresAt, &hold.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.CardHoldData{}, cerrors.NewErrorWithUserMessage(ercodes.CardHoldNotFound, err, "Блокировка средств не найдена")
+		}
+		return web.CardHoldData{}, s.wrapScanError(err)
+	}
+	return hold, nil
+}
+
+func (s *Service) UpdateCardHold(ctx context.Context, hold web.CardHoldData) error {
+	const query = `UPDATE "cardHolds" SET "status" = @status, "capturedCents" = @capturedCents, 
+                 "transactionId" = NULLIF(@transactionId::BIGINT, 0), "updatedAt" = current_timestamp 
+				 WHERE "id" = @holdId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"holdId":        hold.Id,
+		"status":        hold.Status,
+		"capturedCents": hold.CapturedCents,
+		"transactionId": hold.TransactionId,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	if hold.Status == web.CardHoldStatusActive || hold.TransactionId != 0 {
+		return nil
+	}
+	return s.createAccountEvent(ctx, s.conn(ctx), hold.AccountId, web.AccountEventBalanceChanged, 0, hold.AmountCents-hold.CapturedCents)
+}
+
+func (s *Service) ExpireCardHolds(ctx context.Context) error {
+	const query = `WITH expired AS (
+						UPDATE "cardHolds" SET "status" = @expiredStatus, "updatedAt" = current_timestamp 
+						WHERE "status" = @activeStatus AND "expiresAt" <= current_timestamp 
+						RETURNING "accountId", "amountCents"
+					), event AS (
+						INSERT INTO "accountEvents" ("accountId", "userId", "type", "amountCents", "balanceCents") 
+						SELECT accounts."id", "accountOwners"."userId", 'BALANCE_CHANGED', expired."amountCents", accounts."balanceCents" 
+						FROM expired 
+						INNER JOIN accounts ON expired."accountId" = accounts."id" 
+						LEFT JOIN "accountOwners" ON accounts."ownerId" = "accountOwners"."id" 
+						RETURNING "id", "userId"
+					) 
+					SELECT pg_notify('` + accountEventsChannel + `', event."userId" || ':' || event."id") FROM event WHERE event."userId" IS NOT NULL`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"expiredStatus": web.CardHoldStatusExpired,
+		"activeStatus":  web.CardHoldStatusActive,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"time"
+	"x-bank-ms-bank/core/web"
+)
+
+const cashOperationColumns = `id, "atmAccountId", COALESCE("userAccountId", 0), "amountCents", COALESCE("cashAfterCents", 0),
+					COALESCE("balanceAfterCents", 0), "cashAfterCents" IS NOT NULL AND "balanceAfterCents" IS NOT NULL, "createdAt"`
+
+func (s *Service) GetCashOperations(ctx context.Context, atmId int64, from, to time.Time) ([]web.CashOperationData, error) {
+	const query = `SELECT ` + cashOperationColumns + ` FROM "cashOperations"
+					WHERE "atmAccountId" = @atmId AND "createdAt" >= @from AND "createdAt" < @to
+					ORDER BY "createdAt", id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, pgx.NamedArgs{
+		"atmId": atmId,
+		"from":  from,
+		"to":    to,
+	})
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var operations []web.CashOperationData
+	for rows.Next() {
+		var data web.CashOperationData
+		if err = rows.Scan(&data.Id, &data.AtmId, &data.UserAccountId, &data.AmountCents, &data.CashAfterCents, &data.BalanceAfterCents, &data.HasSnapshot, &data.CreatedAt); err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		operations = append(operations, data)
+	}
+	return operations, nil
+}
+
+func (s *Service) GetLastCashOperationBefore(ctx context.Context, atmId int64, before time.Time) (web.CashOperationData, error) {
+	const query = `SELECT ` + cashOperationColumns + ` FROM "cashOperations"
+					WHERE "atmAccountId" = @atmId AND "createdAt" < @before
+						AND "cashAfterCents" IS NOT NULL AND "balanceAfterCents" IS NOT NULL
+					ORDER BY "createdAt" DESC, id DESC LIMIT 1`
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"atmId":  atmId,
+		"before": before,
+	})
+	if err := row.Err(); err != nil {
+		return web.CashOperationData{}, s.wrapQueryError(err)
+	}
+
+	var data web.CashOperationData
+	if err := row.Scan(&data.Id, &data.AtmId, &data.UserAccountId, &data.AmountCents, &data.CashAfterCents, &data.BalanceAfterCents, &data.HasSnapshot, &data.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.CashOperationData{}, nil
+		}
+		return web.CashOperationData{}, s.wrapScanError(err)
+	}
+	return data, nil
+}
+
+func (s *Service) GetAccountTransfersSums(ctx context.Context, accountId int64, from, to time.Time) (int64, int64, error) {
+	const query = `SELECT
+						COALESCE(SUM("amountCents") FILTER (WHERE "receiverId" = @accountId AND status = 'CONFIRMED'
+							AND "confirmedAt" > @from AND "confirmedAt" <= @to), 0),
+						COALESCE(SUM("amountCents") FILTER (WHERE "senderId" = @accountId
+							AND "createdAt" > @from AND "createdAt" <= @to), 0)
+					FROM transactions
+					WHERE "senderId" = @accountId OR "receiverId" = @accountId`
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"accountId": accountId,
+		"from":      from,
+		"to":        to,
+	})
+	if err := row.Err(); err != nil {
+		return 0, 0, s.wrapQueryError(err)
+	}
+
+	var inCents, outCents int64
+	if err := row.Scan(&inCents, &outCents); err != nil {
+		return 0, 0, s.wrapScanError(err)
+	}
+	return inCents, outCents, nil
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) CreatePaymentFile(ctx context.Context, fileData web.PaymentFileData) (int64, error) {
+	var fileId int64
+	err := s.WithinTransaction(ctx, func(ctx context.Context) error {
+		const queryFile = `INSERT INTO "paymentFiles" ("senderId", "userId", "format", "hash", "status", "itemsCount", "totalCents") 
+							VALUES (@senderId, @userId, @format, @hash, @status, @itemsCount, @totalCents) RETURNING id`
+		err := s.conn(ctx).QueryRowContext(ctx, queryFile, pgx.NamedArgs{
+			"senderId":   fileData.SenderId,
+			"userId":     fileData.UserId,
+			"format":     fileData.Format,
+			"hash":       fileData.Hash,
+			"status":     fileData.Status,
+			"itemsCount": fileData.ItemsCount,
+			"totalCents": fileData.TotalCents,
+		}).Scan(&fileId)
+		if err != nil {
+			if s.isUniqueViolation(err) {
+				return cerrors.NewErrorWithUserMessage(ercodes.DuplicatePaymentFile, err, "Этот файл уже был загружен")
+			}
+			return s.wrapQueryError(err)
+		}
+
+		const queryItem = `INSERT INTO "paymentFileItems" ("fileId", "lineNo", "receiver", "receiverId", "amountCents", "description", "status", "error") 
+							VALUES (@fileId, @lineNo, @receiver, NULLIF(@receiverId, 0), @amountCents, @description, @status, @error)`
+		for _, item := range fileData.Items {
+			_, err = s.conn(ctx).ExecContext(ctx, queryItem, pgx.NamedArgs{
+				"fileId":      fileId,
+				"lineNo":      item.LineNo,
+				"receiver":    item.Receiver,
+				"receiverId":  item.ReceiverId,
+				"amountCents": item.AmountCents,
+				"description": item.Description,
+				"status":      item.Status,
+				"error":       item.Error,
+			})
+			if err != nil {
+				return s.wrapQueryError(err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return fileId, nil
+}
+
+func (s *Service) GetPaymentFile(ctx context.Context, fileId int64) (web.PaymentFileData, error) {
+	const queryFile = `SELECT "id", "senderId", "userId", "format", "hash", "status", "itemsCount", "totalCents", "createdAt", "updatedAt" 
+						FROM "paymentFiles" WHERE "id" = $1`
+
+	row := s.db.QueryRowContext(ctx, queryFile, fileId)
+	if err := row.Err(); err != nil {
+		return web.PaymentFileData{}, s.wrapQueryError(err)
+	}
+
+	var fileData web.PaymentFileData
+	err := row.Scan(&fileData.Id, &fileData.SenderId, &fileData.UserId, &fileData.Format, &fileData.Hash, &fileData.Status,
+		&fileData.ItemsCount, &fileData.TotalCents, &fileData.CreatedAt, &fileData.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.PaymentFileData{}, cerrors.NewErrorWithUserMessage(ercodes.PaymentFileNotFound, err, "Файл платежей не найден")
+		}
+		return web.PaymentFileData{}, s.wrapScanError(err)
+	}
+
+	const queryItems = `SELECT "id", "lineNo", "receiver", COALESCE("receiverId", 0), "amountCents", "description", "status", "error", COALESCE("transactionId", 0) 
+						FROM "paymentFileItems" WHERE "fileId" = $1 ORDER BY "lineNo"`
+	rows, err := s.db.QueryContext(ctx, queryItems, fileId)
+	if err != nil {
+		return web.PaymentFileData{}, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var item web.PaymentFileItem
+		err = rows.Scan(&item.Id, &item.LineNo, &item.Receiver, &item.ReceiverId, &item.AmountCents, &item.Description, &item.Status, &item.Error, &item.TransactionId)
+		if err != nil {
+			return web.PaymentFileData{}, s.wrapScanError(err)
+		}
+		fileData.Items = append(fileData.Items, item)
+	}
+
+	return fileData, nil
+}
+
+func (s *Service) GetPaymentFileIdsByStatus(ctx context.Context, status string) ([]int64, error) {
+	const query = `SELECT "id" FROM "paymentFiles" WHERE "status" = $1 ORDER BY "id"`
+
+	rows, err := s.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var fileIds []int64
+	for rows.Next() {
+		var fileId int64
+		if err = rows.Scan(&fileId); err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		fileIds = append(fileIds, fileId)
+	}
+	return fileIds, nil
+}
+
+func (s *Service) UpdatePaymentFileStatus(ctx context.Context, fileId int64, status string) error {
+	const query = `UPDATE "paymentFiles" SET "status" = @status, "updatedAt" = current_timestamp WHERE "id" = @fileId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"fileId": fileId,
+		"status": status,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) ClaimPaymentFileItem(ctx context.Context, itemId int64) (bool, error) {
+	const query = `UPDATE "paymentFileItems" SET "status" = 'PROCESSING' WHERE "id" = $1 AND "status" = 'PENDING'`
+
+	result, err := s.conn(ctx).ExecContext(ctx, query, itemId)
+	if err != nil {
+		return false, s.wrapQueryError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, s.wrapQueryError(err)
+	}
+	return affected != 0, nil
+}
+
+func (s *Service) UpdatePaymentFileItem(ctx context.Context, itemId int64, status string, transactionId int64, errorMessage string) error {
+	const query = `UPDATE "paymentFileItems" SET "status" = @status, "transactionId" = NULLIF(@transactionId, 0), "error" = @error WHERE "id" = @itemId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"itemId":        itemId,
+		"status":        status,
+		"transactionId": transactionId,
+		"error":         errorMessage,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+const paymentRequestColumns = `"paymentRequests"."id", "requesterAccountId", "payerAccountId", "paymentRequests"."amountCents", "paymentRequests"."description",
+	CASE WHEN "paymentRequests"."status" = 'PENDING' AND "expiresAt" <= current_timestamp THEN 'EXPIRED' ELSE "paymentRequests"."status"::text END,
+	"expiresAt", "paymentRequests"."createdAt", COALESCE("transactionId", 0)`
+
+func (s *Service) CreatePaymentRequest(ctx context.Context, requesterAccountId, payerAccountId, amountCents int64, description string, ttl time.Duration) (int64, error) {
+	const query = `INSERT INTO "paymentRequests" ("requesterAccountId", "payerAccountId", "amountCents", "description", "expiresAt") 
+					VALUES (@requesterAccountId, @payerAccountId, @amountCents, @description, current_timestamp + @ttl) RETURNING id`
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"requesterAccountId": requesterAccountId,
+		"payerAccountId":     payerAccountId,
+		"amountCents":        amountCents,
+		"description":        description,
+		"ttl":                ttl,
+	})
+	if err := row.Err(); err != nil {
+		return 0, s.wrapQueryError(err)
+	}
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		return 0, s.wrapScanError(err)
+	}
+	return id, nil
+}
+
+func (s *Service) GetPaymentRequest(ctx context.Context, requestId int64) (web.PaymentRequestData, error) {
+	const query = `SELECT ` + paymentRequestColumns + ` FROM "paymentRequests" WHERE "id" = $1`
+
+	row := s.db.QueryRowContext(ctx, query, requestId)
+	if err := row.Err(); err != nil {
+		return web.PaymentRequestData{}, s.wrapQueryError(err)
+	}
+
+	data, err := s.scanPaymentRequest(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.PaymentRequestData{}, cerrors.NewErrorWithUserMessage(ercodes.PaymentRequestNotFound, err, "Запрос денег не найден")
+		}
+		return web.PaymentRequestData{}, s.wrapScanError(err)
+	}
+	return data, nil
+}
+
+func (s *Service) GetUserPaymentRequests(ctx context.Context, userId int64, incoming bool, limit, offset int64) ([]web.PaymentRequestData, int64, error) {
+	accountColumn := `"requesterAccountId"`
+	if incoming {
+		accountColumn = `"payerAccountId"`
+	}
+	condition := ` FROM "paymentRequests" 
+					INNER JOIN accounts ON "paymentRequests".` + accountColumn + ` = accounts.id
+					INNER JOIN "accountOwners" ON accounts."ownerId" = "accountOwners".id
+					WHERE "accountOwners"."userId" = @userId`
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+paymentRequestColumns+condition+` ORDER BY "paymentRequests"."createdAt" DESC LIMIT @limit OFFSET @offset`, pgx.NamedArgs{
+		"userId": userId,
+		"limit":  limit,
+		"offset": offset,
+	})
+	if err != nil {
+		return nil, 0, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var paymentRequestsData []web.PaymentRequestData
+	for rows.Next() {
+		data, err := s.scanPaymentRequest(rows)
+		if err != nil {
+			return nil, 0, s.wrapScanError(err)
+		}
+		paymentRequestsData = append(paymentRequestsData, data)
+	}
+
+	row := s.db.QueryRowContext(ctx, `SELECT COUNT("paymentRequests"."id")`+condition, pgx.NamedArgs{
+		"userId": userId,
+	})
+	if err = row.Err(); err != nil {
+		return nil, 0, s.wrapQueryError(err)
+	}
+	var total int64
+	if err = row.Scan(&total); err != nil {
+		return nil, 0, s.wrapScanError(err)
+	}
+
+	return paymentRequestsData, total, nil
+}
+
+func (s *Service) ClaimPaymentRequest(ctx context.Context, requestId int64, status string) error {
+	const query = `UPDATE "paymentRequests" SET "status" = @status 
+                         WHERE "id" = @requestId AND "status" = 'PENDING' AND "expiresAt" > current_timestamp`
+
+	result, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"requestId": requestId,
+		"status":    status,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return s.checkPaymentRequestAffected(result)
+}
+
+func (s *Service) LinkPaymentRequestTransaction(ctx context.Context, requestId, transactionId int64) error {
+	const query = `UPDATE "paymentRequests" SET "transactionId" = @transactionId WHERE "id" = @requestId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"requestId":     requestId,
+		"transactionId": transactionId,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) checkPaymentRequestAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	if affected == 0 {
+		return cerrors.NewErrorWithUserMessage(ercodes.PaymentRequestNotPending, nil, "Запрос денег уже обработан или истёк")
+	}
+	return nil
+}
+
+func (s *Service) scanPaymentRequest(row interface{ Scan(dest ...any) error }) (web.PaymentRequestData, error) {
+	var data web.PaymentRequestData
+	err := row.Scan(&data.Id, &data.RequesterAccountId, &data.PayerAccountId, &data.AmountCents, &data.Description,
+		&data.Status, &data.ExpiresAt, &data.CreatedAt, &data.TransactionId)
+	return data, err
+}
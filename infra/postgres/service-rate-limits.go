@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"math"
+	"time"
+)
+
+func (s *Service) TakeRateLimitToken(ctx context.Context, key string, ratePerSecond float64, burst int) (allowed bool, retryAfter time.Duration, err error) {
+	err = s.WithinTransaction(ctx, func(ctx context.Context) error {
+		const refillQuery = `INSERT INTO "rateLimitBuckets" AS bucket ("key", "tokens", "ratePerSecond", "burst", "updatedAt") 
+						VALUES (@key, @burst, @ratePerSecond, @burst, clock_timestamp())
+						ON CONFLICT ("key") DO UPDATE SET 
+						    "tokens" = LEAST(EXCLUDED."burst", bucket."tokens" + EXTRACT(EPOCH FROM clock_timestamp() - bucket."updatedAt") * EXCLUDED."ratePerSecond"),
+						    "ratePerSecond" = EXCLUDED."ratePerSecond",
+						    "burst" = EXCLUDED."burst",
+						    "updatedAt" = clock_timestamp()
+						RETURNING "tokens"`
+
+		row := s.conn(ctx).QueryRowContext(ctx, refillQuery, pgx.NamedArgs{
+			"key":           key,
+			"ratePerSecond": ratePerSecond,
+			"burst":         burst,
+		})
+		if err := row.Err(); err != nil {
+			return s.wrapQueryError(err)
+		}
+
+		var tokens float64
+		if err := row.Scan(&tokens); err != nil {
+			return s.wrapScanError(err)
+		}
+		if tokens < 1 {
+			retryAfter = time.Duration(math.Ceil((1 - tokens) / ratePerSecond * float64(time.Second)))
+			return nil
+		}
+
+		const takeQuery = `UPDATE "rateLimitBuckets" SET "tokens" = "tokens" - 1 WHERE "key" = $1`
+		if _, err := s.conn(ctx).ExecContext(ctx, takeQuery, key); err != nil {
+			return s.wrapQueryError(err)
+		}
+		allowed = true
+		return nil
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return allowed, retryAfter, nil
+}
+
+func (s *Service) DeleteFullRateLimitBuckets(ctx context.Context) error {
+	const query = `DELETE FROM "rateLimitBuckets" 
+					WHERE "tokens" + EXTRACT(EPOCH FROM clock_timestamp() - "updatedAt") * "ratePerSecond" >= "burst"`
+
+	if _, err := s.db.ExecContext(ctx, query); err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
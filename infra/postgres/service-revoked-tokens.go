@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (s *Service) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	const query = `SELECT 1 FROM "revokedTokens" WHERE "jti" = $1 AND "expiresAt" > current_timestamp`
+
+	row := s.db.QueryRowContext(ctx, query, jti)
+	if err := row.Err(); err != nil {
+		return false, s.wrapQueryError(err)
+	}
+
+	var found int
+	if err := row.Scan(&found); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, s.wrapScanError(err)
+	}
+	return true, nil
+}
+
+func (s *Service) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	const deleteExpiredQuery = `DELETE FROM "revokedTokens" WHERE "expiresAt" <= current_timestamp`
+	if _, err := s.conn(ctx).ExecContext(ctx, deleteExpiredQuery); err != nil {
+		return s.wrapQueryError(err)
+	}
+
+	const query = `INSERT INTO "revokedTokens" ("jti", "expiresAt") VALUES ($1, $2) 
+					ON CONFLICT ("jti") DO UPDATE SET "expiresAt" = GREATEST("revokedTokens"."expiresAt", EXCLUDED."expiresAt")`
+	if _, err := s.conn(ctx).ExecContext(ctx, query, jti, expiresAt); err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"x-bank-ms-bank/core/web"
+)
+
+func (s *Service) GetAccountOwnerNames(ctx context.Context, accountId int64) ([]string, error) {
+	const query = `SELECT DISTINCT "ownerName" FROM "paymentAliases" WHERE "accountId" = $1`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, accountId)
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+func (s *Service) CreateScreeningHits(ctx context.Context, hits []web.ScreeningHitData) error {
+	const query = `INSERT INTO "screeningHits" ("operation", "userId", "accountId", "transactionId", "listType", "entry") 
+					VALUES (@operation, NULLIF(@userId::BIGINT, 0), @accountId, NULLIF(@transactionId::BIGINT, 0), @listType, @entry)`
+
+	return s.WithinTransaction(ctx, func(ctx context.Context) error {
+		tx := s.conn(ctx)
+		for _, hit := range hits {
+			_, err := tx.ExecContext(ctx, query, pgx.NamedArgs{
+				"operation":     hit.Operation,
+				"userId":        hit.UserId,
+				"accountId":     hit.AccountId,
+				"transactionId": hit.TransactionId,
+				"listType":      hit.ListType,
+				"entry":         hit.Entry,
+			})
+			if err != nil {
+				return s.wrapQueryError(err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) HasUserTransfersTo(ctx context.Context, userId, receiverId int64) (bool, error) {
+	const query = `SELECT EXISTS(SELECT 1 FROM transactions 
+						INNER JOIN accounts ON transactions."senderId" = accounts."id" 
+						INNER JOIN "accountOwners" ON accounts."ownerId" = "accountOwners"."id" 
+						WHERE "accountOwners"."userId" = $1 AND transactions."receiverId" = $2 AND transactions."status" <> 'CANCELLED')`
+
+	var exists bool
+	if err := s.conn(ctx).QueryRowContext(ctx, query, userId, receiverId).Scan(&exists); err != nil {
+		return false, s.wrapQueryError(err)
+	}
+	return exists, nil
+}
+
+func (s *Service) CreateStepUpChallenge(ctx context.Context, challenge web.StepUpChallengeData) (int64, error) {
+	const query = `INSERT INTO "stepUpChallenges" ("operation", "userId", "senderId", "receiverId", "amountCents", "description", "operationHash", "codeHash", "status", "expiresAt") 
+					VALUES (@operation, @userId, @senderId, NULLIF(@receiverId::BIGINT, 0), @amountCents, @description, @operationHash, @codeHash, @status, @expiresAt) RETURNING "id"`
+
+	var challengeId int64
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"operation":     challenge.Operation,
+		"userId":        challenge.UserId,
+		"senderId":      challenge.SenderId,
+		"receiverId":    challenge.ReceiverId,
+		"amountCents":   challenge.AmountCents,
+		"description":   challenge.Description,
+		"operationHash": challenge.OperationHash,
+		"codeHash":      challenge.CodeHash,
+		"status":        challenge.Status,
+		"expiresAt":     challenge.ExpiresAt,
+	}).Scan(&challengeId)
+	if err != nil {
+		return 0, s.wrapQueryError(err)
+	}
+	return challengeId, nil
+}
+
+func (s *Service) GetStepUpChallengeForUpdate(ctx context.Context, challengeId int64) (web.StepUpChallengeData, error) {
+	const query = `SELECT "id", "operation", "userId", "senderId", COALESCE("receiverId", 0), "amountCents", "description", "operationHash", "codeHash", 
+					COALESCE("tokenHash", ''::BYTEA), "attempts", "status", "expiresAt", "createdAt" 
+					FROM "stepUpChallenges" WHERE "id" = $1 FOR UPDATE`
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, challengeId)
+	if err := row.Err(); err != nil {
+		return web.StepUpChallengeData{}, s.wrapQueryError(err)
+	}
+
+	var challenge web.StepUpChallengeData
+	err := row.Scan(&challenge.Id, &challenge.Operation, &challenge.UserId, &challenge.SenderId, &challenge.ReceiverId, &challenge.AmountCents, &challenge.Description,
+		&challenge.OperationHash, &challenge.CodeHash, &challenge.TokenHash, &challenge.Attempts, &challenge.Status, &challenge.ExpiresAt, &challenge.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.StepUpChallengeData{}, cerrors.NewErrorWithUserMessage(ercodes.StepUpChallengeNotFound, err, "Подтверждение не найдено")
+		}
+		return web.StepUpChallengeData{}, s.wrapScanError(err)
+	}
+	return challenge, nil
+}
+
+func (s *Service) UpdateStepUpChallenge(ctx context.Context, challenge web.StepUpChallengeData) error {
+	const query = `UPDATE "stepUpChallenges" SET "status" = @status, "attempts" = @attempts, "tokenHash" = @tokenHash, 
+                 "expiresAt" = @expiresAt, "updatedAt" = current_timestamp 
+				 WHERE "id" = @challengeId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"challengeId": challenge.Id,
+		"status":      challenge.Status,
+		"attempts":    challenge.Attempts,
+		"tokenHash":   challenge.TokenHash,
+		"expiresAt":   challenge.ExpiresAt,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
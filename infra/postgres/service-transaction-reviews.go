@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
+)
+
+func (s *Service) GetTransferStats(ctx context.Context, senderId, receiverId int64, velocitySince, historySince, roundTripSince time.Time) (web.TransferStatsData, error) {
+	const query = `SELECT 
+       				COUNT(*) FILTER (WHERE "senderId" = @senderId AND "createdAt" >= @velocitySince), 
+       				COUNT(*) FILTER (WHERE "senderId" = @senderId AND "createdAt" >= @historySince), 
+       				COALESCE(SUM("amountCents") FILTER (WHERE "senderId" = @senderId AND "createdAt" >= @historySince), 0), 
+       				COALESCE(BOOL_OR("senderId" = @senderId AND "receiverId" = @receiverId), false), 
+       				COALESCE(BOOL_OR("senderId" = @receiverId AND "receiverId" = @senderId AND "createdAt" >= @roundTripSince), false) 
+					FROM transactions 
+					WHERE ("senderId" = @senderId OR ("senderId" = @receiverId AND "receiverId" = @senderId)) AND "status" <> 'CANCELLED'`
+
+	var stats web.TransferStatsData
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"senderId":       senderId,
+		"receiverId":     receiverId,
+		"velocitySince":  velocitySince,
+		"historySince":   historySince,
+		"roundTripSince": roundTripSince,
+	}).Scan(&stats.RecentCount, &stats.HistoryCount, &stats.HistorySumCents, &stats.HasTransfersTo, &stats.HasRoundTrip)
+	if err != nil {
+		return web.TransferStatsData{}, s.wrapQueryError(err)
+	}
+	return stats, nil
+}
+
+func (s *Service) HoldTransactionForReview(ctx context.Context, transactionId int64, score int, reasons []string) error {
+	return s.WithinTransaction(ctx, func(ctx context.Context) error {
+		tx := s.conn(ctx)
+
+		const queryTransaction = `UPDATE transactions SET "status" = 'REVIEW' WHERE "id" = $1 AND "status" = 'BLOCKED'`
+		if _, err := tx.ExecContext(ctx, queryTransaction, transactionId); err != nil {
+			return s.wrapQueryError(err)
+		}
+
+		const queryReview = `INSERT INTO "transactionReviews" ("transactionId", "score", "reasons") VALUES (@transactionId, @score, @reasons)`
+		_, err := tx.ExecContext(ctx, queryReview, pgx.NamedArgs{
+			"transactionId": transactionId,
+			"score":         score,
+			"reasons":       strings.Join(reasons, ","),
+		})
+		if err != nil {
+			return s.wrapQueryError(err)
+		}
+		return nil
+	})
+}
+
+const transactionReviewColumns = `"transactionReviews"."transactionId", transactions."senderId", transactions."receiverId", transactions."amountCents", 
+       				COALESCE(transactions.description, ''), "transactionReviews"."score", "transactionReviews"."reasons", "transactionReviews"."status", 
+       				COALESCE("transactionReviews"."reviewerId", 0), "transactionReviews"."createdAt", COALESCE("transactionReviews"."reviewedAt", '0001-01-01'::TIMESTAMP)`
+
+func (s *Service) GetTransactionReviews(ctx context.Context, status string) ([]web.TransactionReviewData, error) {
+	const query = `SELECT ` + transactionReviewColumns + ` FROM "transactionReviews" 
+					INNER JOIN transactions ON "transactionReviews"."transactionId" = transactions."id" 
+					WHERE "transactionReviews"."status" = $1 ORDER BY "transactionReviews"."createdAt"`
+
+	rows, err := s.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, s.wrapQueryError(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var reviews []web.TransactionReviewData
+	for rows.Next() {
+		review, err := s.scanTransactionReview(rows)
+		if err != nil {
+			return nil, s.wrapScanError(err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+func (s *Service) GetTransactionReviewForUpdate(ctx context.Context, transactionId int64) (web.TransactionReviewData, error) {
+	const query = `SELECT ` + transactionReviewColumns + ` FROM "transactionReviews" 
+					INNER JOIN transactions ON "transactionReviews"."transactionId" = transactions."id" 
+					WHERE "transactionReviews"."transactionId" = $1 FOR UPDATE`
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, transactionId)
+	if err := row.Err(); err != nil {
+		return web.TransactionReviewData{}, s.wrapQueryError(err)
+	}
+
+	review, err := s.scanTransactionReview(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.TransactionReviewData{}, cerrors.NewErrorWithUserMessage(ercodes.TransactionReviewNotFound, err, "Перевод на проверке не найден")
+		}
+		return web.TransactionReviewData{}, s.wrapScanError(err)
+	}
+	return review, nil
+}
+
+func (s *Service) ApproveTransactionReview(ctx context.Context, transactionId, reviewerId int64) error {
+	return s.WithinTransaction(ctx, func(ctx context.Context) error {
+		tx := s.conn(ctx)
+		if err := s.finishTransactionReview(ctx, tx, transactionId, reviewerId, web.TransactionReviewStatusApproved); err != nil {
+			return err
+		}
+
+		const query = `UPDATE transactions SET "status" = 'BLOCKED' WHERE "id" = $1 AND "status" = 'REVIEW'`
+		if _, err := tx.ExecContext(ctx, query, transactionId); err != nil {
+			return s.wrapQueryError(err)
+		}
+		return nil
+	})
+}
+
+func (s *Service) RejectTransactionReview(ctx context.Context, transactionId, reviewerId int64) error {
+	return s.WithinTransaction(ctx, func(ctx context.Context) error {
+		tx := s.conn(ctx)
+		if err := s.finishTransactionReview(ctx, tx, transactionId, reviewerId, web.TransactionReviewStatusRejected); err != nil {
+			return err
+		}
+
+		const queryTransaction = `UPDATE transactions SET "status" = 'CANCELLED' WHERE "id" = $1 AND "status" = 'REVIEW' RETURNING "senderId", "amountCents"`
+		var senderId, amountCents int64
+		if err := tx.QueryRowContext(ctx, queryTransaction, transactionId).Scan(&senderId, &amountCents); err != nil {
+			return s.wrapQueryError(err)
+		}
+
+		const querySenderUpdate = `UPDATE accounts SET "balanceCents" = "balanceCents" + @amountCents WHERE id = @senderId`
+		_, err := tx.ExecContext(ctx, querySenderUpdate, pgx.NamedArgs{
+			"amountCents": amountCents,
+			"senderId":    senderId,
+		})
+		if err != nil {
+			return s.wrapQueryError(err)
+		}
+		return s.createAccountEvent(ctx, tx, senderId, web.AccountEventBalanceChanged, transactionId, amountCents)
+	})
+}
+
+func (s *Service) finishTransactionReview(ctx context.Context, q querier, transactionId, reviewerId int64, status string) error {
+	const query = `UPDATE "transactionReviews" SET "status" = @status, "reviewerId" = @reviewerId, "reviewedAt" = current_timestamp 
+					WHERE "transactionId" = @transactionId`
+
+	_, err := q.ExecContext(ctx, query, pgx.NamedArgs{
+		"transactionId": transactionId,
+		"reviewerId":    reviewerId,
+		"status":        status,
+	})
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) scanTransactionReview(row interface{ Scan(dest ...any) error }) (web.TransactionReviewData, error) {
+	var (
+		review  web.TransactionReviewData
+		reasons string
+	)
+	err := row.Scan(&review.TransactionId, &review.SenderId, &review.ReceiverId, &review.AmountCents, &review.Description, &review.Score,
+		&reasons, &review.Status, &review.ReviewerId, &review.CreatedAt, &review.ReviewedAt)
+	if err != nil {
+		return web.TransactionReviewData{}, err
+	}
+	if reasons != "" {
+		review.Reasons = strings.Split(reasons, ",")
+	}
+	return review, nil
+}
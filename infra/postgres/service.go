@@ -8,8 +8,10 @@ import (
 	"github.com/jackc/pgx/v5"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"time"
+	"x-bank-ms-bank/cerrors"
 	transaction_manager "x-bank-ms-bank/core/transaction-manager"
 	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/ercodes"
 )
 
 type (
@@ -18,6 +20,12 @@ type (
 	}
 )
 
+const accountBalanceColumns = `accounts."balanceCents", 
+    accounts."balanceCents" - COALESCE((SELECT SUM("cardHolds"."amountCents") FROM "cardHolds" 
+        WHERE "cardHolds"."accountId" = accounts."id" AND "cardHolds"."status" = 'ACTIVE' AND "cardHolds"."expiresAt" > current_timestamp), 0), 
+    COALESCE((SELECT SUM(pending."amountCents") FROM transactions pending WHERE pending."receiverId" = accounts."id" AND pending."status" IN ('BLOCKED', 'REVIEW')), 0), 
+    COALESCE((SELECT SUM(pending."amountCents") FROM transactions pending WHERE pending."senderId" = accounts."id" AND pending."status" IN ('BLOCKED', 'REVIEW')), 0)`
+
 func NewService(login, password, host string, port int, database string, maxCons int) (Service, error) {
 	db, err := sql.Open("pgx", fmt.Sprintf("postgres://%s:%s@%s:%d/%s", login, password, host, port, database))
 	if err != nil {
@@ -36,7 +44,7 @@ func NewService(login, password, host string, port int, database string, maxCons
 }
 
 func (s *Service) GetUserAccounts(ctx context.Context, userId int64) ([]web.UserAccountData, error) {
-	const query = `SELECT accounts."id", "balanceCents", "status" FROM accounts LEFT JOIN "accountOwners" ON "ownerId" = "accountOwners".id WHERE "userId" = $1`
+	const query = `SELECT accounts."id", accounts."number", ` + accountBalanceColumns + `, "status" FROM accounts LEFT JOIN "accountOwners" ON "ownerId" = "accountOwners".id WHERE "userId" = $1`
 
 	rows, err := s.db.QueryContext(ctx, query, userId)
 
@@ -47,19 +55,20 @@ func (s *Service) GetUserAccounts(ctx context.Context, userId int64) ([]web.User
 	var userAccountsData []web.UserAccountData
 	for rows.Next() {
 		var data web.UserAccountData
-		if err = rows.Scan(&data.Id, &data.BalanceCents, &data.Status); err != nil {
+		if err = rows.Scan(&data.Id, &data.Number, &data.BalanceCents, &data.AvailableCents, &data.PendingIncomingCents, &data.PendingOutgoingCents, &data.Status); err != nil {
 			return nil, s.wrapScanError(err)
 		}
+		data.BookedCents = data.BalanceCents + data.PendingOutgoingCents
 		userAccountsData = append(userAccountsData, data)
 	}
 
 	return userAccountsData, nil
 }
 
-func (s *Service) OpenUserAccount(ctx context.Context, userId int64) error {
+func (s *Service) OpenUserAccount(ctx context.Context, userId int64, number string) error {
 	const query = `SELECT "id" FROM "accountOwners" WHERE "userId" = $1`
 
-	row := s.db.QueryRowContext(ctx, query, userId)
+	row := s.conn(ctx).QueryRowContext(ctx, query, userId)
 	if err := row.Err(); err != nil {
 		return s.wrapQueryError(err)
 	}
@@ -76,9 +85,12 @@ func (s *Service) OpenUserAccount(ctx context.Context, userId int64) error {
 		}
 	}
 
-	const openAccountQuery = `INSERT INTO accounts ("ownerId") VALUES ($1)`
-	_, err := s.db.ExecContext(ctx, openAccountQuery, accountOwnerId)
+	const openAccountQuery = `INSERT INTO accounts ("ownerId", "number") VALUES ($1, $2)`
+	_, err := s.conn(ctx).ExecContext(ctx, openAccountQuery, accountOwnerId, number)
 	if err != nil {
+		if s.isUniqueViolation(err) {
+			return cerrors.NewErrorWithUserMessage(ercodes.AccountNumberExists, err, "Номер счёта уже занят")
+		}
 		return s.wrapQueryError(err)
 	}
 	return nil
@@ -87,7 +99,7 @@ func (s *Service) OpenUserAccount(ctx context.Context, userId int64) error {
 func (s *Service) createAccountOwner(ctx context.Context, userId int64) (int64, error) {
 	const query = `INSERT INTO "accountOwners" ("userId") VALUES ($1) RETURNING id`
 
-	row := s.db.QueryRowContext(ctx, query, userId)
+	row := s.conn(ctx).QueryRowContext(ctx, query, userId)
 	if err := row.Err(); err != nil {
 		return 0, s.wrapQueryError(err)
 	}
@@ -99,10 +111,39 @@ func (s *Service) createAccountOwner(ctx context.Context, userId int64) (int64,
 	return id, nil
 }
 
+func (s *Service) SetUserIdentified(ctx context.Context, userId int64, identification web.OwnerIdentificationData) (web.OwnerIdentificationData, bool, bool, error) {
+	const query = `WITH previous AS (
+						SELECT "id", "personalDataVerified", "fullName" FROM "accountOwners" WHERE "userId" = @userId FOR UPDATE
+					), updated AS (
+						UPDATE "accountOwners" SET "personalDataVerified" = @identified, "fullName" = NULLIF(@fullName, '') FROM previous 
+						WHERE "accountOwners"."id" = previous."id" 
+						AND (previous."personalDataVerified" <> @identified OR previous."fullName" IS DISTINCT FROM NULLIF(@fullName, '')) 
+						RETURNING "accountOwners"."id"
+					)
+					SELECT previous."personalDataVerified", COALESCE(previous."fullName", ''), EXISTS(SELECT 1 FROM updated) FROM previous`
+
+	var (
+		previous web.OwnerIdentificationData
+		changed  bool
+	)
+	err := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"userId":     userId,
+		"identified": identification.Identified,
+		"fullName":   identification.FullName,
+	}).Scan(&previous.Identified, &previous.FullName, &changed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.OwnerIdentificationData{}, false, false, nil
+		}
+		return web.OwnerIdentificationData{}, false, false, s.wrapQueryError(err)
+	}
+	return previous, true, changed, nil
+}
+
 func (s *Service) BlockUserAccount(ctx context.Context, accountId int64) error {
 	const query = `UPDATE accounts SET status = 'BLOCKED' WHERE id = $1`
 
-	_, err := s.db.ExecContext(ctx, query, accountId)
+	_, err := s.conn(ctx).ExecContext(ctx, query, accountId)
 	if err != nil {
 		return s.wrapQueryError(err)
 	}
@@ -150,96 +191,123 @@ func (s *Service) GetAccountHistory(ctx context.Context, accountId, limit, offse
 }
 
 func (s *Service) GetAccountDataById(ctx context.Context, senderId int64) (web.UserAccountData, error) {
-	const accountQuery = `SELECT accounts."balanceCents", accounts."status", COALESCE("accountOwners"."userId", 0) FROM accounts 
+	const accountQuery = `SELECT accounts."id", accounts."number", ` + accountBalanceColumns + `, accounts."status", COALESCE("accountOwners"."userId", 0), 
+    COALESCE("accountOwners"."personalDataVerified", true), COALESCE("accountOwners"."fullName", '') FROM accounts 
     LEFT JOIN "accountOwners" ON accounts."ownerId" = "accountOwners".id WHERE accounts."id" = $1`
-	row := s.db.QueryRowContext(ctx, accountQuery, senderId)
+	row := s.conn(ctx).QueryRowContext(ctx, accountQuery, senderId)
 	if err := row.Err(); err != nil {
 		return web.UserAccountData{}, s.wrapQueryError(err)
 	}
 
 	var userAccountData web.UserAccountData
-	if err := row.Scan(&userAccountData.BalanceCents, &userAccountData.Status, &userAccountData.UserId); err != nil {
+	if err := row.Scan(&userAccountData.Id, &userAccountData.Number, &userAccountData.BalanceCents, &userAccountData.AvailableCents, &userAccountData.PendingIncomingCents,
+		&userAccountData.PendingOutgoingCents, &userAccountData.Status, &userAccountData.UserId, &userAccountData.OwnerIdentified, &userAccountData.OwnerName); err != nil {
 		return web.UserAccountData{}, s.wrapScanError(err)
 	}
+	userAccountData.BookedCents = userAccountData.BalanceCents + userAccountData.PendingOutgoingCents
 	return userAccountData, nil
 }
 
-func (s *Service) CreateTransaction(ctx context.Context, senderId, receiverId, amountCents int64, description string) error {
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return s.wrapQueryError(err)
+func (s *Service) GetAccountIdByNumber(ctx context.Context, number string) (int64, error) {
+	const query = `SELECT "id" FROM accounts WHERE "number" = $1`
+
+	row := s.db.QueryRowContext(ctx, query, number)
+	if err := row.Err(); err != nil {
+		return 0, s.wrapQueryError(err)
 	}
 
-	defer func() {
-		if tempErr := tx.Rollback(); tempErr != nil {
-			err = s.wrapQueryError(tempErr)
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, cerrors.NewErrorWithUserMessage(ercodes.AccountNotFound, err, "Счёт не найден")
 		}
-	}()
+		return 0, s.wrapScanError(err)
+	}
+	return id, nil
+}
 
-	errCh := make(chan error, 1)
+func (s *Service) CreateTransaction(ctx context.Context, senderId, receiverId, amountCents int64, description string) (int64, error) {
+	var transactionId int64
+	err := s.WithinTransaction(ctx, func(ctx context.Context) error {
+		tx := s.conn(ctx)
 
-	go func() {
-		const queryTransaction = `INSERT INTO transactions ("senderId", "receiverId", "amountCents", description) VALUES (@senderId, @receiverId, @amountCents, @description)`
-		_, err := tx.ExecContext(ctx, queryTransaction, pgx.NamedArgs{
+		const queryTransaction = `INSERT INTO transactions ("senderId", "receiverId", "amountCents", description) VALUES (@senderId, @receiverId, @amountCents, @description) RETURNING id`
+		err := tx.QueryRowContext(ctx, queryTransaction, pgx.NamedArgs{
 			"senderId":    senderId,
 			"receiverId":  receiverId,
 			"amountCents": amountCents,
 			"description": description,
-		})
-		errCh <- err
-	}()
+		}).Scan(&transactionId)
+		if err != nil {
+			return s.wrapQueryError(err)
+		}
 
-	go func() {
 		const querySenderUpdate = `UPDATE accounts SET "balanceCents" = "balanceCents" - @amountCents WHERE id = @senderId`
-		_, err := tx.ExecContext(ctx, querySenderUpdate, pgx.NamedArgs{
+		_, err = tx.ExecContext(ctx, querySenderUpdate, pgx.NamedArgs{
 			"amountCents": amountCents,
 			"senderId":    senderId,
 		})
-		errCh <- err
-	}()
-
-	for i := 0; i < 2; i++ {
-		if err := <-errCh; err != nil {
+		if err != nil {
 			return s.wrapQueryError(err)
 		}
-	}
 
-	if err = tx.Commit(); err != nil {
-		return s.wrapQueryError(err)
+		if err = s.createAccountEvent(ctx, tx, senderId, web.AccountEventTransactionCreated, transactionId, -amountCents); err != nil {
+			return err
+		}
+		return s.createAccountEvent(ctx, tx, receiverId, web.AccountEventTransactionCreated, transactionId, amountCents)
+	})
+	if err != nil {
+		return 0, err
 	}
-
-	return err
+	return transactionId, nil
 }
 
 func (s *Service) GetAtmDataByLogin(ctx context.Context, login string) (web.AtmData, error) {
-	const query = `SELECT atms.id, atms.password, atms."cashCents", accounts.id as "hasPersonalData"
+	return s.getAtmData(ctx, `atms.login = @login`, pgx.NamedArgs{
+		"login": login,
+	})
+}
+
+func (s *Service) GetAtmDataById(ctx context.Context, atmId int64) (web.AtmData, error) {
+	return s.getAtmData(ctx, `atms.id = @atmId`, pgx.NamedArgs{
+		"atmId": atmId,
+	})
+}
+
+func (s *Service) getAtmData(ctx context.Context, condition string, args pgx.NamedArgs) (web.AtmData, error) {
+	query := `SELECT atms.id, atms.login, atms.password, atms."status", atms."cashCents", atms."lowCashThresholdCents", 
+       			COALESCE(atms."lockedUntil" > current_timestamp, false), atms."createdAt", accounts.id as "hasPersonalData", accounts."number"
 				   FROM atms
 				   INNER JOIN "accountOwners" ON atms.id = "accountOwners"."atmId" 
 					INNER JOIN "accounts" ON "accountOwners".id = "accounts"."ownerId"
-				   WHERE atms.login = @login`
+				   WHERE ` + condition
 
-	row := s.db.QueryRowContext(ctx, query,
-		pgx.NamedArgs{
-			"login": login,
-		},
-	)
+	row := s.conn(ctx).QueryRowContext(ctx, query, args)
 	if err := row.Err(); err != nil {
 		return web.AtmData{}, s.wrapQueryError(err)
 	}
 
 	var atmData web.AtmData
-	if err := row.Scan(&atmData.Id, &atmData.PasswordHash, &atmData.CashCents, &atmData.AccountId); err != nil {
+	if err := row.Scan(&atmData.Id, &atmData.Login, &atmData.PasswordHash, &atmData.Status, &atmData.CashCents, &atmData.LowCashThresholdCents,
+		&atmData.IsLocked, &atmData.CreatedAt, &atmData.AccountId, &atmData.AccountNumber); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.AtmData{}, cerrors.NewErrorWithUserMessage(ercodes.AtmNotFound, err, "Банкомат не найден")
+		}
 		return web.AtmData{}, s.wrapScanError(err)
 	}
 	return atmData, nil
 }
 
-func (s *Service) UpdateAtmCash(ctx context.Context, amountCents, atmId int64) error {
-	const query = `UPDATE atms SET "cashCents" = "cashCents" + @amountCents WHERE id = @atmId`
+func (s *Service) RegisterAtmLoginFailure(ctx context.Context, atmId int64, maxAttempts int, lockDuration time.Duration) error {
+	const query = `UPDATE atms SET 
+                "failedLoginAttempts" = CASE WHEN "failedLoginAttempts" + 1 >= @maxAttempts THEN 0 ELSE "failedLoginAttempts" + 1 END,
+                "lockedUntil" = CASE WHEN "failedLoginAttempts" + 1 >= @maxAttempts THEN current_timestamp + @lockDuration ELSE "lockedUntil" END
+				WHERE id = @atmId`
 
-	_, err := s.db.ExecContext(ctx, query, pgx.NamedArgs{
-		"amountCents": amountCents,
-		"atmId":       atmId,
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
+		"atmId":        atmId,
+		"maxAttempts":  maxAttempts,
+		"lockDuration": lockDuration,
 	})
 	if err != nil {
 		return s.wrapQueryError(err)
@@ -247,10 +315,38 @@ func (s *Service) UpdateAtmCash(ctx context.Context, amountCents, atmId int64) e
 	return nil
 }
 
+func (s *Service) ResetAtmLoginFailures(ctx context.Context, atmId int64) error {
+	const query = `UPDATE atms SET "failedLoginAttempts" = 0, "lockedUntil" = NULL WHERE id = $1`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, atmId)
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) UpdateAtmCash(ctx context.Context, amountCents, atmId int64) (int64, error) {
+	const query = `UPDATE atms SET "cashCents" = "cashCents" + @amountCents WHERE id = @atmId RETURNING "cashCents"`
+
+	row := s.conn(ctx).QueryRowContext(ctx, query, pgx.NamedArgs{
+		"amountCents": amountCents,
+		"atmId":       atmId,
+	})
+	if err := row.Err(); err != nil {
+		return 0, s.wrapQueryError(err)
+	}
+
+	var cashCents int64
+	if err := row.Scan(&cashCents); err != nil {
+		return 0, s.wrapScanError(err)
+	}
+	return cashCents, nil
+}
+
 func (s *Service) UpdateAtmAccount(ctx context.Context, amountCents, accountId int64) error {
 	const query = `UPDATE accounts SET "balanceCents" = "balanceCents" + @amountCents WHERE id = @accountId`
 
-	_, err := s.db.ExecContext(ctx, query, pgx.NamedArgs{
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
 		"amountCents": amountCents,
 		"accountId":   accountId,
 	})
@@ -261,14 +357,14 @@ func (s *Service) UpdateAtmAccount(ctx context.Context, amountCents, accountId i
 }
 
 func (s *Service) LogCashOperation(ctx context.Context, atmId, amountCents, userAccountId int64) error {
-	var query string
-	if userAccountId == 0 {
-		query = `INSERT INTO "cashOperations" ("atmAccountId", "amountCents") VALUES (@atmId, @amountCents)`
-	} else {
-		query = `INSERT INTO "cashOperations" ("atmAccountId", "userAccountId", "amountCents") VALUES (@atmId, @userAccountId, @amountCents)`
-	}
-
-	_, err := s.db.ExecContext(ctx, query, pgx.NamedArgs{
+	const query = `INSERT INTO "cashOperations" ("atmAccountId", "userAccountId", "amountCents", "cashAfterCents", "balanceAfterCents") 
+					SELECT atms.id, NULLIF(@userAccountId::BIGINT, 0), @amountCents, atms."cashCents", accounts."balanceCents"
+					FROM atms
+					INNER JOIN "accountOwners" ON atms.id = "accountOwners"."atmId"
+					INNER JOIN accounts ON "accountOwners".id = accounts."ownerId"
+					WHERE atms.id = @atmId`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, pgx.NamedArgs{
 		"atmId":         atmId,
 		"amountCents":   amountCents,
 		"userAccountId": userAccountId,
@@ -320,7 +416,7 @@ func (s *Service) applyTransaction(ctx context.Context, transaction transaction_
 		}
 	}()
 
-	const queryTransaction = `UPDATE transactions SET status = 'CONFIRMED' WHERE id = $1`
+	const queryTransaction = `UPDATE transactions SET status = 'CONFIRMED', "confirmedAt" = current_timestamp WHERE id = $1`
 	_, err = tx.ExecContext(ctx, queryTransaction, transaction.Id)
 	if err != nil {
 		return s.wrapQueryError(err)
@@ -335,6 +431,13 @@ func (s *Service) applyTransaction(ctx context.Context, transaction transaction_
 	if err != nil {
 		return s.wrapQueryError(err)
 	}
+
+	if err = s.createAccountEvent(ctx, tx, transaction.SenderId, web.AccountEventTransactionConfirmed, transaction.Id, -transaction.AmountCents); err != nil {
+		return err
+	}
+	if err = s.createAccountEvent(ctx, tx, transaction.ReceiverId, web.AccountEventTransactionConfirmed, transaction.Id, transaction.AmountCents); err != nil {
+		return err
+	}
 	if err = tx.Commit(); err != nil {
 		return s.wrapQueryError(err)
 	}
@@ -1,10 +1,54 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
 	"x-bank-ms-bank/cerrors"
 	"x-bank-ms-bank/ercodes"
 )
 
+type (
+	txCtxKey struct{}
+
+	querier interface {
+		ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+		QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	}
+)
+
+func (s *Service) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txCtxKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+	return s.WithinNewTransaction(ctx, fn)
+}
+
+func (s *Service) WithinNewTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.wrapQueryError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = fn(context.WithValue(ctx, txCtxKey{}, tx)); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return s.wrapQueryError(err)
+	}
+	return nil
+}
+
+func (s *Service) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txCtxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return s.db
+}
+
 func (s *Service) Close() {
 	_ = s.db.Close()
 }
@@ -16,3 +60,8 @@ func (s *Service) wrapQueryError(err error) error {
 func (s *Service) wrapScanError(err error) error {
 	return cerrors.NewErrorWithUserMessage(ercodes.PostgresScan, err, "Ошибка работы с базой данных")
 }
+
+func (s *Service) isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type (
+	Service struct {
+		mu      sync.Mutex
+		buckets map[string]*bucket
+		sweptAt time.Time
+	}
+
+	bucket struct {
+		tokens        float64
+		updatedAt     time.Time
+		ratePerSecond float64
+		burst         float64
+	}
+)
+
+func NewService() *Service {
+	return &Service{
+		buckets: make(map[string]*bucket),
+		sweptAt: time.Now(),
+	}
+}
+
+func (s *Service) TakeRateLimitToken(_ context.Context, key string, ratePerSecond float64, burst int) (bool, time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.sweptAt) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+	b.ratePerSecond = ratePerSecond
+	b.burst = float64(burst)
+	b.tokens = b.refill(now)
+	b.updatedAt = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	return false, time.Duration(math.Ceil((1 - b.tokens) / ratePerSecond * float64(time.Second))), nil
+}
+
+func (s *Service) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if b.refill(now) >= b.burst {
+			delete(s.buckets, key)
+		}
+	}
+	s.sweptAt = now
+}
+
+func (b *bucket) refill(now time.Time) float64 {
+	return math.Min(b.burst, b.tokens+now.Sub(b.updatedAt).Seconds()*b.ratePerSecond)
+}
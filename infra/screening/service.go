@@ -0,0 +1,214 @@
+package screening
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/iban"
+)
+
+type (
+	Service struct {
+		path string
+
+		mu      sync.RWMutex
+		list    list
+		modTime time.Time
+	}
+
+	list struct {
+		userIds  map[int64]struct{}
+		accounts map[string]struct{}
+		names    []namePattern
+	}
+
+	namePattern struct {
+		pattern string
+		re      *regexp.Regexp
+	}
+
+	listEntry struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+)
+
+func NewService(path string) (*Service, error) {
+	s := &Service{path: path}
+	if path == "" {
+		return s, nil
+	}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Service) Watch(ctx context.Context, interval time.Duration) {
+	if s.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Reload(); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+func (s *Service) Reload() (bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	unchanged := info.ModTime().Equal(s.modTime)
+	s.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, err
+	}
+	entries, err := parseEntries(s.path, data)
+	if err != nil {
+		return false, fmt.Errorf("screening list %s: %w", s.path, err)
+	}
+	newList, err := buildList(entries)
+	if err != nil {
+		return false, fmt.Errorf("screening list %s: %w", s.path, err)
+	}
+
+	s.mu.Lock()
+	s.list = newList
+	s.modTime = info.ModTime()
+	s.mu.Unlock()
+
+	log.Printf("screening list %s loaded: %d users, %d accounts, %d names", s.path, len(newList.userIds), len(newList.accounts), len(newList.names))
+	return true, nil
+}
+
+func (s *Service) Screen(_ context.Context, subject web.ScreeningSubject) []web.ScreeningMatch {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []web.ScreeningMatch
+	if _, ok := s.list.userIds[subject.UserId]; ok && subject.UserId != 0 {
+		matches = append(matches, web.ScreeningMatch{ListType: web.ScreeningListUser, Entry: strconv.FormatInt(subject.UserId, 10)})
+	}
+	if number := iban.Normalize(subject.AccountNumber); number != "" {
+		if _, ok := s.list.accounts[number]; ok {
+			matches = append(matches, web.ScreeningMatch{ListType: web.ScreeningListAccount, Entry: number})
+		}
+	}
+	for _, name := range subject.Names {
+		name = normalizeName(name)
+		for _, pattern := range s.list.names {
+			if pattern.re.MatchString(name) {
+				matches = append(matches, web.ScreeningMatch{ListType: web.ScreeningListName, Entry: pattern.pattern})
+			}
+		}
+	}
+	return matches
+}
+
+func parseEntries(path string, data []byte) ([]listEntry, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var entries []listEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var entries []listEntry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) != 2 {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: expected type and value", line)
+		}
+		if strings.EqualFold(record[0], "type") {
+			continue
+		}
+		entries = append(entries, listEntry{Type: record[0], Value: record[1]})
+	}
+}
+
+func buildList(entries []listEntry) (list, error) {
+	newList := list{
+		userIds:  make(map[int64]struct{}),
+		accounts: make(map[string]struct{}),
+	}
+	for i, entry := range entries {
+		value := strings.TrimSpace(entry.Value)
+		if value == "" {
+			return list{}, fmt.Errorf("entry %d: empty value", i+1)
+		}
+
+		switch strings.ToUpper(strings.TrimSpace(entry.Type)) {
+		case web.ScreeningListUser:
+			userId, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return list{}, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			newList.userIds[userId] = struct{}{}
+		case web.ScreeningListAccount:
+			newList.accounts[iban.Normalize(value)] = struct{}{}
+		case web.ScreeningListName:
+			newList.names = append(newList.names, compileNamePattern(value))
+		default:
+			return list{}, fmt.Errorf("entry %d: unknown type %q", i+1, entry.Type)
+		}
+	}
+	return newList, nil
+}
+
+func compileNamePattern(pattern string) namePattern {
+	parts := strings.Split(normalizeName(pattern), "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return namePattern{
+		pattern: pattern,
+		re:      regexp.MustCompile("^" + strings.Join(parts, ".*") + "$"),
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(strings.ToLower(name), "ё", "е")), " ")
+}
@@ -0,0 +1,52 @@
+package pan
+
+import "strings"
+
+const (
+	minLength = 12
+	maxLength = 19
+)
+
+func New(prefix, body string) string {
+	number := prefix + body
+	return number + string(rune('0'+checkDigit(number)))
+}
+
+func Normalize(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number))
+}
+
+func IsValid(number string) bool {
+	if len(number) < minLength || len(number) > maxLength {
+		return false
+	}
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return checkDigit(number[:len(number)-1]) == int(number[len(number)-1]-'0')
+}
+
+func Mask(number string) string {
+	if len(number) < minLength {
+		return number
+	}
+	return number[:6] + strings.Repeat("*", len(number)-10) + number[len(number)-4:]
+}
+
+func checkDigit(number string) int {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if (len(number)-i)%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return (10 - sum%10) % 10
+}
@@ -1,8 +1,16 @@
 package http
 
 import (
+	"fmt"
+	"math"
 	"net/http"
 	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/iban"
+	"x-bank-ms-bank/pan"
 )
 
 var (
@@ -19,6 +27,21 @@ func (t *Transport) validate(w http.ResponseWriter, v validatable) bool {
 	return true
 }
 
+func validateAccountRef(ve *validationErrors, accountId int64, accountNumber, message string) {
+	if accountNumber == "" {
+		if accountId <= 0 {
+			ve.Add(message)
+		}
+		return
+	}
+
+	if accountId != 0 {
+		ve.Add("Нужно указать либо id счёта, либо его номер")
+	} else if !iban.IsValid(iban.Normalize(accountNumber)) {
+		ve.Add("Неверный номер счёта")
+	}
+}
+
 func (u *ATMAuthData) validate() (ve validationErrors) {
 	ve = make(validationErrors, 0, 2)
 
@@ -26,9 +49,9 @@ func (u *ATMAuthData) validate() (ve validationErrors) {
 		ve.Add("Неверный логин")
 	}
 
-	//if len(u.Password) < 6 || len(u.Password) > 16 {
-	//	ve.Add("Неверный пароль")
-	//}
+	if u.Password == "" {
+		ve.Add("Неверный пароль")
+	}
 
 	return
 }
@@ -44,28 +67,267 @@ func (u *ATMOperationData) validate() (ve validationErrors) {
 }
 
 func (u *ATMUserOperationData) validate() (ve validationErrors) {
-	ve = make(validationErrors, 0, 2)
+	ve = make(validationErrors, 0, 3)
 
 	if u.AmountCents <= 0 {
 		ve.Add("Неверная сумма для перевода")
 	}
-	if u.AccountId < 0 {
-		ve.Add("Неверный id для транзакции")
+	validateCard(&ve, u.CardNumber, u.Pin)
+
+	return
+}
+
+func (u *ATMSupplementData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 2)
+
+	validateBanknotes(&ve, u.AmountCents, u.Banknotes)
+
+	return
+}
+
+func (u *ATMUserSupplementData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 4)
+
+	validateBanknotes(&ve, u.AmountCents, u.Banknotes)
+	validateCard(&ve, u.CardNumber, u.Pin)
+
+	return
+}
+
+func (u *IssueCardData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 1)
+
+	if !isValidPin(u.Pin) {
+		ve.Add("PIN должен состоять из 4 цифр")
 	}
 
 	return
 }
 
-func (u *TransactionData) validate() (ve validationErrors) {
+func (u *CardLimitsData) validate() (ve validationErrors) {
 	ve = make(validationErrors, 0, 2)
 
+	if u.PerTransactionLimitCents < 0 {
+		ve.Add("Неверный лимит на одну операцию")
+	}
+	if u.DailyLimitCents < 0 {
+		ve.Add("Неверный дневной лимит")
+	}
+
+	return
+}
+
+func (u *CardPaymentData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 5)
+
+	validateAccountRef(&ve, u.MerchantAccountId, u.MerchantAccountNumber, "Неверный счёт продавца")
+	if !pan.IsValid(pan.Normalize(u.CardNumber)) {
+		ve.Add("Неверный номер карты")
+	}
+	if _, _, ok := parseCardExpiry(u.Expiry); !ok {
+		ve.Add("Срок действия карты должен быть в формате MM/YY")
+	}
+	if len(u.Cvv) != 3 || strings.Trim(u.Cvv, "0123456789") != "" {
+		ve.Add("Неверный CVV")
+	}
+	if u.AmountCents <= 0 {
+		ve.Add("Неверная сумма платежа")
+	}
+
+	return
+}
+
+func (u *CardHoldCaptureData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 1)
+
+	if u.AmountCents < 0 {
+		ve.Add("Неверная сумма списания")
+	}
+
+	return
+}
+
+func parseCardExpiry(value string) (int, int, bool) {
+	expiry, err := time.Parse("01/06", value)
+	if err != nil {
+		return 0, 0, false
+	}
+	return int(expiry.Month()), expiry.Year(), true
+}
+
+func validateCard(ve *validationErrors, cardNumber, pin string) {
+	if !pan.IsValid(pan.Normalize(cardNumber)) {
+		ve.Add("Неверный номер карты")
+	}
+	if !isValidPin(pin) {
+		ve.Add("Неверный PIN")
+	}
+}
+
+func isValidPin(pin string) bool {
+	if len(pin) != 4 {
+		return false
+	}
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func validateBanknotes(ve *validationErrors, amountCents int64, banknotes []ATMBanknotesData) {
+	if len(banknotes) == 0 {
+		ve.Add("Не указаны внесённые купюры")
+		return
+	}
+
+	var totalCents int64
+	for _, entry := range banknotes {
+		if entry.DenominationCents <= 0 || entry.Count <= 0 || entry.Count > (math.MaxInt64-totalCents)/entry.DenominationCents {
+			ve.Add("Неверный номинал или количество купюр")
+			return
+		}
+		totalCents += entry.DenominationCents * entry.Count
+	}
+
+	if amountCents != 0 && amountCents != totalCents {
+		ve.Add("Сумма не совпадает с внесёнными купюрами")
+	}
+}
+
+func (u *TransactionData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 3)
+
 	if u.AmountCents <= 0 {
 		ve.Add("Неверная сумма для перевода")
 	}
+	validateAccountRef(&ve, u.SenderId, u.SenderNumber, "Неверный id для транзакции")
 
-	if u.SenderId < 0 || u.ReceiverId < 0 || u.SenderId == u.ReceiverId {
+	if u.ReceiverAlias != "" {
+		if u.ReceiverId != 0 || u.ReceiverNumber != "" {
+			ve.Add("Нужно указать либо счёт получателя, либо псевдоним")
+		} else if _, _, ok := web.ParsePaymentAlias(u.ReceiverAlias); !ok {
+			ve.Add("Неверный псевдоним получателя")
+		}
+		return
+	}
+
+	validateAccountRef(&ve, u.ReceiverId, u.ReceiverNumber, "Неверный id для транзакции")
+	if u.SenderId != 0 && u.SenderId == u.ReceiverId || u.SenderNumber != "" && iban.Normalize(u.SenderNumber) == iban.Normalize(u.ReceiverNumber) {
 		ve.Add("Неверный id для транзакции")
 	}
 
 	return
 }
+
+func (u *PaymentAliasData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 2)
+
+	validateAccountRef(&ve, u.AccountId, u.AccountNumber, "Неверный id счёта")
+	if _, _, ok := web.ParsePaymentAlias(u.Alias); !ok {
+		ve.Add("Псевдоним должен быть номером телефона в формате +79991234567 или email")
+	}
+
+	return
+}
+
+func (u *PaymentAliasConfirmData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 1)
+
+	if u.Code == "" {
+		ve.Add("Не указан код подтверждения")
+	}
+
+	return
+}
+
+func (u *BeneficiaryData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 2)
+
+	validateAccountRef(&ve, u.AccountId, u.AccountNumber, "Неверный id счёта")
+	validateBeneficiaryNickname(&ve, u.Nickname)
+
+	return
+}
+
+func (u *BeneficiaryNicknameData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 1)
+
+	validateBeneficiaryNickname(&ve, u.Nickname)
+
+	return
+}
+
+func validateBeneficiaryNickname(ve *validationErrors, nickname string) {
+	if nicknameLength := utf8.RuneCountInString(strings.TrimSpace(nickname)); nicknameLength == 0 || nicknameLength > 128 {
+		ve.Add("Неверное название получателя")
+	}
+}
+
+func (u *ResolvePaymentAliasData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 1)
+
+	if _, _, ok := web.ParsePaymentAlias(u.Alias); !ok {
+		ve.Add("Неверный псевдоним")
+	}
+
+	return
+}
+
+func (u *PaymentRequestData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 4)
+
+	if u.AmountCents <= 0 {
+		ve.Add("Неверная сумма запроса")
+	}
+	validateAccountRef(&ve, u.AccountId, u.AccountNumber, "Неверный id счёта получателя")
+	validateAccountRef(&ve, u.PayerAccountId, u.PayerAccountNumber, "Неверный id счёта плательщика")
+	if u.ExpiresInMinutes < 0 || time.Duration(u.ExpiresInMinutes)*time.Minute > web.MaxPaymentRequestTTL {
+		ve.Add("Неверный срок действия запроса")
+	}
+
+	return
+}
+
+func (u *BatchTransactionData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 2)
+
+	validateAccountRef(&ve, u.SenderId, u.SenderNumber, "Неверный id счёта отправителя")
+	if len(u.Items) == 0 || len(u.Items) > web.MaxBatchTransferItems {
+		ve.Add(fmt.Sprintf("Пакет должен содержать от 1 до %d переводов", web.MaxBatchTransferItems))
+	}
+
+	for i, item := range u.Items {
+		var itemErrors validationErrors
+		if item.AmountCents <= 0 {
+			itemErrors.Add("неверная сумма для перевода")
+		}
+		switch {
+		case item.ReceiverAlias != "":
+			if item.ReceiverId != 0 || item.ReceiverNumber != "" {
+				itemErrors.Add("нужно указать либо счёт получателя, либо псевдоним")
+			} else if _, _, ok := web.ParsePaymentAlias(item.ReceiverAlias); !ok {
+				itemErrors.Add("неверный псевдоним получателя")
+			}
+		default:
+			validateAccountRef(&itemErrors, item.ReceiverId, item.ReceiverNumber, "неверный id счёта получателя")
+		}
+
+		for _, e := range itemErrors {
+			ve.Add(fmt.Sprintf("Перевод №%d: %s", i, e))
+		}
+	}
+
+	return
+}
+
+func (u *StepUpConfirmData) validate() (ve validationErrors) {
+	ve = make(validationErrors, 0, 1)
+
+	if u.Code == "" {
+		ve.Add("Не указан код подтверждения")
+	}
+
+	return
+}
@@ -1,10 +1,17 @@
 package http
 
+import "encoding/json"
+
 type (
 	UserAccountsResponseItem struct {
-		Id           int64  `json:"id"`
-		BalanceCents int64  `json:"balanceCents"`
-		Status       string `json:"status"`
+		Id                    int64  `json:"id"`
+		Number                string `json:"number"`
+		BalanceCents          int64  `json:"balanceCents"`
+		BookedBalanceCents    int64  `json:"bookedBalanceCents"`
+		AvailableBalanceCents int64  `json:"availableBalanceCents"`
+		PendingIncomingCents  int64  `json:"pendingIncomingCents"`
+		PendingOutgoingCents  int64  `json:"pendingOutgoingCents"`
+		Status                string `json:"status"`
 	}
 
 	UserAccountsResponse struct {
@@ -12,24 +19,57 @@ type (
 	}
 
 	AccountsHistoryResponseItem struct {
-		SenderId    int64  `json:"senderId"`
-		ReceiverId  int64  `json:"receiverId"`
-		Status      string `json:"status"`
-		CreatedAt   string `json:"createdAt"`
-		AmountCents int64  `json:"amountCents"`
-		Description string `json:"description"`
+		SenderId             int64  `json:"senderId"`
+		ReceiverId           int64  `json:"receiverId"`
+		Status               string `json:"status"`
+		CreatedAt            string `json:"createdAt"`
+		AmountCents          int64  `json:"amountCents"`
+		Description          string `json:"description"`
+		CounterpartyNickname string `json:"counterpartyNickname,omitempty"`
+	}
+
+	AccountEventResponse struct {
+		Id            int64  `json:"id"`
+		AccountId     int64  `json:"accountId"`
+		Type          string `json:"type"`
+		TransactionId int64  `json:"transactionId,omitempty"`
+		AmountCents   int64  `json:"amountCents"`
+		BalanceCents  int64  `json:"balanceCents"`
+		CreatedAt     string `json:"createdAt"`
 	}
 
 	AccountsHistoryResponse struct {
-		Items []AccountsHistoryResponseItem `json:"items"`
-		Total int64                         `json:"total"`
+		BookedBalanceCents    int64                         `json:"bookedBalanceCents"`
+		AvailableBalanceCents int64                         `json:"availableBalanceCents"`
+		PendingIncomingCents  int64                         `json:"pendingIncomingCents"`
+		PendingOutgoingCents  int64                         `json:"pendingOutgoingCents"`
+		Items                 []AccountsHistoryResponseItem `json:"items"`
+		Total                 int64                         `json:"total"`
 	}
 
 	TransactionData struct {
-		SenderId    int64  `json:"senderId"`
-		ReceiverId  int64  `json:"receiverId"`
-		AmountCents int64  `json:"amountCents"`
-		Description string `json:"description"`
+		SenderId          int64  `json:"senderId"`
+		SenderNumber      string `json:"senderNumber"`
+		ReceiverId        int64  `json:"receiverId"`
+		ReceiverNumber    string `json:"receiverNumber"`
+		ReceiverAlias     string `json:"receiverAlias"`
+		AmountCents       int64  `json:"amountCents"`
+		Description       string `json:"description"`
+		ConfirmationToken string `json:"confirmationToken"`
+	}
+
+	StepUpChallengeResponse struct {
+		ChallengeId int64  `json:"challengeId"`
+		ExpiresAt   string `json:"expiresAt"`
+	}
+
+	StepUpConfirmData struct {
+		Code string `json:"code"`
+	}
+
+	StepUpConfirmResponse struct {
+		ConfirmationToken string `json:"confirmationToken"`
+		ExpiresAt         string `json:"expiresAt"`
 	}
 
 	ATMOperationData struct {
@@ -37,12 +77,376 @@ type (
 	}
 
 	ATMUserOperationData struct {
+		AmountCents int64  `json:"amountCents"`
+		CardNumber  string `json:"cardNumber"`
+		Pin         string `json:"pin"`
+	}
+
+	ATMBanknotesData struct {
+		DenominationCents int64 `json:"denominationCents"`
+		Count             int64 `json:"count"`
+	}
+
+	ATMSupplementData struct {
+		AmountCents int64              `json:"amountCents"`
+		Banknotes   []ATMBanknotesData `json:"banknotes"`
+	}
+
+	ATMUserSupplementData struct {
+		AmountCents int64              `json:"amountCents"`
+		CardNumber  string             `json:"cardNumber"`
+		Pin         string             `json:"pin"`
+		Banknotes   []ATMBanknotesData `json:"banknotes"`
+	}
+
+	IssueCardData struct {
+		Pin string `json:"pin"`
+	}
+
+	IssueCardResponse struct {
+		Id        int64  `json:"id"`
+		AccountId int64  `json:"accountId"`
+		Number    string `json:"number"`
+		ExpiresAt string `json:"expiresAt"`
+		Cvv       string `json:"cvv"`
+		Status    string `json:"status"`
+	}
+
+	UserCardsResponseItem struct {
+		Id                       int64  `json:"id"`
+		AccountId                int64  `json:"accountId"`
+		Number                   string `json:"number"`
+		ExpiresAt                string `json:"expiresAt"`
+		Status                   string `json:"status"`
+		PerTransactionLimitCents int64  `json:"perTransactionLimitCents,omitempty"`
+		DailyLimitCents          int64  `json:"dailyLimitCents,omitempty"`
+		CreatedAt                string `json:"createdAt"`
+	}
+
+	CardLimitsData struct {
+		PerTransactionLimitCents int64 `json:"perTransactionLimitCents"`
+		DailyLimitCents          int64 `json:"dailyLimitCents"`
+	}
+
+	CardPaymentData struct {
+		MerchantAccountId     int64  `json:"merchantAccountId"`
+		MerchantAccountNumber string `json:"merchantAccountNumber"`
+		CardNumber            string `json:"cardNumber"`
+		Expiry                string `json:"expiry"`
+		Cvv                   string `json:"cvv"`
+		AmountCents           int64  `json:"amountCents"`
+		Description           string `json:"description"`
+	}
+
+	CardHoldResponse struct {
+		Id            int64  `json:"id"`
+		CardNumber    string `json:"cardNumber,omitempty"`
+		AmountCents   int64  `json:"amountCents"`
+		CapturedCents int64  `json:"capturedCents"`
+		TransactionId int64  `json:"transactionId,omitempty"`
+		Status        string `json:"status"`
+		ExpiresAt     string `json:"expiresAt"`
+	}
+
+	CardHoldCaptureData struct {
 		AmountCents int64 `json:"amountCents"`
-		AccountId   int64 `json:"accountId"`
+	}
+
+	CardPaymentResponse struct {
+		Id            int64  `json:"id"`
+		TransactionId int64  `json:"transactionId"`
+		CardNumber    string `json:"cardNumber"`
+		AmountCents   int64  `json:"amountCents"`
+		Status        string `json:"status"`
+	}
+
+	UserCardsResponse struct {
+		Items []UserCardsResponseItem `json:"items"`
+	}
+
+	ATMWithdrawalResponse struct {
+		AmountCents int64              `json:"amountCents"`
+		Banknotes   []ATMBanknotesData `json:"banknotes"`
+	}
+
+	PaymentAliasData struct {
+		AccountId     int64  `json:"accountId"`
+		AccountNumber string `json:"accountNumber"`
+		Alias         string `json:"alias"`
+	}
+
+	PaymentAliasVerificationResponse struct {
+		VerificationId int64  `json:"verificationId"`
+		ExpiresAt      string `json:"expiresAt"`
+	}
+
+	PaymentAliasConfirmData struct {
+		Code string `json:"code"`
+	}
+
+	PaymentAliasesResponseItem struct {
+		Id        int64  `json:"id"`
+		AccountId int64  `json:"accountId"`
+		Type      string `json:"type"`
+		Alias     string `json:"alias"`
+		OwnerName string `json:"ownerName"`
+		CreatedAt string `json:"createdAt"`
+	}
+
+	PaymentAliasesResponse struct {
+		Items []PaymentAliasesResponseItem `json:"items"`
+	}
+
+	BeneficiaryData struct {
+		AccountId     int64  `json:"accountId"`
+		AccountNumber string `json:"accountNumber"`
+		Nickname      string `json:"nickname"`
+	}
+
+	BeneficiaryNicknameData struct {
+		Nickname string `json:"nickname"`
+	}
+
+	BeneficiariesResponse struct {
+		Items []BeneficiaryResponse `json:"items"`
+	}
+
+	BeneficiaryResponse struct {
+		Id              int64  `json:"id"`
+		AccountId       int64  `json:"accountId"`
+		AccountNumber   string `json:"accountNumber"`
+		Nickname        string `json:"nickname"`
+		CreatedAt       string `json:"createdAt"`
+		CoolingOffUntil string `json:"coolingOffUntil"`
+	}
+
+	ResolvePaymentAliasData struct {
+		Alias string `json:"alias"`
+	}
+
+	ResolvePaymentAliasResponse struct {
+		Type      string `json:"type"`
+		OwnerName string `json:"ownerName"`
+	}
+
+	PaymentRequestData struct {
+		AccountId          int64  `json:"accountId"`
+		AccountNumber      string `json:"accountNumber"`
+		PayerAccountId     int64  `json:"payerAccountId"`
+		PayerAccountNumber string `json:"payerAccountNumber"`
+		AmountCents        int64  `json:"amountCents"`
+		Description        string `json:"description"`
+		ExpiresInMinutes   int64  `json:"expiresInMinutes"`
+	}
+
+	PaymentRequestCreatedResponse struct {
+		Id int64 `json:"id"`
+	}
+
+	PaymentRequestsResponseItem struct {
+		Id                 int64  `json:"id"`
+		RequesterAccountId int64  `json:"requesterAccountId"`
+		PayerAccountId     int64  `json:"payerAccountId"`
+		AmountCents        int64  `json:"amountCents"`
+		Description        string `json:"description"`
+		Status             string `json:"status"`
+		ExpiresAt          string `json:"expiresAt"`
+		CreatedAt          string `json:"createdAt"`
+		TransactionId      int64  `json:"transactionId,omitempty"`
+	}
+
+	PaymentRequestsResponse struct {
+		Items []PaymentRequestsResponseItem `json:"items"`
+		Total int64                         `json:"total"`
+	}
+
+	PaymentRequestAcceptData struct {
+		ConfirmationToken string `json:"confirmationToken"`
+	}
+
+	PaymentRequestAcceptedResponse struct {
+		TransactionId int64 `json:"transactionId"`
+	}
+
+	BatchTransactionData struct {
+		SenderId          int64                      `json:"senderId"`
+		SenderNumber      string                     `json:"senderNumber"`
+		Items             []BatchTransactionItemData `json:"items"`
+		ConfirmationToken string                     `json:"confirmationToken"`
+	}
+
+	BatchTransactionItemData struct {
+		ReceiverId     int64  `json:"receiverId"`
+		ReceiverNumber string `json:"receiverNumber"`
+		ReceiverAlias  string `json:"receiverAlias"`
+		AmountCents    int64  `json:"amountCents"`
+		Description    string `json:"description"`
+	}
+
+	BatchTransactionResponseItem struct {
+		Index         int    `json:"index"`
+		ReceiverId    int64  `json:"receiverId,omitempty"`
+		AmountCents   int64  `json:"amountCents"`
+		TransactionId int64  `json:"transactionId,omitempty"`
+		Status        string `json:"status"`
+		Error         string `json:"error,omitempty"`
+	}
+
+	BatchTransactionResponse struct {
+		BatchId    int64                          `json:"batchId,omitempty"`
+		Status     string                         `json:"status"`
+		TotalCents int64                          `json:"totalCents"`
+		Items      []BatchTransactionResponseItem `json:"items"`
+	}
+
+	TransactionBatchResponseItem struct {
+		TransactionId int64  `json:"transactionId"`
+		ReceiverId    int64  `json:"receiverId"`
+		AmountCents   int64  `json:"amountCents"`
+		Description   string `json:"description"`
+		Status        string `json:"status"`
+	}
+
+	TransactionBatchResponse struct {
+		Id         int64                          `json:"id"`
+		SenderId   int64                          `json:"senderId"`
+		TotalCents int64                          `json:"totalCents"`
+		CreatedAt  string                         `json:"createdAt"`
+		Items      []TransactionBatchResponseItem `json:"items"`
+	}
+
+	PaymentFileResponseItem struct {
+		Id            int64  `json:"id,omitempty"`
+		LineNo        int    `json:"lineNo"`
+		Receiver      string `json:"receiver"`
+		ReceiverId    int64  `json:"receiverId,omitempty"`
+		AmountCents   int64  `json:"amountCents"`
+		Description   string `json:"description"`
+		Status        string `json:"status"`
+		Error         string `json:"error,omitempty"`
+		TransactionId int64  `json:"transactionId,omitempty"`
+	}
+
+	PaymentFileConfirmData struct {
+		ConfirmationToken string `json:"confirmationToken"`
+	}
+
+	PaymentFileResponse struct {
+		Id         int64                     `json:"id,omitempty"`
+		SenderId   int64                     `json:"senderId"`
+		Format     string                    `json:"format"`
+		Hash       string                    `json:"hash"`
+		Status     string                    `json:"status"`
+		ItemsCount int                       `json:"itemsCount"`
+		TotalCents int64                     `json:"totalCents"`
+		CreatedAt  string                    `json:"createdAt,omitempty"`
+		UpdatedAt  string                    `json:"updatedAt,omitempty"`
+		Items      []PaymentFileResponseItem `json:"items"`
 	}
 
 	ATMAuthData struct {
-		Login    string
-		Password string
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+
+	AdminAtmsResponseItem struct {
+		Id                    int64              `json:"id"`
+		Login                 string             `json:"login"`
+		Status                string             `json:"status"`
+		IsLocked              bool               `json:"isLocked"`
+		AccountId             int64              `json:"accountId"`
+		AccountNumber         string             `json:"accountNumber"`
+		CashCents             int64              `json:"cashCents"`
+		LowCashThresholdCents int64              `json:"lowCashThresholdCents"`
+		Cassettes             []ATMBanknotesData `json:"cassettes"`
+		CreatedAt             string             `json:"createdAt"`
+	}
+
+	AdminAtmsResponse struct {
+		Items []AdminAtmsResponseItem `json:"items"`
+		Total int64                   `json:"total"`
+	}
+
+	AdminAtmCashOperation struct {
+		Id                int64  `json:"id"`
+		UserAccountId     int64  `json:"userAccountId,omitempty"`
+		AmountCents       int64  `json:"amountCents"`
+		CashAfterCents    *int64 `json:"cashAfterCents"`
+		BalanceAfterCents *int64 `json:"balanceAfterCents"`
+		DiscrepancyCents  int64  `json:"discrepancyCents"`
+		CreatedAt         string `json:"createdAt"`
+	}
+
+	AdminAtmCashReportResponse struct {
+		AtmId                   int64                   `json:"atmId"`
+		AccountId               int64                   `json:"accountId"`
+		From                    string                  `json:"from"`
+		To                      string                  `json:"to"`
+		Operations              []AdminAtmCashOperation `json:"operations"`
+		OperationsSumCents      int64                   `json:"operationsSumCents"`
+		OpeningCashCents        int64                   `json:"openingCashCents"`
+		ClosingCashCents        int64                   `json:"closingCashCents"`
+		CurrentCashCents        int64                   `json:"currentCashCents"`
+		CashDiscrepancyCents    int64                   `json:"cashDiscrepancyCents"`
+		OpeningBalanceCents     int64                   `json:"openingBalanceCents"`
+		ClosingBalanceCents     int64                   `json:"closingBalanceCents"`
+		TransfersInCents        int64                   `json:"transfersInCents"`
+		TransfersOutCents       int64                   `json:"transfersOutCents"`
+		BalanceDiscrepancyCents int64                   `json:"balanceDiscrepancyCents"`
+		Balanced                bool                    `json:"balanced"`
+	}
+
+	AdminAtmCredentialsResponse struct {
+		Id            int64  `json:"id"`
+		Login         string `json:"login"`
+		Password      string `json:"password"`
+		AccountId     int64  `json:"accountId,omitempty"`
+		AccountNumber string `json:"accountNumber,omitempty"`
+	}
+
+	AdminTransactionReviewsResponse struct {
+		Items []AdminTransactionReviewsResponseItem `json:"items"`
+	}
+
+	AdminTransactionReviewsResponseItem struct {
+		TransactionId int64    `json:"transactionId"`
+		SenderId      int64    `json:"senderId"`
+		ReceiverId    int64    `json:"receiverId"`
+		AmountCents   int64    `json:"amountCents"`
+		Description   string   `json:"description"`
+		Score         int      `json:"score"`
+		Reasons       []string `json:"reasons"`
+		Status        string   `json:"status"`
+		ReviewerId    int64    `json:"reviewerId,omitempty"`
+		CreatedAt     string   `json:"createdAt"`
+		ReviewedAt    string   `json:"reviewedAt,omitempty"`
+	}
+
+	AdminAuditLogResponse struct {
+		Items []AdminAuditLogResponseItem `json:"items"`
+		Total int64                       `json:"total"`
+	}
+
+	AdminAuditLogResponseItem struct {
+		Id        int64           `json:"id"`
+		ActorType string          `json:"actorType"`
+		ActorId   string          `json:"actorId"`
+		RequestId string          `json:"requestId"`
+		Ip        string          `json:"ip"`
+		UserAgent string          `json:"userAgent"`
+		Action    string          `json:"action"`
+		EntityId  int64           `json:"entityId,omitempty"`
+		Before    json.RawMessage `json:"before,omitempty"`
+		After     json.RawMessage `json:"after,omitempty"`
+		Outcome   string          `json:"outcome"`
+		Error     string          `json:"error,omitempty"`
+		CreatedAt string          `json:"createdAt"`
+		PrevHash  string          `json:"prevHash"`
+		Hash      string          `json:"hash"`
+	}
+
+	ATMLoginResponse struct {
+		Token     string `json:"token"`
+		ExpiresAt int64  `json:"expiresAt"`
 	}
 )
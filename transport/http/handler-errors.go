@@ -3,8 +3,10 @@ package http
 import (
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 	"x-bank-ms-bank/cerrors"
 )
 
@@ -79,6 +81,19 @@ func (h *errorHandler) setUnauthorizedError(w http.ResponseWriter, err error) {
 	}, http.StatusUnauthorized)
 }
 
+func (h *errorHandler) setForbiddenError(w http.ResponseWriter, err error) {
+	h.setTransportError(w, TransportError{
+		DevMessage: errorMessage(err), UserMessage: "Доступ запрещён",
+	}, http.StatusForbidden)
+}
+
+func (h *errorHandler) setTooManyRequestsError(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.FormatInt(int64(max(1, math.Ceil(retryAfter.Seconds()))), 10))
+	h.setTransportError(w, TransportError{
+		UserMessage: "Слишком много запросов, повторите позже",
+	}, http.StatusTooManyRequests)
+}
+
 func (h *errorHandler) setUnprocessableEntityError(w http.ResponseWriter, ve validationErrors) {
 	w.WriteHeader(http.StatusUnprocessableEntity)
 	_ = json.NewEncoder(w).Encode(&ve)
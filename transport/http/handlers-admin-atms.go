@@ -0,0 +1,214 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"x-bank-ms-bank/core/web"
+)
+
+const (
+	reportDateTimeLayout = "2006.01.02 15:04:05"
+	reportDateLayout     = "2006.01.02"
+	defaultReportPeriod  = 24 * time.Hour
+)
+
+func (t *Transport) handlerAdminAtms(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil || limit < minLimit || limit > maxLimit {
+		limit = defaultLimit
+	}
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil || offset < minOffset {
+		offset = defaultOffset
+	}
+
+	data, total, err := t.service.GetAtms(r.Context(), limit, offset)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	response := AdminAtmsResponse{
+		Items: make([]AdminAtmsResponseItem, 0, len(data)),
+		Total: total,
+	}
+	for _, entry := range data {
+		item := AdminAtmsResponseItem{
+			Id:                    entry.Id,
+			Login:                 entry.Login,
+			Status:                entry.Status,
+			IsLocked:              entry.IsLocked,
+			AccountId:             entry.AccountId,
+			AccountNumber:         entry.AccountNumber,
+			CashCents:             entry.CashCents,
+			LowCashThresholdCents: entry.LowCashThresholdCents,
+			Cassettes:             make([]ATMBanknotesData, 0, len(entry.Cassettes)),
+			CreatedAt:             entry.CreatedAt.Format("2006.01.02 15:04:05"),
+		}
+		for _, cassette := range entry.Cassettes {
+			item.Cassettes = append(item.Cassettes, ATMBanknotesData{
+				DenominationCents: cassette.DenominationCents,
+				Count:             cassette.Count,
+			})
+		}
+		response.Items = append(response.Items, item)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerAdminRegisterAtm(w http.ResponseWriter, r *http.Request) {
+	data, password, err := t.service.RegisterAtm(r.Context())
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(AdminAtmCredentialsResponse{
+		Id:            data.Id,
+		Login:         data.Login,
+		Password:      password,
+		AccountId:     data.AccountId,
+		AccountNumber: data.AccountNumber,
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerAdminRotateAtmPassword(w http.ResponseWriter, r *http.Request) {
+	atmId, err := strconv.ParseInt(r.PathValue("atmId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+
+	password, err := t.service.RotateAtmPassword(r.Context(), atmId)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(AdminAtmCredentialsResponse{
+		Id:       atmId,
+		Password: password,
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerAdminDisableAtm(w http.ResponseWriter, r *http.Request) {
+	t.setAtmStatus(w, r, web.AtmStatusDisabled)
+}
+
+func (t *Transport) handlerAdminEnableAtm(w http.ResponseWriter, r *http.Request) {
+	t.setAtmStatus(w, r, web.AtmStatusActive)
+}
+
+func (t *Transport) setAtmStatus(w http.ResponseWriter, r *http.Request, status string) {
+	atmId, err := strconv.ParseInt(r.PathValue("atmId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+
+	if err = t.service.SetAtmStatus(r.Context(), atmId, status); err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (t *Transport) handlerAdminAtmCashReport(w http.ResponseWriter, r *http.Request) {
+	atmId, err := strconv.ParseInt(r.PathValue("atmId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+
+	to := time.Now()
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = parseReportTime(value); err != nil {
+			t.errorHandler.setBadRequestError(w, err)
+			return
+		}
+	}
+	from := to.Add(-defaultReportPeriod)
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = parseReportTime(value); err != nil {
+			t.errorHandler.setBadRequestError(w, err)
+			return
+		}
+	}
+	if !from.Before(to) {
+		t.errorHandler.setBadRequestError(w, errors.New("from must be before to"))
+		return
+	}
+
+	data, err := t.service.GetAtmCashReport(r.Context(), atmId, from, to)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	response := AdminAtmCashReportResponse{
+		AtmId:                   data.AtmId,
+		AccountId:               data.AccountId,
+		From:                    data.From.Format(reportDateTimeLayout),
+		To:                      data.To.Format(reportDateTimeLayout),
+		Operations:              make([]AdminAtmCashOperation, 0, len(data.Operations)),
+		OperationsSumCents:      data.OperationsSumCents,
+		OpeningCashCents:        data.OpeningCashCents,
+		ClosingCashCents:        data.ClosingCashCents,
+		CurrentCashCents:        data.CurrentCashCents,
+		CashDiscrepancyCents:    data.CashDiscrepancyCents,
+		OpeningBalanceCents:     data.OpeningBalanceCents,
+		ClosingBalanceCents:     data.ClosingBalanceCents,
+		TransfersInCents:        data.TransfersInCents,
+		TransfersOutCents:       data.TransfersOutCents,
+		BalanceDiscrepancyCents: data.BalanceDiscrepancyCents,
+		Balanced:                data.Balanced,
+	}
+	for _, entry := range data.Operations {
+		operation := AdminAtmCashOperation{
+			Id:               entry.Id,
+			UserAccountId:    entry.UserAccountId,
+			AmountCents:      entry.AmountCents,
+			DiscrepancyCents: entry.DiscrepancyCents,
+			CreatedAt:        entry.CreatedAt.Format(reportDateTimeLayout),
+		}
+		if entry.HasSnapshot {
+			operation.CashAfterCents = &entry.CashAfterCents
+			operation.BalanceAfterCents = &entry.BalanceAfterCents
+		}
+		response.Operations = append(response.Operations, operation)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func parseReportTime(value string) (time.Time, error) {
+	if parsed, err := time.ParseInLocation(reportDateTimeLayout, value, time.Local); err == nil {
+		return parsed, nil
+	}
+	return time.ParseInLocation(reportDateLayout, value, time.Local)
+}
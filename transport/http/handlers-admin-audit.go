@@ -0,0 +1,81 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"x-bank-ms-bank/core/web"
+)
+
+func (t *Transport) handlerAdminAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := strconv.ParseInt(query.Get("limit"), 10, 64)
+	if err != nil || limit < minLimit || limit > maxLimit {
+		limit = defaultLimit
+	}
+	offset, err := strconv.ParseInt(query.Get("offset"), 10, 64)
+	if err != nil || offset < minOffset {
+		offset = defaultOffset
+	}
+
+	filter := web.AuditFilterData{
+		ActorType: query.Get("actorType"),
+		ActorId:   query.Get("actorId"),
+		Action:    query.Get("action"),
+	}
+	if value := query.Get("entityId"); value != "" {
+		if filter.EntityId, err = strconv.ParseInt(value, 10, 64); err != nil {
+			t.errorHandler.setBadRequestError(w, err)
+			return
+		}
+	}
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = parseReportTime(value); err != nil {
+			t.errorHandler.setBadRequestError(w, err)
+			return
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = parseReportTime(value); err != nil {
+			t.errorHandler.setBadRequestError(w, err)
+			return
+		}
+	}
+
+	data, total, err := t.service.GetAuditEntries(r.Context(), filter, limit, offset)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	response := AdminAuditLogResponse{
+		Items: make([]AdminAuditLogResponseItem, 0, len(data)),
+		Total: total,
+	}
+	for _, entry := range data {
+		response.Items = append(response.Items, AdminAuditLogResponseItem{
+			Id:        entry.Id,
+			ActorType: entry.ActorType,
+			ActorId:   entry.ActorId,
+			RequestId: entry.RequestId,
+			Ip:        entry.Ip,
+			UserAgent: entry.UserAgent,
+			Action:    entry.Action,
+			EntityId:  entry.EntityId,
+			Before:    entry.Before,
+			After:     entry.After,
+			Outcome:   entry.Outcome,
+			Error:     entry.Error,
+			CreatedAt: entry.CreatedAt.Local().Format(reportDateTimeLayout),
+			PrevHash:  entry.PrevHash,
+			Hash:      entry.Hash,
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
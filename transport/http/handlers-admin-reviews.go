@@ -0,0 +1,91 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/core/web"
+)
+
+func (t *Transport) handlerAdminTransactionReviews(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = web.TransactionReviewStatusPending
+	case web.TransactionReviewStatusPending, web.TransactionReviewStatusApproved, web.TransactionReviewStatusRejected:
+	default:
+		t.errorHandler.setBadRequestError(w, errors.New("unknown review status"))
+		return
+	}
+
+	data, err := t.service.GetTransactionReviews(r.Context(), status)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	response := AdminTransactionReviewsResponse{Items: make([]AdminTransactionReviewsResponseItem, 0, len(data))}
+	for _, entry := range data {
+		item := AdminTransactionReviewsResponseItem{
+			TransactionId: entry.TransactionId,
+			SenderId:      entry.SenderId,
+			ReceiverId:    entry.ReceiverId,
+			AmountCents:   entry.AmountCents,
+			Description:   entry.Description,
+			Score:         entry.Score,
+			Reasons:       entry.Reasons,
+			Status:        entry.Status,
+			ReviewerId:    entry.ReviewerId,
+			CreatedAt:     entry.CreatedAt.Format("2006.01.02 15:04:05"),
+		}
+		if item.Reasons == nil {
+			item.Reasons = []string{}
+		}
+		if !entry.ReviewedAt.IsZero() {
+			item.ReviewedAt = entry.ReviewedAt.Format("2006.01.02 15:04:05")
+		}
+		response.Items = append(response.Items, item)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerAdminApproveTransactionReview(w http.ResponseWriter, r *http.Request) {
+	t.resolveTransactionReview(w, r, true)
+}
+
+func (t *Transport) handlerAdminRejectTransactionReview(w http.ResponseWriter, r *http.Request) {
+	t.resolveTransactionReview(w, r, false)
+}
+
+func (t *Transport) resolveTransactionReview(w http.ResponseWriter, r *http.Request, approve bool) {
+	transactionId, err := strconv.ParseInt(r.PathValue("transactionId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	if approve {
+		err = t.service.ApproveTransactionReview(r.Context(), transactionId, claims.Sub)
+	} else {
+		err = t.service.RejectTransactionReview(r.Context(), transactionId, claims.Sub)
+	}
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
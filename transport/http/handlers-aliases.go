@@ -0,0 +1,155 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"x-bank-ms-bank/auth"
+)
+
+func (t *Transport) handlerUserPaymentAliases(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	data, err := t.service.GetPaymentAliases(r.Context(), claims.Sub)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	var response PaymentAliasesResponse
+	for _, entry := range data {
+		response.Items = append(response.Items, PaymentAliasesResponseItem{
+			Id:        entry.Id,
+			AccountId: entry.AccountId,
+			Type:      entry.Type,
+			Alias:     entry.Value,
+			OwnerName: entry.OwnerName,
+			CreatedAt: entry.CreatedAt.Format("2006.01.02 15:04:05"),
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerBindPaymentAlias(w http.ResponseWriter, r *http.Request) {
+	var aliasData PaymentAliasData
+	if err := json.NewDecoder(r.Body).Decode(&aliasData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &aliasData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	accountId, err := t.resolveAccountId(r.Context(), aliasData.AccountId, aliasData.AccountNumber)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	verification, err := t.service.BindPaymentAlias(r.Context(), accountId, claims.Sub, aliasData.Alias)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode(PaymentAliasVerificationResponse{
+		VerificationId: verification.Id,
+		ExpiresAt:      verification.ExpiresAt.Format("2006.01.02 15:04:05"),
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerConfirmPaymentAlias(w http.ResponseWriter, r *http.Request) {
+	verificationId, err := strconv.ParseInt(r.PathValue("verificationId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	var confirmData PaymentAliasConfirmData
+	if err = json.NewDecoder(r.Body).Decode(&confirmData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &confirmData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	if err = t.service.ConfirmPaymentAlias(r.Context(), verificationId, claims.Sub, confirmData.Code); err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (t *Transport) handlerResolvePaymentAlias(w http.ResponseWriter, r *http.Request) {
+	var resolveData ResolvePaymentAliasData
+	if err := json.NewDecoder(r.Body).Decode(&resolveData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &resolveData) {
+		return
+	}
+
+	data, err := t.service.ResolvePaymentAlias(r.Context(), resolveData.Alias)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(ResolvePaymentAliasResponse{
+		Type:      data.Type,
+		OwnerName: data.OwnerName,
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerDeletePaymentAlias(w http.ResponseWriter, r *http.Request) {
+	aliasId, err := strconv.ParseInt(r.PathValue("aliasId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	if err = t.service.DeletePaymentAlias(r.Context(), aliasId, claims.Sub); err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
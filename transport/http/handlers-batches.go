@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/core/web"
+)
+
+func (t *Transport) handlerBatchTransaction(w http.ResponseWriter, r *http.Request) {
+	var batchData BatchTransactionData
+	if err := json.NewDecoder(r.Body).Decode(&batchData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &batchData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	senderId, err := t.resolveAccountId(r.Context(), batchData.SenderId, batchData.SenderNumber)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	data, err := t.service.MakeBatchTransaction(r.Context(), senderId, claims.Sub, batchTransferItems(batchData), batchData.ConfirmationToken)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	response := BatchTransactionResponse{
+		BatchId:    data.BatchId,
+		Status:     "COMPLETED",
+		TotalCents: data.TotalCents,
+	}
+	statusCode := http.StatusCreated
+	if data.BatchId == 0 {
+		response.Status = "REJECTED"
+		statusCode = http.StatusUnprocessableEntity
+	}
+	for _, entry := range data.Items {
+		item := BatchTransactionResponseItem{
+			Index:         entry.Index,
+			ReceiverId:    entry.ReceiverId,
+			AmountCents:   entry.AmountCents,
+			TransactionId: entry.TransactionId,
+			Status:        "CREATED",
+			Error:         entry.Error,
+		}
+		if data.BatchId == 0 {
+			item.Status = "REJECTED"
+		}
+		response.Items = append(response.Items, item)
+	}
+
+	w.WriteHeader(statusCode)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerCreateBatchStepUpChallenge(w http.ResponseWriter, r *http.Request) {
+	var batchData BatchTransactionData
+	if err := json.NewDecoder(r.Body).Decode(&batchData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &batchData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	senderId, err := t.resolveAccountId(r.Context(), batchData.SenderId, batchData.SenderNumber)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	challenge, err := t.service.CreateBatchStepUpChallenge(r.Context(), senderId, claims.Sub, batchTransferItems(batchData))
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(StepUpChallengeResponse{
+		ChallengeId: challenge.Id,
+		ExpiresAt:   challenge.ExpiresAt.Format("2006.01.02 15:04:05"),
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerTransactionBatch(w http.ResponseWriter, r *http.Request) {
+	batchId, err := strconv.ParseInt(r.PathValue("batchId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	data, err := t.service.GetTransactionBatch(r.Context(), batchId, claims.Sub)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	response := TransactionBatchResponse{
+		Id:         data.Id,
+		SenderId:   data.SenderId,
+		TotalCents: data.TotalCents,
+		CreatedAt:  data.CreatedAt.Format("2006.01.02 15:04:05"),
+	}
+	for _, entry := range data.Items {
+		response.Items = append(response.Items, TransactionBatchResponseItem{
+			TransactionId: entry.TransactionId,
+			ReceiverId:    entry.ReceiverId,
+			AmountCents:   entry.AmountCents,
+			Description:   entry.Description,
+			Status:        entry.Status,
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func batchTransferItems(batchData BatchTransactionData) []web.BatchTransferItem {
+	items := make([]web.BatchTransferItem, 0, len(batchData.Items))
+	for _, item := range batchData.Items {
+		items = append(items, web.BatchTransferItem{
+			ReceiverId:     item.ReceiverId,
+			ReceiverNumber: item.ReceiverNumber,
+			ReceiverAlias:  item.ReceiverAlias,
+			AmountCents:    item.AmountCents,
+			Description:    item.Description,
+		})
+	}
+	return items
+}
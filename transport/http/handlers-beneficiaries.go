@@ -0,0 +1,131 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/core/web"
+)
+
+func (t *Transport) handlerUserBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	data, err := t.service.GetBeneficiaries(r.Context(), claims.Sub)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	var response BeneficiariesResponse
+	for _, entry := range data {
+		response.Items = append(response.Items, toBeneficiaryResponse(entry))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerAddBeneficiary(w http.ResponseWriter, r *http.Request) {
+	var beneficiaryData BeneficiaryData
+	if err := json.NewDecoder(r.Body).Decode(&beneficiaryData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &beneficiaryData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	accountId, err := t.resolveAccountId(r.Context(), beneficiaryData.AccountId, beneficiaryData.AccountNumber)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	data, err := t.service.AddBeneficiary(r.Context(), claims.Sub, accountId, strings.TrimSpace(beneficiaryData.Nickname))
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(toBeneficiaryResponse(data))
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerRenameBeneficiary(w http.ResponseWriter, r *http.Request) {
+	beneficiaryId, err := strconv.ParseInt(r.PathValue("beneficiaryId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	var nicknameData BeneficiaryNicknameData
+	if err = json.NewDecoder(r.Body).Decode(&nicknameData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &nicknameData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	if err = t.service.RenameBeneficiary(r.Context(), beneficiaryId, claims.Sub, strings.TrimSpace(nicknameData.Nickname)); err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (t *Transport) handlerDeleteBeneficiary(w http.ResponseWriter, r *http.Request) {
+	beneficiaryId, err := strconv.ParseInt(r.PathValue("beneficiaryId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	if err = t.service.DeleteBeneficiary(r.Context(), beneficiaryId, claims.Sub); err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func toBeneficiaryResponse(data web.BeneficiaryData) BeneficiaryResponse {
+	return BeneficiaryResponse{
+		Id:              data.Id,
+		AccountId:       data.AccountId,
+		AccountNumber:   data.AccountNumber,
+		Nickname:        data.Nickname,
+		CreatedAt:       data.CreatedAt.Format("2006.01.02 15:04:05"),
+		CoolingOffUntil: data.CoolingOffUntil.Format("2006.01.02 15:04:05"),
+	}
+}
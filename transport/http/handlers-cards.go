@@ -0,0 +1,300 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/pan"
+)
+
+const cardExpiryLayout = "01/06"
+
+func (t *Transport) handlerUserCards(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	data, err := t.service.GetCards(r.Context(), claims.Sub)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	response := UserCardsResponse{Items: make([]UserCardsResponseItem, 0, len(data))}
+	for _, entry := range data {
+		response.Items = append(response.Items, UserCardsResponseItem{
+			Id:                       entry.Id,
+			AccountId:                entry.AccountId,
+			Number:                   pan.Mask(entry.Number),
+			ExpiresAt:                entry.ExpiresAt.Format(cardExpiryLayout),
+			Status:                   entry.Status,
+			PerTransactionLimitCents: entry.PerTransactionLimitCents,
+			DailyLimitCents:          entry.DailyLimitCents,
+			CreatedAt:                entry.CreatedAt.Format("2006.01.02 15:04:05"),
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerIssueCard(w http.ResponseWriter, r *http.Request) {
+	accountId, ok := t.accountIdFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var cardData IssueCardData
+	if err := json.NewDecoder(r.Body).Decode(&cardData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &cardData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	data, cvv, err := t.service.IssueCard(r.Context(), accountId, claims.Sub, cardData.Pin)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(IssueCardResponse{
+		Id:        data.Id,
+		AccountId: data.AccountId,
+		Number:    data.Number,
+		ExpiresAt: data.ExpiresAt.Format(cardExpiryLayout),
+		Cvv:       cvv,
+		Status:    data.Status,
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerBlockCard(w http.ResponseWriter, r *http.Request) {
+	t.setCardBlocked(w, r, true)
+}
+
+func (t *Transport) handlerUnblockCard(w http.ResponseWriter, r *http.Request) {
+	t.setCardBlocked(w, r, false)
+}
+
+func (t *Transport) setCardBlocked(w http.ResponseWriter, r *http.Request, blocked bool) {
+	cardId, err := strconv.ParseInt(r.PathValue("cardId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	if blocked {
+		err = t.service.BlockCard(r.Context(), cardId, claims.Sub)
+	} else {
+		err = t.service.UnblockCard(r.Context(), cardId, claims.Sub)
+	}
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (t *Transport) handlerSetCardLimits(w http.ResponseWriter, r *http.Request) {
+	cardId, err := strconv.ParseInt(r.PathValue("cardId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+
+	var limitsData CardLimitsData
+	if err = json.NewDecoder(r.Body).Decode(&limitsData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &limitsData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	err = t.service.SetCardLimits(r.Context(), cardId, claims.Sub, limitsData.PerTransactionLimitCents, limitsData.DailyLimitCents)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (t *Transport) handlerCardPayment(w http.ResponseWriter, r *http.Request) {
+	claims, payment, ok := t.decodeCardPayment(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := t.service.AuthorizeCardPayment(r.Context(), claims.Sub, payment)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(CardPaymentResponse{
+		Id:            data.Id,
+		TransactionId: data.TransactionId,
+		CardNumber:    pan.Mask(pan.Normalize(payment.CardNumber)),
+		AmountCents:   data.AmountCents,
+		Status:        "APPROVED",
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerCreateCardHold(w http.ResponseWriter, r *http.Request) {
+	claims, payment, ok := t.decodeCardPayment(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := t.service.CreateCardHold(r.Context(), claims.Sub, payment)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(CardHoldResponse{
+		Id:          data.Id,
+		CardNumber:  pan.Mask(pan.Normalize(payment.CardNumber)),
+		AmountCents: data.AmountCents,
+		Status:      data.Status,
+		ExpiresAt:   data.ExpiresAt.Format("2006.01.02 15:04:05"),
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerCaptureCardHold(w http.ResponseWriter, r *http.Request) {
+	holdId, err := strconv.ParseInt(r.PathValue("holdId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+
+	var captureData CardHoldCaptureData
+	if err = json.NewDecoder(r.Body).Decode(&captureData); err != nil && !errors.Is(err, io.EOF) {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &captureData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	data, err := t.service.CaptureCardHold(r.Context(), holdId, claims.Sub, captureData.AmountCents)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(CardHoldResponse{
+		Id:            data.Id,
+		AmountCents:   data.AmountCents,
+		CapturedCents: data.CapturedCents,
+		TransactionId: data.TransactionId,
+		Status:        data.Status,
+		ExpiresAt:     data.ExpiresAt.Format("2006.01.02 15:04:05"),
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerReleaseCardHold(w http.ResponseWriter, r *http.Request) {
+	holdId, err := strconv.ParseInt(r.PathValue("holdId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	if err = t.service.ReleaseCardHold(r.Context(), holdId, claims.Sub); err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (t *Transport) decodeCardPayment(w http.ResponseWriter, r *http.Request) (*auth.Claims, web.CardPaymentData, bool) {
+	var paymentData CardPaymentData
+	if err := json.NewDecoder(r.Body).Decode(&paymentData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return nil, web.CardPaymentData{}, false
+	}
+	if !t.validate(w, &paymentData) {
+		return nil, web.CardPaymentData{}, false
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return nil, web.CardPaymentData{}, false
+	}
+
+	merchantAccountId, err := t.resolveAccountId(r.Context(), paymentData.MerchantAccountId, paymentData.MerchantAccountNumber)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return nil, web.CardPaymentData{}, false
+	}
+	expiryMonth, expiryYear, _ := parseCardExpiry(paymentData.Expiry)
+
+	return claims, web.CardPaymentData{
+		MerchantAccountId: merchantAccountId,
+		CardNumber:        paymentData.CardNumber,
+		ExpiryMonth:       expiryMonth,
+		ExpiryYear:        expiryYear,
+		Cvv:               paymentData.Cvv,
+		AmountCents:       paymentData.AmountCents,
+		Description:       paymentData.Description,
+	}, true
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"x-bank-ms-bank/auth"
+)
+
+const eventsKeepAliveInterval = 15 * time.Second
+
+func (t *Transport) handlerUserEvents(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	var lastEventId int64
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		var err error
+		if lastEventId, err = strconv.ParseInt(value, 10, 64); err != nil {
+			t.errorHandler.setBadRequestError(w, err)
+			return
+		}
+	}
+
+	events, err := t.service.SubscribeAccountEvents(r.Context(), claims.Sub, lastEventId)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err = rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(AccountEventResponse{
+				Id:            event.Id,
+				AccountId:     event.AccountId,
+				Type:          event.Type,
+				TransactionId: event.TransactionId,
+				AmountCents:   event.AmountCents,
+				BalanceCents:  event.BalanceCents,
+				CreatedAt:     event.CreatedAt.Format("2006.01.02 15:04:05"),
+			})
+			if err != nil {
+				return
+			}
+			if _, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err = fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-t.shutdownCh:
+			return
+		}
+		if err = rc.Flush(); err != nil {
+			return
+		}
+	}
+}
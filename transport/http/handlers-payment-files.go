@@ -0,0 +1,172 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/core/web"
+)
+
+func (t *Transport) handlerImportPaymentFile(w http.ResponseWriter, r *http.Request) {
+	senderId, ok := t.accountIdFromPath(w, r)
+	if !ok {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = web.PaymentFileFormatCSV
+	}
+	if format != web.PaymentFileFormatCSV && format != web.PaymentFileFormatPain001 {
+		t.errorHandler.setUnprocessableEntityError(w, validationErrors{"Формат файла должен быть csv или pain.001"})
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, web.MaxPaymentFileSize))
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+
+	fileData, err := t.service.ImportPaymentFile(r.Context(), senderId, claims.Sub, format, data)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	statusCode := http.StatusAccepted
+	switch fileData.Status {
+	case web.PaymentFileStatusRejected:
+		statusCode = http.StatusUnprocessableEntity
+	case web.PaymentFileStatusProcessing:
+		t.service.ProcessPaymentFileAsync(fileData.Id)
+	}
+
+	w.WriteHeader(statusCode)
+	err = json.NewEncoder(w).Encode(newPaymentFileResponse(fileData))
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerCreatePaymentFileStepUpChallenge(w http.ResponseWriter, r *http.Request) {
+	fileId, err := strconv.ParseInt(r.PathValue("fileId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	challenge, err := t.service.CreatePaymentFileStepUpChallenge(r.Context(), fileId, claims.Sub)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(StepUpChallengeResponse{
+		ChallengeId: challenge.Id,
+		ExpiresAt:   challenge.ExpiresAt.Format("2006.01.02 15:04:05"),
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerConfirmPaymentFile(w http.ResponseWriter, r *http.Request) {
+	fileId, err := strconv.ParseInt(r.PathValue("fileId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	var confirmData PaymentFileConfirmData
+	if err = json.NewDecoder(r.Body).Decode(&confirmData); err != nil && !errors.Is(err, io.EOF) {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+
+	if err = t.service.ConfirmPaymentFile(r.Context(), fileId, claims.Sub, confirmData.ConfirmationToken); err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+	t.service.ProcessPaymentFileAsync(fileId)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (t *Transport) handlerPaymentFile(w http.ResponseWriter, r *http.Request) {
+	fileId, err := strconv.ParseInt(r.PathValue("fileId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	fileData, err := t.service.GetPaymentFile(r.Context(), fileId, claims.Sub)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	response := newPaymentFileResponse(fileData)
+	response.CreatedAt = fileData.CreatedAt.Format("2006.01.02 15:04:05")
+	response.UpdatedAt = fileData.UpdatedAt.Format("2006.01.02 15:04:05")
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func newPaymentFileResponse(fileData web.PaymentFileData) PaymentFileResponse {
+	response := PaymentFileResponse{
+		Id:         fileData.Id,
+		SenderId:   fileData.SenderId,
+		Format:     fileData.Format,
+		Hash:       fileData.Hash,
+		Status:     fileData.Status,
+		ItemsCount: fileData.ItemsCount,
+		TotalCents: fileData.TotalCents,
+		Items:      make([]PaymentFileResponseItem, 0, len(fileData.Items)),
+	}
+	for _, entry := range fileData.Items {
+		response.Items = append(response.Items, PaymentFileResponseItem{
+			Id:            entry.Id,
+			LineNo:        entry.LineNo,
+			Receiver:      entry.Receiver,
+			ReceiverId:    entry.ReceiverId,
+			AmountCents:   entry.AmountCents,
+			Description:   entry.Description,
+			Status:        entry.Status,
+			Error:         entry.Error,
+			TransactionId: entry.TransactionId,
+		})
+	}
+	return response
+}
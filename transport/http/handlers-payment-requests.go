@@ -0,0 +1,160 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"x-bank-ms-bank/auth"
+)
+
+func (t *Transport) handlerUserPaymentRequests(w http.ResponseWriter, r *http.Request) {
+	var incoming bool
+	switch r.URL.Query().Get("direction") {
+	case "", "incoming":
+		incoming = true
+	case "outgoing":
+		incoming = false
+	default:
+		t.errorHandler.setBadRequestError(w, errors.New("неверное значение direction"))
+		return
+	}
+	limit, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil || limit < minLimit || limit > maxLimit {
+		limit = defaultLimit
+	}
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil || offset < minOffset {
+		offset = defaultOffset
+	}
+
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	data, total, err := t.service.GetPaymentRequests(r.Context(), claims.Sub, incoming, limit, offset)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	var response PaymentRequestsResponse
+	for _, entry := range data {
+		response.Items = append(response.Items, PaymentRequestsResponseItem{
+			Id:                 entry.Id,
+			RequesterAccountId: entry.RequesterAccountId,
+			PayerAccountId:     entry.PayerAccountId,
+			AmountCents:        entry.AmountCents,
+			Description:        entry.Description,
+			Status:             entry.Status,
+			ExpiresAt:          entry.ExpiresAt.Format("2006.01.02 15:04:05"),
+			CreatedAt:          entry.CreatedAt.Format("2006.01.02 15:04:05"),
+			TransactionId:      entry.TransactionId,
+		})
+	}
+	response.Total = total
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerCreatePaymentRequest(w http.ResponseWriter, r *http.Request) {
+	var paymentRequestData PaymentRequestData
+	if err := json.NewDecoder(r.Body).Decode(&paymentRequestData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &paymentRequestData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	accountId, err := t.resolveAccountId(r.Context(), paymentRequestData.AccountId, paymentRequestData.AccountNumber)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+	payerAccountId, err := t.resolveAccountId(r.Context(), paymentRequestData.PayerAccountId, paymentRequestData.PayerAccountNumber)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	requestId, err := t.service.CreatePaymentRequest(r.Context(), accountId, payerAccountId, paymentRequestData.AmountCents, claims.Sub,
+		paymentRequestData.Description, time.Duration(paymentRequestData.ExpiresInMinutes)*time.Minute)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(PaymentRequestCreatedResponse{Id: requestId})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerAcceptPaymentRequest(w http.ResponseWriter, r *http.Request) {
+	requestId, err := strconv.ParseInt(r.PathValue("requestId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	var acceptData PaymentRequestAcceptData
+	if err = json.NewDecoder(r.Body).Decode(&acceptData); err != nil && !errors.Is(err, io.EOF) {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+
+	transactionId, err := t.service.AcceptPaymentRequest(r.Context(), requestId, claims.Sub, acceptData.ConfirmationToken)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(PaymentRequestAcceptedResponse{TransactionId: transactionId})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerDeclinePaymentRequest(w http.ResponseWriter, r *http.Request) {
+	requestId, err := strconv.ParseInt(r.PathValue("requestId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	if err = t.service.DeclinePaymentRequest(r.Context(), requestId, claims.Sub); err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/core/web"
+)
+
+func (t *Transport) handlerCreateStepUpChallenge(w http.ResponseWriter, r *http.Request) {
+	var transactionData TransactionData
+	if err := json.NewDecoder(r.Body).Decode(&transactionData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &transactionData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	senderId, err := t.resolveAccountId(r.Context(), transactionData.SenderId, transactionData.SenderNumber)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+	var challenge web.StepUpChallengeData
+	if transactionData.ReceiverAlias != "" {
+		challenge, err = t.service.CreateStepUpChallengeToAlias(r.Context(), senderId, transactionData.ReceiverAlias, transactionData.AmountCents, claims.Sub, transactionData.Description)
+	} else {
+		var receiverId int64
+		receiverId, err = t.resolveAccountId(r.Context(), transactionData.ReceiverId, transactionData.ReceiverNumber)
+		if err == nil {
+			challenge, err = t.service.CreateStepUpChallenge(r.Context(), senderId, receiverId, transactionData.AmountCents, claims.Sub, transactionData.Description)
+		}
+	}
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(StepUpChallengeResponse{
+		ChallengeId: challenge.Id,
+		ExpiresAt:   challenge.ExpiresAt.Format("2006.01.02 15:04:05"),
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
+func (t *Transport) handlerConfirmStepUpChallenge(w http.ResponseWriter, r *http.Request) {
+	challengeId, err := strconv.ParseInt(r.PathValue("challengeId"), 10, 64)
+	if err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	var confirmData StepUpConfirmData
+	if err = json.NewDecoder(r.Body).Decode(&confirmData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &confirmData) {
+		return
+	}
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	token, expiresAt, err := t.service.ConfirmStepUpChallenge(r.Context(), challengeId, claims.Sub, confirmData.Code)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(StepUpConfirmResponse{
+		ConfirmationToken: token,
+		ExpiresAt:         expiresAt.Format("2006.01.02 15:04:05"),
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
@@ -0,0 +1,23 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"x-bank-ms-bank/auth"
+)
+
+func (t *Transport) handlerLogout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims в контексте"))
+		return
+	}
+
+	if err := t.service.RevokeToken(r.Context(), claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
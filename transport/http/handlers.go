@@ -1,11 +1,15 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/core/web"
+	"x-bank-ms-bank/iban"
 )
 
 const (
@@ -14,8 +18,36 @@ const (
 	defaultLimit  = 20
 	minOffset     = 0
 	defaultOffset = 0
+
+	atmTokenTTL = 15 * time.Minute
 )
 
+func (t *Transport) accountIdFromPath(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	value := r.PathValue("accountId")
+	if accountId, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return accountId, true
+	}
+
+	number := iban.Normalize(value)
+	if !iban.IsValid(number) {
+		t.errorHandler.setUnprocessableEntityError(w, validationErrors{"Неверный номер счёта"})
+		return 0, false
+	}
+	accountId, err := t.service.ResolveAccountNumber(r.Context(), number)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return 0, false
+	}
+	return accountId, true
+}
+
+func (t *Transport) resolveAccountId(ctx context.Context, accountId int64, accountNumber string) (int64, error) {
+	if accountNumber == "" {
+		return accountId, nil
+	}
+	return t.service.ResolveAccountNumber(ctx, accountNumber)
+}
+
 func (t *Transport) handlerNotFound(w http.ResponseWriter, _ *http.Request) {
 	t.errorHandler.setNotFoundError(w)
 }
@@ -37,9 +69,14 @@ func (t *Transport) handlerUserAccounts(w http.ResponseWriter, r *http.Request)
 	if data != nil {
 		for _, entry := range data {
 			userAccountsItem := UserAccountsResponseItem{
-				Id:           entry.Id,
-				BalanceCents: entry.BalanceCents,
-				Status:       entry.Status,
+				Id:                    entry.Id,
+				Number:                entry.Number,
+				BalanceCents:          entry.BalanceCents,
+				BookedBalanceCents:    entry.BookedCents,
+				AvailableBalanceCents: entry.AvailableCents,
+				PendingIncomingCents:  entry.PendingIncomingCents,
+				PendingOutgoingCents:  entry.PendingOutgoingCents,
+				Status:                entry.Status,
 			}
 			response.Items = append(response.Items, userAccountsItem)
 		}
@@ -48,7 +85,7 @@ func (t *Transport) handlerUserAccounts(w http.ResponseWriter, r *http.Request)
 	}
 
 	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(data)
+	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		t.errorHandler.setError(w, err)
 		return
@@ -71,9 +108,9 @@ func (t *Transport) handlerOpenAccount(w http.ResponseWriter, r *http.Request) {
 }
 
 func (t *Transport) handlerBlockAccount(w http.ResponseWriter, r *http.Request) {
-	accountId, err := strconv.ParseInt(r.PathValue("accountId"), 10, 64)
-	if err != nil {
-		t.errorHandler.setBadRequestError(w, err)
+	accountId, ok := t.accountIdFromPath(w, r)
+	if !ok {
+		return
 	}
 	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
 	if !ok {
@@ -84,14 +121,15 @@ func (t *Transport) handlerBlockAccount(w http.ResponseWriter, r *http.Request)
 
 	if err := t.service.BlockAccount(r.Context(), accountId, userId); err != nil {
 		t.errorHandler.setError(w, err)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
 func (t *Transport) handlerAccountHistory(w http.ResponseWriter, r *http.Request) {
-	accountId, err := strconv.ParseInt(r.PathValue("accountId"), 10, 64)
-	if err != nil {
-		t.errorHandler.setBadRequestError(w, err)
+	accountId, ok := t.accountIdFromPath(w, r)
+	if !ok {
+		return
 	}
 	limit, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
 	if err != nil || limit < minLimit || limit > maxLimit {
@@ -109,22 +147,28 @@ func (t *Transport) handlerAccountHistory(w http.ResponseWriter, r *http.Request
 	}
 	userId := claims.Sub
 
-	data, total, err := t.service.GetAccountHistory(r.Context(), accountId, userId, limit, offset)
+	accountData, data, total, err := t.service.GetAccountHistory(r.Context(), accountId, userId, limit, offset)
 	if err != nil {
 		t.errorHandler.setError(w, err)
 		return
 	}
 
-	var response AccountsHistoryResponse
+	response := AccountsHistoryResponse{
+		BookedBalanceCents:    accountData.BookedCents,
+		AvailableBalanceCents: accountData.AvailableCents,
+		PendingIncomingCents:  accountData.PendingIncomingCents,
+		PendingOutgoingCents:  accountData.PendingOutgoingCents,
+	}
 	if data != nil {
 		for _, entry := range data {
 			userAccountsItem := AccountsHistoryResponseItem{
-				SenderId:    entry.SenderId,
-				ReceiverId:  entry.ReceiverId,
-				Status:      entry.Status,
-				CreatedAt:   entry.CreatedAt.Format("2006.01.02 15:04:05"),
-				AmountCents: entry.AmountCents,
-				Description: entry.Description,
+				SenderId:             entry.SenderId,
+				ReceiverId:           entry.ReceiverId,
+				Status:               entry.Status,
+				CreatedAt:            entry.CreatedAt.Format("2006.01.02 15:04:05"),
+				AmountCents:          entry.AmountCents,
+				Description:          entry.Description,
+				CounterpartyNickname: entry.CounterpartyNickname,
 			}
 			response.Items = append(response.Items, userAccountsItem)
 		}
@@ -157,7 +201,23 @@ func (t *Transport) handlerAccountTransaction(w http.ResponseWriter, r *http.Req
 	}
 	userId := claims.Sub
 
-	if err := t.service.MakeTransaction(r.Context(), transactionData.SenderId, transactionData.ReceiverId, transactionData.AmountCents, userId, transactionData.Description); err != nil {
+	senderId, err := t.resolveAccountId(r.Context(), transactionData.SenderId, transactionData.SenderNumber)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+	if transactionData.ReceiverAlias != "" {
+		_, err = t.service.MakeTransactionToAlias(r.Context(), senderId, transactionData.ReceiverAlias, transactionData.AmountCents, userId, transactionData.Description,
+			transactionData.ConfirmationToken)
+	} else {
+		var receiverId int64
+		receiverId, err = t.resolveAccountId(r.Context(), transactionData.ReceiverId, transactionData.ReceiverNumber)
+		if err == nil {
+			_, err = t.service.MakeUserTransaction(r.Context(), senderId, receiverId, transactionData.AmountCents, userId, transactionData.Description,
+				transactionData.ConfirmationToken)
+		}
+	}
+	if err != nil {
 		t.errorHandler.setError(w, err)
 		return
 	}
@@ -165,8 +225,48 @@ func (t *Transport) handlerAccountTransaction(w http.ResponseWriter, r *http.Req
 	w.WriteHeader(http.StatusOK)
 }
 
+func (t *Transport) handlerATMLogin(w http.ResponseWriter, r *http.Request) {
+	var atmAuthData ATMAuthData
+	if err := json.NewDecoder(r.Body).Decode(&atmAuthData); err != nil {
+		t.errorHandler.setBadRequestError(w, err)
+		return
+	}
+	if !t.validate(w, &atmAuthData) {
+		return
+	}
+
+	sessionData, err := t.service.ATMLogin(r.Context(), atmAuthData.Login, atmAuthData.Password)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	now := time.Now()
+	claims := auth.ATMClaims{
+		Id:        sessionData.Id,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(atmTokenTTL).Unix(),
+		AtmId:     sessionData.AtmId,
+	}
+	token, err := t.atmAuthorizer.AuthorizeATM(r.Context(), claims)
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(ATMLoginResponse{
+		Token:     string(token),
+		ExpiresAt: claims.ExpiresAt,
+	})
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
+}
+
 func (t *Transport) handlerATMSupplement(w http.ResponseWriter, r *http.Request) {
-	var atmSupplementData ATMOperationData
+	var atmSupplementData ATMSupplementData
 	if err := json.NewDecoder(r.Body).Decode(&atmSupplementData); err != nil {
 		t.errorHandler.setBadRequestError(w, err)
 		return
@@ -175,17 +275,15 @@ func (t *Transport) handlerATMSupplement(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	basic, ok := r.Context().Value(t.basicCtxKey).(ATMAuthData)
+	atmClaims, ok := r.Context().Value(t.atmClaimsCtxKey).(*auth.ATMClaims)
 	if !ok {
-		t.errorHandler.setError(w, errors.New("отсутствуют basic auth в контексте"))
-		return
-	}
-	if !t.validate(w, &basic) {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims банкомата в контексте"))
 		return
 	}
 
-	if err := t.service.ATMSupplement(r.Context(), basic.Login, basic.Password, atmSupplementData.AmountCents); err != nil {
+	if err := t.service.ATMSupplement(r.Context(), atmClaims.AtmId, toWebBanknotes(atmSupplementData.Banknotes)); err != nil {
 		t.errorHandler.setError(w, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -201,24 +299,28 @@ func (t *Transport) handlerATMWithdrawal(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	basic, ok := r.Context().Value(t.basicCtxKey).(ATMAuthData)
+	atmClaims, ok := r.Context().Value(t.atmClaimsCtxKey).(*auth.ATMClaims)
 	if !ok {
-		t.errorHandler.setError(w, errors.New("отсутствуют basic auth в контексте"))
-		return
-	}
-	if !t.validate(w, &basic) {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims банкомата в контексте"))
 		return
 	}
 
-	if err := t.service.ATMWithdrawal(r.Context(), basic.Login, basic.Password, atmWithdrawalData.AmountCents); err != nil {
+	banknotes, err := t.service.ATMWithdrawal(r.Context(), atmClaims.AtmId, atmWithdrawalData.AmountCents)
+	if err != nil {
 		t.errorHandler.setError(w, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(newATMWithdrawalResponse(atmWithdrawalData.AmountCents, banknotes))
+	if err != nil {
+		t.errorHandler.setError(w, err)
+		return
+	}
 }
 
 func (t *Transport) handlerATMUserSupplement(w http.ResponseWriter, r *http.Request) {
-	var atmUserSupplementData ATMUserOperationData
+	var atmUserSupplementData ATMUserSupplementData
 	if err := json.NewDecoder(r.Body).Decode(&atmUserSupplementData); err != nil {
 		t.errorHandler.setBadRequestError(w, err)
 		return
@@ -227,17 +329,16 @@ func (t *Transport) handlerATMUserSupplement(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	basic, ok := r.Context().Value(t.basicCtxKey).(ATMAuthData)
+	atmClaims, ok := r.Context().Value(t.atmClaimsCtxKey).(*auth.ATMClaims)
 	if !ok {
-		t.errorHandler.setError(w, errors.New("отсутствуют basic auth в контексте"))
-		return
-	}
-	if !t.validate(w, &basic) {
+		t.errorHandler.setError(w, errors.New("отсутствуют claims банкомата в контексте"))
 		return
 	}
 
-	if err := t.service.ATMUserSupplement(r.Context(), basic.Login, basic.Password, atmUserSupplementData.AmountCents, atmUserSupplementData.AccountId, 0); err != nil {
+	err := t.service.ATMUserSupplement(r.Context(), atmClaims.AtmId, toWebBanknotes(atmUserSupplementData.Banknotes), atmUserSupplementData.CardNumber, atmUserSupplementData.Pin)
+	if err != nil {
 		t.errorHandler.setError(w, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -253,18 +354,47 @@ func (t *Transport) handlerATMUserWithdrawal(w http.ResponseWriter, r *http.Requ
 	if !t.validate(w, &atmUserWithdrawalData) {
 		return
 	}
-	basic, ok := r.Context().Value(t.basicCtxKey).(ATMAuthData)
+	atmClaims, ok := r.Context().Value(t.atmClaimsCtxKey).(*auth.ATMClaims)
 	if !ok {
-		t.errorHandler.setError(w, errors.New("отсутствуют basic auth в контексте"))
+		t.errorHandler.setError(w, errors.New("отсутствуют claims банкомата в контексте"))
 		return
 	}
-	if !t.validate(w, &basic) {
+
+	banknotes, err := t.service.ATMUserWithdrawal(r.Context(), atmClaims.AtmId, atmUserWithdrawalData.AmountCents, atmUserWithdrawalData.CardNumber, atmUserWithdrawalData.Pin)
+	if err != nil {
+		t.errorHandler.setError(w, err)
 		return
 	}
 
-	if err := t.service.ATMUserWithdrawal(r.Context(), basic.Login, basic.Password, atmUserWithdrawalData.AmountCents, atmUserWithdrawalData.AccountId, 0); err != nil {
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(newATMWithdrawalResponse(atmUserWithdrawalData.AmountCents, banknotes))
+	if err != nil {
 		t.errorHandler.setError(w, err)
+		return
 	}
+}
 
-	w.WriteHeader(http.StatusOK)
+func toWebBanknotes(banknotes []ATMBanknotesData) []web.AtmBanknotesData {
+	result := make([]web.AtmBanknotesData, 0, len(banknotes))
+	for _, entry := range banknotes {
+		result = append(result, web.AtmBanknotesData{
+			DenominationCents: entry.DenominationCents,
+			Count:             entry.Count,
+		})
+	}
+	return result
+}
+
+func newATMWithdrawalResponse(amountCents int64, banknotes []web.AtmBanknotesData) ATMWithdrawalResponse {
+	response := ATMWithdrawalResponse{
+		AmountCents: amountCents,
+		Banknotes:   make([]ATMBanknotesData, 0, len(banknotes)),
+	}
+	for _, entry := range banknotes {
+		response.Banknotes = append(response.Banknotes, ATMBanknotesData{
+			DenominationCents: entry.DenominationCents,
+			Count:             entry.Count,
+		})
+	}
+	return response
 }
@@ -8,7 +8,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"strings"
-	"time"
 	"x-bank-ms-bank/auth"
 	"x-bank-ms-bank/cerrors"
 	"x-bank-ms-bank/ercodes"
@@ -16,23 +15,72 @@ import (
 
 type (
 	HS512 struct {
-		secret []byte
+		secret    []byte
+		validator Validator
+	}
+
+	jwtHeader struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+		Kid string `json:"kid,omitempty"`
 	}
 )
 
-func NewHS512(secret string) (HS512, error) {
+const (
+	typJWT = "JWT"
+	typATM = "ATM"
+)
+
+const algHS512 = "HS512"
+
+func NewHS512(secret string, validator Validator) (HS512, error) {
 	hs512SecretKey, err := hex.DecodeString(secret)
 	if err != nil {
 		return HS512{}, err
 	}
 	return HS512{
-		secret: hs512SecretKey,
+		secret:    hs512SecretKey,
+		validator: validator,
 	}, nil
 }
 
 func (R *HS512) Authorize(_ context.Context, claims auth.Claims) ([]byte, error) {
+	return R.sign(typJWT, claims)
+}
+
+func (R *HS512) VerifyAuthorization(ctx context.Context, authorization []byte) (auth.Claims, error) {
+	var userClaims auth.Claims
+	typ, err := R.verify(ctx, authorization, &userClaims)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	if typ != "" && typ != typJWT {
+		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, nil, "Токен не валиден")
+	}
+
+	return userClaims, nil
+}
+
+func (R *HS512) AuthorizeATM(_ context.Context, claims auth.ATMClaims) ([]byte, error) {
+	return R.sign(typATM, claims)
+}
+
+func (R *HS512) VerifyATMAuthorization(ctx context.Context, authorization []byte) (auth.ATMClaims, error) {
+	var atmClaims auth.ATMClaims
+	typ, err := R.verify(ctx, authorization, &atmClaims)
+	if err != nil {
+		return auth.ATMClaims{}, err
+	}
+	if typ != typATM || atmClaims.AtmId == 0 {
+		return auth.ATMClaims{}, cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, nil, "Токен не валиден")
+	}
+
+	return atmClaims, nil
+}
+
+func (R *HS512) sign(typ string, claims any) ([]byte, error) {
 	mac := hmac.New(sha512.New, R.secret)
-	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS512","typ":"JWT"}`))
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + algHS512 + `","typ":"` + typ + `"}`))
 
 	claimsJSON, err := json.Marshal(claims)
 	if err != nil {
@@ -50,11 +98,11 @@ func (R *HS512) Authorize(_ context.Context, claims auth.Claims) ([]byte, error)
 	return []byte(token), nil
 }
 
-func (R *HS512) VerifyAuthorization(_ context.Context, authorization []byte) (auth.Claims, error) {
+func (R *HS512) verify(ctx context.Context, authorization []byte, claims any) (string, error) {
 	data := strings.Split(string(authorization), ".")
 
 	if len(data) != 3 {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, nil, "Токен не валиден")
+		return "", cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, nil, "Токен не валиден")
 	}
 
 	mac := hmac.New(sha512.New, R.secret)
@@ -62,31 +110,42 @@ func (R *HS512) VerifyAuthorization(_ context.Context, authorization []byte) (au
 
 	_, err := mac.Write([]byte(signData))
 	if err != nil {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Ошибка при подписывании токена")
+		return "", cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Ошибка при подписывании токена")
 	}
 	signature := mac.Sum(nil)
 
 	providedSignature, err := base64.RawURLEncoding.DecodeString(data[2])
 	if err != nil {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Ошибка преобразования подписи")
+		return "", cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Ошибка преобразования подписи")
 	}
 	if !hmac.Equal(signature, providedSignature) {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Токен не валиден")
+		return "", cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Токен не валиден")
 	}
-	claimsJSON, err := base64.RawURLEncoding.DecodeString(data[1])
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(data[0])
 	if err != nil {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Ошибка преобразования payload")
+		return "", cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Ошибка преобразования заголовка")
+	}
+	var tokenHeader jwtHeader
+	if err = json.Unmarshal(headerJSON, &tokenHeader); err != nil {
+		return "", cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Заголовок токена не соответствует шаблону")
+	}
+	if tokenHeader.Alg != algHS512 {
+		return "", cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, nil, "Токен не валиден")
 	}
 
-	var userClaims auth.Claims
-	err = json.Unmarshal(claimsJSON, &userClaims)
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(data[1])
 	if err != nil {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Данные авторизации не соответствуют шаблону")
+		return "", cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Ошибка преобразования payload")
 	}
 
-	if userClaims.ExpiresAt < time.Now().Unix() {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Время жизни токена истекло")
+	err = json.Unmarshal(claimsJSON, claims)
+	if err != nil {
+		return "", cerrors.NewErrorWithUserMessage(ercodes.HS512Authorization, err, "Данные авторизации не соответствуют шаблону")
+	}
+	if err = R.validator.validate(ctx, claimsJSON, ercodes.HS512Authorization); err != nil {
+		return "", err
 	}
 
-	return userClaims, nil
+	return tokenHeader.Typ, nil
 }
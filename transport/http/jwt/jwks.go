@@ -0,0 +1,250 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	jwksRefreshInterval   = 10 * time.Minute
+	jwksMinReloadInterval = 30 * time.Second
+	jwksRequestTimeout    = 5 * time.Second
+	jwksMaxSize           = 1 << 20
+)
+
+type (
+	JWKS struct {
+		source    string
+		client    *http.Client
+		validator Validator
+
+		mu       sync.RWMutex
+		keys     map[string]jwksKey
+		loadedAt time.Time
+	}
+
+	jwksKey struct {
+		alg string
+		key crypto.PublicKey
+	}
+
+	jwksDocument struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+)
+
+func NewJWKS(source string, validator Validator) (*JWKS, error) {
+	if source == "" {
+		return nil, errors.New("jwksSource is not set")
+	}
+
+	jwks := &JWKS{
+		source:    source,
+		client:    &http.Client{Timeout: jwksRequestTimeout},
+		validator: validator,
+	}
+	if err := jwks.reload(); err != nil {
+		return nil, err
+	}
+	return jwks, nil
+}
+
+func (j *JWKS) Authorize(_ context.Context, _ auth.Claims) ([]byte, error) {
+	return nil, cerrors.NewErrorWithUserMessage(ercodes.JWKSAuthorization, nil, "Выпуск токенов по JWKS не поддерживается")
+}
+
+func (j *JWKS) VerifyAuthorization(ctx context.Context, authorization []byte) (auth.Claims, error) {
+	token, err := parseToken(authorization, ercodes.JWKSAuthorization)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+
+	key, ok := j.key(token.header.Kid)
+	if !ok {
+		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.JWKSAuthorization, nil, "Неизвестный ключ подписи")
+	}
+	return verifyToken(ctx, token, key.alg, key.key, &j.validator, ercodes.JWKSAuthorization)
+}
+
+func (j *JWKS) key(kid string) (jwksKey, bool) {
+	j.mu.RLock()
+	key, ok := j.lookup(kid)
+	loadedAt := j.loadedAt
+	j.mu.RUnlock()
+
+	sinceLoad := time.Since(loadedAt)
+	if (ok && sinceLoad < jwksRefreshInterval) || (!ok && sinceLoad < jwksMinReloadInterval) {
+		return key, ok
+	}
+
+	if err := j.reload(); err != nil {
+		log.Println(err)
+		j.mu.Lock()
+		j.loadedAt = time.Now()
+		j.mu.Unlock()
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.lookup(kid)
+}
+
+func (j *JWKS) lookup(kid string) (jwksKey, bool) {
+	if kid == "" {
+		if len(j.keys) != 1 {
+			return jwksKey{}, false
+		}
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *JWKS) reload() error {
+	data, err := j.read()
+	if err != nil {
+		return err
+	}
+
+	var document jwksDocument
+	if err = json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	keys := make(map[string]jwksKey, len(document.Keys))
+	for _, item := range document.Keys {
+		if item.Use != "" && item.Use != "sig" {
+			continue
+		}
+		key, err := item.parse()
+		if err != nil {
+			return fmt.Errorf("jwks key %q: %w", item.Kid, err)
+		}
+		keys[item.Kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("jwks document has no signing keys")
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.loadedAt = time.Now()
+	j.mu.Unlock()
+	return nil
+}
+
+func (j *JWKS) read() ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	resp, err := j.client.Get(j.source)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks request failed: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, jwksMaxSize))
+}
+
+func (k jwk) parse() (jwksKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return jwksKey{}, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return jwksKey{}, err
+		}
+		if !e.IsInt64() {
+			return jwksKey{}, errors.New("invalid RSA exponent")
+		}
+		return k.withAlg(algRS256, &rsa.PublicKey{N: n, E: int(e.Int64())})
+	case "EC":
+		if k.Crv != "P-256" {
+			return jwksKey{}, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return jwksKey{}, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return jwksKey{}, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return jwksKey{}, errors.New("point is not on curve")
+		}
+		return k.withAlg(algES256, &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y})
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return jwksKey{}, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return jwksKey{}, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return jwksKey{}, errors.New("invalid Ed25519 key size")
+		}
+		return k.withAlg(algEdDSA, ed25519.PublicKey(x))
+	default:
+		return jwksKey{}, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func (k jwk) withAlg(alg string, key crypto.PublicKey) (jwksKey, error) {
+	if k.Alg != "" && k.Alg != alg {
+		return jwksKey{}, fmt.Errorf("unsupported algorithm %q", k.Alg)
+	}
+	return jwksKey{alg: alg, key: key}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
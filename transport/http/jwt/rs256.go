@@ -3,16 +3,6 @@ package jwt
 import (
 	"context"
 	"crypto"
-	"crypto/rsa"
-	"crypto/sha256"
-	"crypto/x509"
-	"encoding/base64"
-	"encoding/json"
-	"encoding/pem"
-	"errors"
-	"os"
-	"strings"
-	"time"
 	"x-bank-ms-bank/auth"
 	"x-bank-ms-bank/cerrors"
 	"x-bank-ms-bank/ercodes"
@@ -20,131 +10,89 @@ import (
 
 type (
 	RS256 struct {
-		PrivateKey *rsa.PrivateKey
-		PublicKey  *rsa.PublicKey
+		asymmetric
 	}
-)
-
-func NewRS256(pathPrivateKey, pathPublicKey string) (RS256, error) {
-	privateKey, err := func(path string) (*rsa.PrivateKey, error) {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil, err
-		}
-
-		block, _ := pem.Decode(data)
-		if block == nil || block.Type != "PRIVATE KEY" {
-			return nil, errors.New("Ошибка парсинга ключа")
-
-		}
-
-		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
-		if err != nil {
-			return nil, err
-		}
-
-		rsaPrivateKey, ok := key.(*rsa.PrivateKey)
-		if !ok {
-			return nil, errors.New("Ошибка преобразования в rsa.PrivateKey")
-		}
-
-		return rsaPrivateKey, nil
-	}(pathPrivateKey)
 
-	if err != nil {
-		return RS256{}, err
+	ES256 struct {
+		asymmetric
 	}
 
-	publicKey, err := func(path string) (*rsa.PublicKey, error) {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil, err
-		}
-
-		block, _ := pem.Decode(data)
-		if block == nil || block.Type != "PUBLIC KEY" {
-			return nil, errors.New("Ошибка парсинга ключа")
-		}
-
-		key, err := x509.ParsePKIXPublicKey(block.Bytes)
-		if err != nil {
-			return nil, err
-		}
-
-		rsaPublicKey, ok := key.(*rsa.PublicKey)
-		if !ok {
-			return nil, errors.New("Ошибка преобразования в rsa.PublicKey")
-		}
+	EdDSA struct {
+		asymmetric
+	}
 
-		return rsaPublicKey, nil
-	}(pathPublicKey)
+	asymmetric struct {
+		alg        string
+		code       cerrors.Code
+		keyId      string
+		privateKey crypto.PrivateKey
+		publicKey  crypto.PublicKey
+		validator  Validator
+	}
+)
 
+func NewRS256(pathPrivateKey, pathPublicKey, keyId string, validator Validator) (RS256, error) {
+	key, err := newAsymmetric(algRS256, ercodes.RS256Authorization, pathPrivateKey, pathPublicKey, keyId, validator)
 	if err != nil {
 		return RS256{}, err
 	}
-
-	return RS256{
-		PrivateKey: privateKey,
-		PublicKey:  publicKey,
-	}, nil
+	return RS256{asymmetric: key}, nil
 }
 
-func (R *RS256) Authorize(ctx context.Context, claims auth.Claims) ([]byte, error) {
-	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
-
-	claimsJSON, err := json.Marshal(claims)
+func NewES256(pathPrivateKey, pathPublicKey, keyId string, validator Validator) (ES256, error) {
+	key, err := newAsymmetric(algES256, ercodes.ES256Authorization, pathPrivateKey, pathPublicKey, keyId, validator)
 	if err != nil {
-		return nil, cerrors.NewErrorWithUserMessage(ercodes.RS256Authorization, err, "Ошибка преобразования payload")
+		return ES256{}, err
 	}
-	payload := base64.RawURLEncoding.EncodeToString(claimsJSON)
-
-	signData := header + "." + payload
-	hashed := sha256.Sum256([]byte(signData))
+	return ES256{asymmetric: key}, nil
+}
 
-	signature, err := rsa.SignPKCS1v15(nil, R.PrivateKey, crypto.SHA256, hashed[:])
+func NewEdDSA(pathPrivateKey, pathPublicKey, keyId string, validator Validator) (EdDSA, error) {
+	key, err := newAsymmetric(algEdDSA, ercodes.EdDSAAuthorization, pathPrivateKey, pathPublicKey, keyId, validator)
 	if err != nil {
-		return nil, cerrors.NewErrorWithUserMessage(ercodes.RS256Authorization, err, "Ошибка при подписывании токена")
+		return EdDSA{}, err
 	}
-
-	token := header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(signature)
-
-	return []byte(token), nil
+	return EdDSA{asymmetric: key}, nil
 }
 
-func (R *RS256) VerifyAuthorization(ctx context.Context, authorization []byte) (auth.Claims, error) {
-	data := strings.Split(string(authorization), ".")
-	if len(data) != 3 {
-		return auth.Claims{}, errors.New("Токен не валиден")
+func newAsymmetric(alg string, code cerrors.Code, pathPrivateKey, pathPublicKey, keyId string, validator Validator) (asymmetric, error) {
+	var privateKey crypto.PrivateKey
+	if pathPrivateKey != "" {
+		var err error
+		if privateKey, err = readPrivateKey(pathPrivateKey); err != nil {
+			return asymmetric{}, err
+		}
 	}
 
-	signData := data[0] + "." + data[1]
-
-	providedSignature, err := base64.RawURLEncoding.DecodeString(data[2])
+	publicKey, err := readPublicKey(pathPublicKey)
 	if err != nil {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.RS256Authorization, err, "Ошибка преобразования подписи")
+		return asymmetric{}, err
 	}
 
-	hashed := sha256.Sum256([]byte(signData))
-
-	err = rsa.VerifyPKCS1v15(R.PublicKey, crypto.SHA256, hashed[:], providedSignature)
-	if err != nil {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.RS256Authorization, err, "Токен не валиден")
-	}
+	return asymmetric{
+		alg:        alg,
+		code:       code,
+		keyId:      keyId,
+		privateKey: privateKey,
+		publicKey:  publicKey,
+		validator:  validator,
+	}, nil
+}
 
-	claimsJSON, err := base64.RawURLEncoding.DecodeString(data[1])
-	if err != nil {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.RS256Authorization, err, "Ошибка преобразования payload")
+func (a *asymmetric) Authorize(_ context.Context, claims auth.Claims) ([]byte, error) {
+	if a.privateKey == nil {
+		return nil, cerrors.NewErrorWithUserMessage(a.code, nil, "Закрытый ключ не задан")
 	}
+	return signToken(a.alg, a.keyId, a.privateKey, claims, a.code)
+}
 
-	var claims auth.Claims
-	err = json.Unmarshal(claimsJSON, &claims)
+func (a *asymmetric) VerifyAuthorization(ctx context.Context, authorization []byte) (auth.Claims, error) {
+	token, err := parseToken(authorization, a.code)
 	if err != nil {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.RS256Authorization, err, "Данные авторизации не соответствуют шаблону")
+		return auth.Claims{}, err
 	}
-
-	if claims.ExpiresAt < time.Now().Unix() {
-		return auth.Claims{}, cerrors.NewErrorWithUserMessage(ercodes.RS256Authorization, errors.New("Время жизни токена истекло"), "Время жизни токена истекло")
+	if token.header.Kid != "" && a.keyId != "" && token.header.Kid != a.keyId {
+		return auth.Claims{}, cerrors.NewErrorWithUserMessage(a.code, nil, "Неизвестный ключ подписи")
 	}
-
-	return claims, nil
+	return verifyToken(ctx, token, a.alg, a.publicKey, &a.validator, a.code)
 }
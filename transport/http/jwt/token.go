@@ -0,0 +1,177 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/cerrors"
+)
+
+const (
+	algRS256 = "RS256"
+	algES256 = "ES256"
+	algEdDSA = "EdDSA"
+
+	es256KeySize = 32
+)
+
+type (
+	parsedToken struct {
+		header     jwtHeader
+		signData   string
+		signature  []byte
+		claimsJSON []byte
+	}
+)
+
+func signToken(alg, kid string, key crypto.PrivateKey, claims any, code cerrors.Code) ([]byte, error) {
+	headerJSON, err := json.Marshal(jwtHeader{Alg: alg, Typ: typJWT, Kid: kid})
+	if err != nil {
+		return nil, cerrors.NewErrorWithUserMessage(code, err, "Ошибка преобразования заголовка")
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return nil, cerrors.NewErrorWithUserMessage(code, err, "Ошибка преобразования payload")
+	}
+	signData := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	signature, err := sign(alg, key, signData)
+	if err != nil {
+		return nil, cerrors.NewErrorWithUserMessage(code, err, "Ошибка при подписывании токена")
+	}
+	return []byte(signData + "." + base64.RawURLEncoding.EncodeToString(signature)), nil
+}
+
+func parseToken(authorization []byte, code cerrors.Code) (parsedToken, error) {
+	data := strings.Split(string(authorization), ".")
+	if len(data) != 3 {
+		return parsedToken{}, cerrors.NewErrorWithUserMessage(code, nil, "Токен не валиден")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(data[0])
+	if err != nil {
+		return parsedToken{}, cerrors.NewErrorWithUserMessage(code, err, "Ошибка преобразования заголовка")
+	}
+	var header jwtHeader
+	if err = json.Unmarshal(headerJSON, &header); err != nil {
+		return parsedToken{}, cerrors.NewErrorWithUserMessage(code, err, "Заголовок токена не соответствует шаблону")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(data[2])
+	if err != nil {
+		return parsedToken{}, cerrors.NewErrorWithUserMessage(code, err, "Ошибка преобразования подписи")
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(data[1])
+	if err != nil {
+		return parsedToken{}, cerrors.NewErrorWithUserMessage(code, err, "Ошибка преобразования payload")
+	}
+
+	return parsedToken{
+		header:     header,
+		signData:   data[0] + "." + data[1],
+		signature:  signature,
+		claimsJSON: claimsJSON,
+	}, nil
+}
+
+func verifyToken(ctx context.Context, token parsedToken, alg string, key crypto.PublicKey, validator *Validator, code cerrors.Code) (auth.Claims, error) {
+	if token.header.Alg != alg || (token.header.Typ != "" && token.header.Typ != typJWT) {
+		return auth.Claims{}, cerrors.NewErrorWithUserMessage(code, nil, "Токен не валиден")
+	}
+	if err := verify(alg, key, token.signData, token.signature); err != nil {
+		return auth.Claims{}, cerrors.NewErrorWithUserMessage(code, err, "Токен не валиден")
+	}
+
+	var claims auth.Claims
+	if err := json.Unmarshal(token.claimsJSON, &claims); err != nil {
+		return auth.Claims{}, cerrors.NewErrorWithUserMessage(code, err, "Данные авторизации не соответствуют шаблону")
+	}
+	if err := validator.validate(ctx, token.claimsJSON, code); err != nil {
+		return auth.Claims{}, err
+	}
+
+	return claims, nil
+}
+
+func sign(alg string, key crypto.PrivateKey, signData string) ([]byte, error) {
+	switch alg {
+	case algRS256:
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("ключ не подходит для RS256")
+		}
+		hashed := sha256.Sum256([]byte(signData))
+		return rsa.SignPKCS1v15(nil, rsaKey, crypto.SHA256, hashed[:])
+	case algES256:
+		ecKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("ключ не подходит для ES256")
+		}
+		hashed := sha256.Sum256([]byte(signData))
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, hashed[:])
+		if err != nil {
+			return nil, err
+		}
+		signature := make([]byte, 2*es256KeySize)
+		r.FillBytes(signature[:es256KeySize])
+		s.FillBytes(signature[es256KeySize:])
+		return signature, nil
+	case algEdDSA:
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("ключ не подходит для EdDSA")
+		}
+		return ed25519.Sign(edKey, []byte(signData)), nil
+	default:
+		return nil, errors.New("неподдерживаемый алгоритм подписи")
+	}
+}
+
+func verify(alg string, key crypto.PublicKey, signData string, signature []byte) error {
+	switch alg {
+	case algRS256:
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("ключ не подходит для RS256")
+		}
+		hashed := sha256.Sum256([]byte(signData))
+		return rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, hashed[:], signature)
+	case algES256:
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("ключ не подходит для ES256")
+		}
+		if len(signature) != 2*es256KeySize {
+			return errors.New("неверная длина подписи")
+		}
+		hashed := sha256.Sum256([]byte(signData))
+		r := new(big.Int).SetBytes(signature[:es256KeySize])
+		s := new(big.Int).SetBytes(signature[es256KeySize:])
+		if !ecdsa.Verify(ecKey, hashed[:], r, s) {
+			return errors.New("подпись не совпадает")
+		}
+		return nil
+	case algEdDSA:
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return errors.New("ключ не подходит для EdDSA")
+		}
+		if !ed25519.Verify(edKey, []byte(signData), signature) {
+			return errors.New("подпись не совпадает")
+		}
+		return nil
+	default:
+		return errors.New("неподдерживаемый алгоритм подписи")
+	}
+}
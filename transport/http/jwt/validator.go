@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+	"x-bank-ms-bank/cerrors"
+)
+
+type (
+	TokenDenylist interface {
+		IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	}
+
+	Validator struct {
+		issuer    string
+		audience  string
+		clockSkew time.Duration
+		denylist  TokenDenylist
+	}
+
+	registeredClaims struct {
+		Id        string   `json:"jti"`
+		Issuer    string   `json:"iss"`
+		Audience  audience `json:"aud"`
+		IssuedAt  *int64   `json:"iat"`
+		NotBefore *int64   `json:"nbf"`
+		ExpiresAt *int64   `json:"exp"`
+	}
+
+	audience []string
+)
+
+func NewValidator(issuer, audience string, clockSkew time.Duration, denylist TokenDenylist) Validator {
+	return Validator{
+		issuer:    issuer,
+		audience:  audience,
+		clockSkew: clockSkew,
+		denylist:  denylist,
+	}
+}
+
+func (v *Validator) validate(ctx context.Context, claimsJSON []byte, code cerrors.Code) error {
+	var claims registeredClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return cerrors.NewErrorWithUserMessage(code, err, "Данные авторизации не соответствуют шаблону")
+	}
+
+	now := time.Now()
+	if claims.ExpiresAt == nil {
+		return cerrors.NewErrorWithUserMessage(code, nil, "Не задано время жизни токена")
+	}
+	if !now.Add(-v.clockSkew).Before(time.Unix(*claims.ExpiresAt, 0)) {
+		return cerrors.NewErrorWithUserMessage(code, nil, "Время жизни токена истекло")
+	}
+	if claims.NotBefore != nil && now.Add(v.clockSkew).Before(time.Unix(*claims.NotBefore, 0)) {
+		return cerrors.NewErrorWithUserMessage(code, nil, "Токен ещё не действителен")
+	}
+	if claims.IssuedAt != nil && now.Add(v.clockSkew).Before(time.Unix(*claims.IssuedAt, 0)) {
+		return cerrors.NewErrorWithUserMessage(code, nil, "Токен выпущен в будущем")
+	}
+
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return cerrors.NewErrorWithUserMessage(code, nil, "Неверный издатель токена")
+	}
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return cerrors.NewErrorWithUserMessage(code, nil, "Токен выпущен для другого получателя")
+	}
+
+	if v.denylist == nil {
+		return nil
+	}
+	if claims.Id == "" {
+		return cerrors.NewErrorWithUserMessage(code, nil, "Не задан идентификатор токена")
+	}
+	revoked, err := v.denylist.IsTokenRevoked(ctx, claims.Id)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return cerrors.NewErrorWithUserMessage(code, nil, "Токен отозван")
+	}
+	return nil
+}
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
@@ -0,0 +1,405 @@
+package jwt
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"hash"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/cerrors"
+	"x-bank-ms-bank/ercodes"
+)
+
+const (
+	testIssuer    = "x-bank-ms-auth"
+	testAudience  = "x-bank-ms-bank"
+	testClockSkew = 30 * time.Second
+	testKeyId     = "key-1"
+	testHS512Key  = "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff"
+)
+
+type testDenylist map[string]bool
+
+func (d testDenylist) IsTokenRevoked(_ context.Context, jti string) (bool, error) {
+	return d[jti], nil
+}
+
+func TestVerifyAuthorization(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyPEM := writeTestKeys(t, privateKey)
+
+	validator := NewValidator(testIssuer, testAudience, testClockSkew, testDenylist{"revoked": true})
+	rs256, err := NewRS256("", publicKeyPEM, testKeyId, validator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs512, err := NewHS512(testHS512Key, validator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := NewJWKS(writeTestJWKS(t, testKeyId, &privateKey.PublicKey), validator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKeyBytes, err := os.ReadFile(publicKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs512Secret, _ := hex.DecodeString(testHS512Key)
+
+	rsaSigner := func(signData string) []byte {
+		signature, err := sign(algRS256, privateKey, signData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+	hmacSigner := func(h func() hash.Hash, secret []byte) func(string) []byte {
+		return func(signData string) []byte {
+			mac := hmac.New(h, secret)
+			mac.Write([]byte(signData))
+			return mac.Sum(nil)
+		}
+	}
+	noneSigner := func(string) []byte { return nil }
+
+	rs256Header := jwtHeader{Alg: algRS256, Typ: typJWT, Kid: testKeyId}
+	hs512Header := jwtHeader{Alg: algHS512, Typ: typJWT}
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name       string
+		authorizer auth.Authorizer
+		code       cerrors.Code
+		token      []byte
+		wantErr    string
+	}{
+		{
+			name:       "RS256 valid",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(nil), rsaSigner),
+		},
+		{
+			name:       "HS512 valid",
+			authorizer: &hs512,
+			code:       ercodes.HS512Authorization,
+			token:      testToken(t, hs512Header, testClaims(nil), hmacSigner(sha512.New, hs512Secret)),
+		},
+		{
+			name:       "JWKS valid",
+			authorizer: jwks,
+			code:       ercodes.JWKSAuthorization,
+			token:      testToken(t, rs256Header, testClaims(nil), rsaSigner),
+		},
+		{
+			name:       "alg confusion HS256 with RSA public key",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, jwtHeader{Alg: "HS256", Typ: typJWT, Kid: testKeyId}, testClaims(nil), hmacSigner(sha256.New, publicKeyBytes)),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "alg confusion HS512 with RSA public key",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, jwtHeader{Alg: algHS512, Typ: typJWT, Kid: testKeyId}, testClaims(nil), hmacSigner(sha512.New, publicKeyBytes)),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "alg confusion HS256 via JWKS",
+			authorizer: jwks,
+			code:       ercodes.JWKSAuthorization,
+			token:      testToken(t, jwtHeader{Alg: "HS256", Typ: typJWT, Kid: testKeyId}, testClaims(nil), hmacSigner(sha256.New, publicKeyBytes)),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "alg none RS256",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, jwtHeader{Alg: "none", Typ: typJWT}, testClaims(nil), noneSigner),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "alg none HS512",
+			authorizer: &hs512,
+			code:       ercodes.HS512Authorization,
+			token:      testToken(t, jwtHeader{Alg: "none", Typ: typJWT}, testClaims(nil), noneSigner),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "alg none JWKS",
+			authorizer: jwks,
+			code:       ercodes.JWKSAuthorization,
+			token:      testToken(t, jwtHeader{Alg: "none", Typ: typJWT, Kid: testKeyId}, testClaims(nil), noneSigner),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "bad signature RS256",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      tamperPayload(t, testToken(t, rs256Header, testClaims(nil), rsaSigner)),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "bad signature HS512",
+			authorizer: &hs512,
+			code:       ercodes.HS512Authorization,
+			token:      testToken(t, hs512Header, testClaims(nil), hmacSigner(sha512.New, []byte("wrong secret"))),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "expired",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"exp": now - 60}), rsaSigner),
+			wantErr:    "Время жизни токена истекло",
+		},
+		{
+			name:       "expired within clock skew",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"exp": now - 10}), rsaSigner),
+		},
+		{
+			name:       "missing exp",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"exp": nil}), rsaSigner),
+			wantErr:    "Не задано время жизни токена",
+		},
+		{
+			name:       "not yet valid",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"nbf": now + 60}), rsaSigner),
+			wantErr:    "Токен ещё не действителен",
+		},
+		{
+			name:       "not yet valid within clock skew",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"nbf": now + 10}), rsaSigner),
+		},
+		{
+			name:       "issued in the future",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"iat": now + 60}), rsaSigner),
+			wantErr:    "Токен выпущен в будущем",
+		},
+		{
+			name:       "wrong issuer",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"iss": "other"}), rsaSigner),
+			wantErr:    "Неверный издатель токена",
+		},
+		{
+			name:       "wrong audience",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"aud": []string{"other", "another"}}), rsaSigner),
+			wantErr:    "Токен выпущен для другого получателя",
+		},
+		{
+			name:       "audience list",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"aud": []string{"other", testAudience}}), rsaSigner),
+		},
+		{
+			name:       "missing jti",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"jti": nil}), rsaSigner),
+			wantErr:    "Не задан идентификатор токена",
+		},
+		{
+			name:       "revoked jti",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, rs256Header, testClaims(map[string]any{"jti": "revoked"}), rsaSigner),
+			wantErr:    "Токен отозван",
+		},
+		{
+			name:       "unknown JWKS kid",
+			authorizer: jwks,
+			code:       ercodes.JWKSAuthorization,
+			token:      testToken(t, jwtHeader{Alg: algRS256, Typ: typJWT, Kid: "key-2"}, testClaims(nil), rsaSigner),
+			wantErr:    "Неизвестный ключ подписи",
+		},
+		{
+			name:       "unknown RS256 kid",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testToken(t, jwtHeader{Alg: algRS256, Typ: typJWT, Kid: "key-2"}, testClaims(nil), rsaSigner),
+			wantErr:    "Неизвестный ключ подписи",
+		},
+		{
+			name:       "two segments",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      []byte("eyJhbGciOiJSUzI1NiJ9.e30"),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "four segments",
+			authorizer: &hs512,
+			code:       ercodes.HS512Authorization,
+			token:      append(testToken(t, hs512Header, testClaims(nil), hmacSigner(sha512.New, hs512Secret)), ".e30"...),
+			wantErr:    "Токен не валиден",
+		},
+		{
+			name:       "header is not base64",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      []byte("!!!.e30.AAAA"),
+			wantErr:    "Ошибка преобразования заголовка",
+		},
+		{
+			name:       "header is not JSON",
+			authorizer: jwks,
+			code:       ercodes.JWKSAuthorization,
+			token:      []byte(encodeTestSegment([]byte("not json")) + ".e30.AAAA"),
+			wantErr:    "Заголовок токена не соответствует шаблону",
+		},
+		{
+			name:       "signature is not base64",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      []byte(encodeTestSegment([]byte(`{"alg":"RS256"}`)) + ".e30.!!!"),
+			wantErr:    "Ошибка преобразования подписи",
+		},
+		{
+			name:       "payload is not JSON",
+			authorizer: &rs256,
+			code:       ercodes.RS256Authorization,
+			token:      testRawToken(t, rs256Header, []byte("not json"), rsaSigner),
+			wantErr:    "Данные авторизации не соответствуют шаблону",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.authorizer.VerifyAuthorization(context.Background(), tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if claims.Sub != 1 {
+					t.Fatalf("sub = %d, want 1", claims.Sub)
+				}
+				return
+			}
+
+			var cerr *cerrors.Error
+			if !errors.As(err, &cerr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if cerr.Code != tt.code || cerr.UserMessage != tt.wantErr {
+				t.Fatalf("error = %d %q, want %d %q", cerr.Code, cerr.UserMessage, tt.code, tt.wantErr)
+			}
+		})
+	}
+}
+
+func testClaims(overrides map[string]any) map[string]any {
+	now := time.Now().Unix()
+	claims := map[string]any{
+		"jti": "token-1",
+		"iss": testIssuer,
+		"aud": testAudience,
+		"sub": 1,
+		"iat": now,
+		"exp": now + 300,
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+
+func testToken(t *testing.T, header jwtHeader, claims map[string]any, signer func(string) []byte) []byte {
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testRawToken(t, header, claimsJSON, signer)
+}
+
+func testRawToken(t *testing.T, header jwtHeader, claimsJSON []byte, signer func(string) []byte) []byte {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signData := encodeTestSegment(headerJSON) + "." + encodeTestSegment(claimsJSON)
+	return []byte(signData + "." + encodeTestSegment(signer(signData)))
+}
+
+func tamperPayload(t *testing.T, token []byte) []byte {
+	segments := strings.Split(string(token), ".")
+	claimsJSON, err := json.Marshal(testClaims(map[string]any{"sub": 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(segments[0] + "." + encodeTestSegment(claimsJSON) + "." + segments[2])
+}
+
+func encodeTestSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func writeTestKeys(t *testing.T, privateKey *rsa.PrivateKey) string {
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "public.pem")
+	if err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeTestJWKS(t *testing.T, kid string, publicKey *rsa.PublicKey) string {
+	document, err := json.Marshal(jwksDocument{Keys: []jwk{{
+		Kty: "RSA",
+		Kid: kid,
+		Alg: algRS256,
+		Use: "sig",
+		N:   encodeTestSegment(publicKey.N.Bytes()),
+		E:   encodeTestSegment(big.NewInt(int64(publicKey.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err = os.WriteFile(path, document, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
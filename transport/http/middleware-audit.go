@@ -0,0 +1,36 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"x-bank-ms-bank/core/web"
+)
+
+const (
+	requestIdHeader    = "X-Request-Id"
+	maxRequestIdLength = 128
+)
+
+func (t *Transport) auditMiddleware(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(requestIdHeader)
+		if requestId == "" || len(requestId) > maxRequestIdLength {
+			requestId = newRequestId()
+		}
+		w.Header().Set(requestIdHeader, requestId)
+
+		ctx := web.WithAuditRequest(r.Context(), web.AuditRequestData{
+			RequestId: requestId,
+			Ip:        clientIp(r),
+			UserAgent: r.UserAgent(),
+		})
+		h(w, r.WithContext(ctx))
+	}
+}
+
+func newRequestId() string {
+	var buf [16]byte
+	_, _ = rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
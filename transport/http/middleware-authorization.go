@@ -2,10 +2,12 @@ package http
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"x-bank-ms-bank/auth"
+	"x-bank-ms-bank/core/web"
 )
 
 func (t *Transport) authMiddleware(allow2Fa bool) middleware {
@@ -32,14 +34,51 @@ func (t *Transport) authMiddleware(allow2Fa bool) middleware {
 				t.errorHandler.setUnauthorizedError(w, errors.New("требуется 2FA"))
 				return
 			}
+			if err = t.service.SyncUserIdentification(r.Context(), claims.Sub, claims.HasPersonalData, claims.Name); err != nil {
+				t.errorHandler.setError(w, err)
+				return
+			}
 
 			ctx := context.WithValue(r.Context(), t.claimsCtxKey, &claims)
+			ctx = web.WithAuditActor(ctx, web.AuditActorUser, strconv.FormatInt(claims.Sub, 10))
 			handlerFunc(w, r.WithContext(ctx))
 		}
 	}
 }
 
-func (t *Transport) basicAuthMiddleware() middleware {
+func (t *Transport) staffMiddleware(handlerFunc http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+		if !ok {
+			t.errorHandler.setUnauthorizedError(w, errors.New("отсутствуют claims в контексте"))
+			return
+		}
+		if !claims.IsStaff {
+			t.errorHandler.setForbiddenError(w, errors.New("требуются права сотрудника"))
+			return
+		}
+		handlerFunc(w, r)
+	}
+}
+
+func (t *Transport) scopeMiddleware(scopes ...string) middleware {
+	return func(handlerFunc http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+			if !ok {
+				t.errorHandler.setUnauthorizedError(w, errors.New("отсутствуют claims в контексте"))
+				return
+			}
+			if !claims.HasScopes(scopes...) {
+				t.errorHandler.setForbiddenError(w, errors.New("токену не выданы права "+strings.Join(scopes, " ")))
+				return
+			}
+			handlerFunc(w, r)
+		}
+	}
+}
+
+func (t *Transport) atmAuthMiddleware() middleware {
 	return func(handlerFunc http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			header := r.Header.Get("Authorization")
@@ -48,25 +87,18 @@ func (t *Transport) basicAuthMiddleware() middleware {
 				return
 			}
 			parts := strings.Split(header, " ")
-			if len(parts) != 2 || parts[0] != "Basic" {
+			if len(parts) != 2 || parts[0] != "Bearer" {
 				t.errorHandler.setUnauthorizedError(w, errors.New("неверный формат заголовка Authorization"))
 				return
 			}
-			encodedAuth := parts[1]
-			auth, err := base64.StdEncoding.DecodeString(encodedAuth)
+			claims, err := t.atmAuthorizer.VerifyATMAuthorization(r.Context(), []byte(parts[1]))
 			if err != nil {
 				t.errorHandler.setUnauthorizedError(w, err)
 				return
 			}
 
-			authArr := strings.Split(string(auth), ":")
-			if len(authArr) < 2 {
-				t.errorHandler.setUnauthorizedError(w, errors.New("неверный формат заголовка Authorization"))
-				return
-			}
-			atmAuthDate := ATMAuthData{Login: authArr[0], Password: authArr[1]}
-
-			ctx := context.WithValue(r.Context(), t.basicCtxKey, atmAuthDate)
+			ctx := context.WithValue(r.Context(), t.atmClaimsCtxKey, &claims)
+			ctx = web.WithAuditActor(ctx, web.AuditActorAtm, strconv.FormatInt(claims.AtmId, 10))
 			handlerFunc(w, r.WithContext(ctx))
 		}
 	}
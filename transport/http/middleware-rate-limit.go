@@ -0,0 +1,108 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+	"x-bank-ms-bank/auth"
+)
+
+const maxRateLimitBodySize = 4096
+
+type (
+	RateLimiter interface {
+		TakeRateLimitToken(ctx context.Context, key string, ratePerSecond float64, burst int) (bool, time.Duration, error)
+	}
+
+	RateLimit struct {
+		RequestsPerMinute int
+		Burst             int
+	}
+
+	RateLimits struct {
+		Ip        RateLimit
+		User      RateLimit
+		Transfers RateLimit
+		Atm       RateLimit
+		AtmLogin  RateLimit
+	}
+)
+
+func (t *Transport) rateLimitMiddleware(group string, limit RateLimit, key func(r *http.Request) string) middleware {
+	if t.rateLimiter == nil || limit.RequestsPerMinute <= 0 {
+		return func(handlerFunc http.HandlerFunc) http.HandlerFunc {
+			return handlerFunc
+		}
+	}
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = limit.RequestsPerMinute
+	}
+	ratePerSecond := float64(limit.RequestsPerMinute) / 60
+
+	return func(handlerFunc http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := key(r)
+			if id == "" {
+				handlerFunc(w, r)
+				return
+			}
+
+			allowed, retryAfter, err := t.rateLimiter.TakeRateLimitToken(r.Context(), group+":"+id, ratePerSecond, burst)
+			if err != nil {
+				log.Println(err)
+				handlerFunc(w, r)
+				return
+			}
+			if !allowed {
+				t.errorHandler.setTooManyRequestsError(w, retryAfter)
+				return
+			}
+			handlerFunc(w, r)
+		}
+	}
+}
+
+func (t *Transport) userRateLimitKey(r *http.Request) string {
+	claims, ok := r.Context().Value(t.claimsCtxKey).(*auth.Claims)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatInt(claims.Sub, 10)
+}
+
+func (t *Transport) atmRateLimitKey(r *http.Request) string {
+	claims, ok := r.Context().Value(t.atmClaimsCtxKey).(*auth.ATMClaims)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatInt(claims.AtmId, 10)
+}
+
+func atmLoginRateLimitKey(r *http.Request) string {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRateLimitBodySize))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil {
+		return ""
+	}
+
+	var atmAuthData ATMAuthData
+	if err = json.Unmarshal(body, &atmAuthData); err != nil {
+		return ""
+	}
+	return atmAuthData.Login
+}
+
+func clientIp(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
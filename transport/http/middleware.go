@@ -16,3 +16,7 @@ func (mg middlewareGroup) Apply(h http.HandlerFunc) http.HandlerFunc {
 
 	return h
 }
+
+func (mg middlewareGroup) With(m ...middleware) middlewareGroup {
+	return append(mg[:len(mg):len(mg)], m...)
+}
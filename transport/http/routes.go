@@ -1,38 +1,126 @@
 package http
 
-import "net/http"
+import (
+	"net/http"
+	"x-bank-ms-bank/auth"
+)
 
 func (t *Transport) routes() http.Handler {
 	corsHandler := t.corsHandler("*", "*", "*", "")
 	corsMiddleware := t.corsMiddleware(corsHandler)
+	ipRateLimitMiddleware := t.rateLimitMiddleware("ip", t.rateLimits.Ip, clientIp)
+	userRateLimitMiddleware := t.rateLimitMiddleware("user", t.rateLimits.User, t.userRateLimitKey)
 
 	defaultMiddlewareGroup := middlewareGroup{
 		t.panicMiddleware,
 		corsMiddleware,
+		t.auditMiddleware,
+		ipRateLimitMiddleware,
 	}
 
 	userMiddlewareGroup := middlewareGroup{
 		t.panicMiddleware,
 		corsMiddleware,
+		t.auditMiddleware,
+		ipRateLimitMiddleware,
 		t.authMiddleware(false),
+		userRateLimitMiddleware,
 	}
 
+	staffMiddlewareGroup := middlewareGroup{
+		t.panicMiddleware,
+		corsMiddleware,
+		t.auditMiddleware,
+		ipRateLimitMiddleware,
+		t.authMiddleware(false),
+		userRateLimitMiddleware,
+		t.staffMiddleware,
+	}
+
+	accountsReadGroup := userMiddlewareGroup.With(t.scopeMiddleware(auth.ScopeAccountsRead))
+	accountsWriteGroup := userMiddlewareGroup.With(t.scopeMiddleware(auth.ScopeAccountsWrite))
+	transactionsWriteGroup := userMiddlewareGroup.With(
+		t.scopeMiddleware(auth.ScopeTransactionsWrite),
+		t.rateLimitMiddleware("transfers", t.rateLimits.Transfers, t.userRateLimitKey),
+	)
+	atmOperateGroup := staffMiddlewareGroup.With(t.scopeMiddleware(auth.ScopeAtmOperate))
+	transactionsReviewGroup := staffMiddlewareGroup.With(t.scopeMiddleware(auth.ScopeTransactionsReview))
+	auditReadGroup := staffMiddlewareGroup.With(t.scopeMiddleware(auth.ScopeAuditRead))
+
 	ATMMiddlewareGroup := middlewareGroup{
 		t.panicMiddleware,
 		corsMiddleware,
-		t.basicAuthMiddleware(),
+		t.auditMiddleware,
+		ipRateLimitMiddleware,
+		t.atmAuthMiddleware(),
+		t.rateLimitMiddleware("atm", t.rateLimits.Atm, t.atmRateLimitKey),
 	}
 
+	atmLoginGroup := defaultMiddlewareGroup.With(t.rateLimitMiddleware("atmLogin", t.rateLimits.AtmLogin, atmLoginRateLimitKey))
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/", defaultMiddlewareGroup.Apply(t.handlerNotFound))
-	mux.HandleFunc("GET /v1/me/accounts", userMiddlewareGroup.Apply(t.handlerUserAccounts))
+	mux.HandleFunc("GET /v1/me/accounts", accountsReadGroup.Apply(t.handlerUserAccounts))
+	mux.HandleFunc("GET /v1/me/events", accountsReadGroup.Apply(t.handlerUserEvents))
+	mux.HandleFunc("POST /v1/me/logout", userMiddlewareGroup.Apply(t.handlerLogout))
+
+	mux.HandleFunc("POST /v1/accounts", accountsWriteGroup.Apply(t.handlerOpenAccount))
+	mux.HandleFunc("POST /v1/accounts/{accountId}/block", accountsWriteGroup.Apply(t.handlerBlockAccount))
+	mux.HandleFunc("GET /v1/accounts/{accountId}/history", accountsReadGroup.Apply(t.handlerAccountHistory))
+
+	mux.HandleFunc("GET /v1/me/cards", accountsReadGroup.Apply(t.handlerUserCards))
+	mux.HandleFunc("POST /v1/accounts/{accountId}/cards", accountsWriteGroup.Apply(t.handlerIssueCard))
+	mux.HandleFunc("POST /v1/me/cards/{cardId}/block", accountsWriteGroup.Apply(t.handlerBlockCard))
+	mux.HandleFunc("POST /v1/me/cards/{cardId}/unblock", accountsWriteGroup.Apply(t.handlerUnblockCard))
+	mux.HandleFunc("PUT /v1/me/cards/{cardId}/limits", accountsWriteGroup.Apply(t.handlerSetCardLimits))
+	mux.HandleFunc("POST /v1/card-payments", transactionsWriteGroup.Apply(t.handlerCardPayment))
+	mux.HandleFunc("POST /v1/card-holds", transactionsWriteGroup.Apply(t.handlerCreateCardHold))
+	mux.HandleFunc("POST /v1/card-holds/{holdId}/capture", transactionsWriteGroup.Apply(t.handlerCaptureCardHold))
+	mux.HandleFunc("POST /v1/card-holds/{holdId}/release", transactionsWriteGroup.Apply(t.handlerReleaseCardHold))
+
+	mux.HandleFunc("GET /v1/me/aliases", accountsReadGroup.Apply(t.handlerUserPaymentAliases))
+	mux.HandleFunc("POST /v1/aliases", accountsWriteGroup.Apply(t.handlerBindPaymentAlias))
+	mux.HandleFunc("POST /v1/aliases/verifications/{verificationId}/confirm", accountsWriteGroup.Apply(t.handlerConfirmPaymentAlias))
+	mux.HandleFunc("POST /v1/aliases/resolve", accountsReadGroup.Apply(t.handlerResolvePaymentAlias))
+	mux.HandleFunc("DELETE /v1/aliases/{aliasId}", accountsWriteGroup.Apply(t.handlerDeletePaymentAlias))
+
+	mux.HandleFunc("GET /v1/me/beneficiaries", accountsReadGroup.Apply(t.handlerUserBeneficiaries))
+	mux.HandleFunc("POST /v1/beneficiaries", accountsWriteGroup.Apply(t.handlerAddBeneficiary))
+	mux.HandleFunc("PUT /v1/beneficiaries/{beneficiaryId}", accountsWriteGroup.Apply(t.handlerRenameBeneficiary))
+	mux.HandleFunc("DELETE /v1/beneficiaries/{beneficiaryId}", accountsWriteGroup.Apply(t.handlerDeleteBeneficiary))
+
+	mux.HandleFunc("POST /v1/transactions", transactionsWriteGroup.Apply(t.handlerAccountTransaction))
+	mux.HandleFunc("POST /v1/step-up/challenges", transactionsWriteGroup.Apply(t.handlerCreateStepUpChallenge))
+	mux.HandleFunc("POST /v1/step-up/challenges/{challengeId}/confirm", transactionsWriteGroup.Apply(t.handlerConfirmStepUpChallenge))
+	mux.HandleFunc("POST /v1/transactions/batch", transactionsWriteGroup.Apply(t.handlerBatchTransaction))
+	mux.HandleFunc("POST /v1/transactions/batch/step-up", transactionsWriteGroup.Apply(t.handlerCreateBatchStepUpChallenge))
+	mux.HandleFunc("GET /v1/transactions/batch/{batchId}", accountsReadGroup.Apply(t.handlerTransactionBatch))
+
+	mux.HandleFunc("POST /v1/accounts/{accountId}/payment-files", transactionsWriteGroup.Apply(t.handlerImportPaymentFile))
+	mux.HandleFunc("GET /v1/payment-files/{fileId}", accountsReadGroup.Apply(t.handlerPaymentFile))
+	mux.HandleFunc("POST /v1/payment-files/{fileId}/step-up", transactionsWriteGroup.Apply(t.handlerCreatePaymentFileStepUpChallenge))
+	mux.HandleFunc("POST /v1/payment-files/{fileId}/confirm", transactionsWriteGroup.Apply(t.handlerConfirmPaymentFile))
+
+	mux.HandleFunc("GET /v1/me/payment-requests", accountsReadGroup.Apply(t.handlerUserPaymentRequests))
+	mux.HandleFunc("POST /v1/payment-requests", transactionsWriteGroup.Apply(t.handlerCreatePaymentRequest))
+	mux.HandleFunc("POST /v1/payment-requests/{requestId}/accept", transactionsWriteGroup.Apply(t.handlerAcceptPaymentRequest))
+	mux.HandleFunc("POST /v1/payment-requests/{requestId}/decline", transactionsWriteGroup.Apply(t.handlerDeclinePaymentRequest))
+
+	mux.HandleFunc("GET /v1/admin/atms", atmOperateGroup.Apply(t.handlerAdminAtms))
+	mux.HandleFunc("POST /v1/admin/atms", atmOperateGroup.Apply(t.handlerAdminRegisterAtm))
+	mux.HandleFunc("POST /v1/admin/atms/{atmId}/password", atmOperateGroup.Apply(t.handlerAdminRotateAtmPassword))
+	mux.HandleFunc("POST /v1/admin/atms/{atmId}/disable", atmOperateGroup.Apply(t.handlerAdminDisableAtm))
+	mux.HandleFunc("POST /v1/admin/atms/{atmId}/enable", atmOperateGroup.Apply(t.handlerAdminEnableAtm))
+	mux.HandleFunc("GET /v1/admin/atms/{atmId}/cash-report", atmOperateGroup.Apply(t.handlerAdminAtmCashReport))
+
+	mux.HandleFunc("GET /v1/admin/transaction-reviews", transactionsReviewGroup.Apply(t.handlerAdminTransactionReviews))
+	mux.HandleFunc("POST /v1/admin/transaction-reviews/{transactionId}/approve", transactionsReviewGroup.Apply(t.handlerAdminApproveTransactionReview))
+	mux.HandleFunc("POST /v1/admin/transaction-reviews/{transactionId}/reject", transactionsReviewGroup.Apply(t.handlerAdminRejectTransactionReview))
 
-	mux.HandleFunc("POST /v1/accounts", userMiddlewareGroup.Apply(t.handlerOpenAccount))
-	mux.HandleFunc("POST /v1/accounts/{accountId}/block", userMiddlewareGroup.Apply(t.handlerBlockAccount))
-	mux.HandleFunc("GET /v1/accounts/{accountId}/history", userMiddlewareGroup.Apply(t.handlerAccountHistory))
+	mux.HandleFunc("GET /v1/admin/audit-log", auditReadGroup.Apply(t.handlerAdminAuditLog))
 
-	mux.HandleFunc("POST /v1/transactions", userMiddlewareGroup.Apply(t.handlerAccountTransaction))
+	mux.HandleFunc("POST /v1/atm/login", atmLoginGroup.Apply(t.handlerATMLogin))
 	mux.HandleFunc("POST /v1/atm/supplement", ATMMiddlewareGroup.Apply(t.handlerATMSupplement))
 	mux.HandleFunc("POST /v1/atm/withdrawal", ATMMiddlewareGroup.Apply(t.handlerATMWithdrawal))
 	mux.HandleFunc("POST /v1/atm/user/supplement", ATMMiddlewareGroup.Apply(t.handlerATMUserSupplement))
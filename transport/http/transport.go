@@ -11,34 +11,90 @@ import (
 
 type (
 	Transport struct {
-		service      web.Service
-		authorizer   auth.Authorizer
-		errorHandler errorHandler
+		service       web.Service
+		authorizer    auth.Authorizer
+		atmAuthorizer auth.ATMAuthorizer
+		errorHandler  errorHandler
+		rateLimiter   RateLimiter
+		rateLimits    RateLimits
 
-		srv *http.Server
+		srv        *http.Server
+		shutdownCh chan struct{}
 
-		claimsCtxKey string
-		basicCtxKey  string
+		claimsCtxKey    string
+		atmClaimsCtxKey string
 	}
 )
 
-func NewTransport(service web.Service, authorizer auth.Authorizer) Transport {
+func NewTransport(service web.Service, authorizer auth.Authorizer, atmAuthorizer auth.ATMAuthorizer, rateLimiter RateLimiter, rateLimits RateLimits) Transport {
 	return Transport{
-		service:    service,
-		authorizer: authorizer,
+		service:       service,
+		authorizer:    authorizer,
+		atmAuthorizer: atmAuthorizer,
+		rateLimiter:   rateLimiter,
+		rateLimits:    rateLimits,
+		shutdownCh:    make(chan struct{}),
 		errorHandler: errorHandler{
 			defaultStatusCode: http.StatusBadRequest,
 			statusCodes: map[cerrors.Code]int{
-				ercodes.BcryptHashing: http.StatusInternalServerError,
+				ercodes.BcryptHashing:                      http.StatusInternalServerError,
+				ercodes.AliasNotFound:                      http.StatusNotFound,
+				ercodes.AliasAlreadyExists:                 http.StatusConflict,
+				ercodes.AliasVerificationNotFound:          http.StatusNotFound,
+				ercodes.AliasVerificationNotActive:         http.StatusConflict,
+				ercodes.AccountNotFound:                    http.StatusNotFound,
+				ercodes.InvalidAccountNumber:               http.StatusUnprocessableEntity,
+				ercodes.PaymentRequestNotFound:             http.StatusNotFound,
+				ercodes.PaymentRequestNotPending:           http.StatusConflict,
+				ercodes.TransactionBatchNotFound:           http.StatusNotFound,
+				ercodes.PaymentFileNotFound:                http.StatusNotFound,
+				ercodes.DuplicatePaymentFile:               http.StatusConflict,
+				ercodes.InvalidPaymentFile:                 http.StatusUnprocessableEntity,
+				ercodes.PaymentFileNotAwaitingConfirmation: http.StatusConflict,
+				ercodes.InvalidBanknotes:                   http.StatusUnprocessableEntity,
+				ercodes.NotEnoughAtmCash:                   http.StatusConflict,
+				ercodes.AtmCannotDispense:                  http.StatusUnprocessableEntity,
+				ercodes.WrongPassword:                      http.StatusUnauthorized,
+				ercodes.AtmNotFound:                        http.StatusNotFound,
+				ercodes.AtmLocked:                          http.StatusForbidden,
+				ercodes.CardNotFound:                       http.StatusNotFound,
+				ercodes.CardBlocked:                        http.StatusForbidden,
+				ercodes.WrongPin:                           http.StatusUnauthorized,
+				ercodes.AtmDisabled:                        http.StatusForbidden,
+				ercodes.AtmLoginExists:                     http.StatusConflict,
+				ercodes.CardExpired:                        http.StatusForbidden,
+				ercodes.WrongCardData:                      http.StatusUnauthorized,
+				ercodes.CardLimitExceeded:                  http.StatusUnprocessableEntity,
+				ercodes.CardHoldNotFound:                   http.StatusNotFound,
+				ercodes.CardHoldNotActive:                  http.StatusConflict,
+				ercodes.InvalidCardHoldAmount:              http.StatusUnprocessableEntity,
+				ercodes.TokenWithoutId:                     http.StatusUnprocessableEntity,
+				ercodes.StepUpRequired:                     http.StatusPreconditionRequired,
+				ercodes.StepUpChallengeNotFound:            http.StatusNotFound,
+				ercodes.StepUpChallengeNotActive:           http.StatusConflict,
+				ercodes.WrongStepUpCode:                    http.StatusUnprocessableEntity,
+				ercodes.InvalidConfirmationToken:           http.StatusForbidden,
+				ercodes.StepUpNotification:                 http.StatusBadGateway,
+				ercodes.IdentificationRequired:             http.StatusForbidden,
+				ercodes.ReceiverBalanceLimit:               http.StatusUnprocessableEntity,
+				ercodes.BeneficiaryNotFound:                http.StatusNotFound,
+				ercodes.BeneficiaryAlreadyExists:           http.StatusConflict,
+				ercodes.BeneficiaryCoolingOff:              http.StatusUnprocessableEntity,
+				ercodes.TransferDenied:                     http.StatusForbidden,
+				ercodes.TransactionReviewNotFound:          http.StatusNotFound,
+				ercodes.TransactionReviewNotPending:        http.StatusConflict,
+				ercodes.ScreeningHit:                       http.StatusForbidden,
+				ercodes.SameAccount:                        http.StatusUnprocessableEntity,
 			},
 		},
-		claimsCtxKey: "CLAIMS",
-		basicCtxKey:  "BASIC",
+		claimsCtxKey:    "CLAIMS",
+		atmClaimsCtxKey: "ATM_CLAIMS",
 	}
 }
 
 func (t *Transport) Start(addr string) chan error {
 	t.srv = &http.Server{Addr: addr, Handler: t.routes()}
+	t.srv.RegisterOnShutdown(func() { close(t.shutdownCh) })
 	ch := make(chan error)
 
 	go func() {